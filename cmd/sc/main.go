@@ -16,9 +16,16 @@ import (
 	"github.com/roberthamel/skill-compiler/internal/generate"
 	"github.com/roberthamel/skill-compiler/internal/instructions"
 	"github.com/roberthamel/skill-compiler/internal/ir"
+	"github.com/roberthamel/skill-compiler/internal/plugins/asyncapi"
 	cliplugin "github.com/roberthamel/skill-compiler/internal/plugins/cli"
 	"github.com/roberthamel/skill-compiler/internal/plugins/codebase"
+	"github.com/roberthamel/skill-compiler/internal/plugins/dts"
+	"github.com/roberthamel/skill-compiler/internal/plugins/goapi"
+	"github.com/roberthamel/skill-compiler/internal/plugins/graphql"
+	"github.com/roberthamel/skill-compiler/internal/plugins/jsonschema"
 	"github.com/roberthamel/skill-compiler/internal/plugins/openapi"
+	"github.com/roberthamel/skill-compiler/internal/plugins/postman"
+	"github.com/roberthamel/skill-compiler/internal/plugins/protobuf"
 	"github.com/roberthamel/skill-compiler/internal/provider"
 	"github.com/spf13/cobra"
 )
@@ -81,7 +88,7 @@ func newInitCmd() *cobra.Command {
 		RunE:  runInit,
 	}
 	cmd.Flags().String("spec", "", "Path to spec file or CLI binary name")
-	cmd.Flags().String("type", "", "Spec type: openapi, cli, codebase")
+	cmd.Flags().String("type", "", "Spec type: openapi, asyncapi, postman, har, jsonschema, graphql, protobuf, goapi, dts, cli, codebase")
 	cmd.Flags().String("name", "", "Project/tool name")
 	cmd.Flags().Bool("force", false, "Overwrite existing instructions file")
 	return cmd
@@ -156,7 +163,16 @@ func newConfigResetCmd() *cobra.Command {
 
 func newPluginRegistry() *ir.Registry {
 	reg := ir.NewRegistry()
+	// asyncapi, postman and jsonschema sniff YAML/JSON files before openapi
+	// claims them
+	reg.Register(asyncapi.New())
+	reg.Register(postman.New())
+	reg.Register(jsonschema.New())
 	reg.Register(openapi.New())
+	reg.Register(graphql.New())
+	reg.Register(protobuf.New())
+	reg.Register(goapi.New())
+	reg.Register(dts.New())
 	reg.Register(cliplugin.New())
 	reg.Register(codebase.New())
 	return reg
@@ -402,7 +418,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 		if specFlag == "" {
 			specFlag = "./openapi.yaml"
 		}
-		sources = []instructions.SpecSource{{Path: specFlag}}
+		sources = []instructions.SpecSource{{Path: specFlag, Type: typeFlag}}
 	}
 
 	// Process specs
@@ -432,6 +448,8 @@ func runInit(cmd *cobra.Command, args []string) error {
 		specConfig = fmt.Sprintf("\n  type: cli\n  binary: %s", specFlag)
 	case "codebase":
 		specConfig = "\n  type: codebase\n  path: ."
+	case "asyncapi", "postman", "har", "jsonschema", "graphql", "protobuf", "goapi", "dts":
+		specConfig = fmt.Sprintf("\n  type: %s\n  path: %s", typeFlag, specFlag)
 	}
 
 	userMsg := fmt.Sprintf("Project name: %s\nSpec type: %s\nSpec config: %s\n\nSpec (IR):\n```json\n%s\n```",
@@ -16,9 +16,16 @@ import (
 	"github.com/roberthamel/skill-compiler/internal/generate"
 	"github.com/roberthamel/skill-compiler/internal/instructions"
 	"github.com/roberthamel/skill-compiler/internal/ir"
+	"github.com/roberthamel/skill-compiler/internal/plugins/asyncapi"
 	cliplugin "github.com/roberthamel/skill-compiler/internal/plugins/cli"
 	"github.com/roberthamel/skill-compiler/internal/plugins/codebase"
+	"github.com/roberthamel/skill-compiler/internal/plugins/dts"
+	"github.com/roberthamel/skill-compiler/internal/plugins/goapi"
+	"github.com/roberthamel/skill-compiler/internal/plugins/graphql"
+	"github.com/roberthamel/skill-compiler/internal/plugins/jsonschema"
 	"github.com/roberthamel/skill-compiler/internal/plugins/openapi"
+	"github.com/roberthamel/skill-compiler/internal/plugins/postman"
+	"github.com/roberthamel/skill-compiler/internal/plugins/protobuf"
 )
 
 func main() {
@@ -47,7 +54,16 @@ func main() {
 	}
 
 	reg := ir.NewRegistry()
+	// asyncapi, postman and jsonschema sniff YAML/JSON files before openapi
+	// claims them
+	reg.Register(asyncapi.New())
+	reg.Register(postman.New())
+	reg.Register(jsonschema.New())
 	reg.Register(openapi.New())
+	reg.Register(graphql.New())
+	reg.Register(protobuf.New())
+	reg.Register(goapi.New())
+	reg.Register(dts.New())
 	reg.Register(cliplugin.New())
 	reg.Register(codebase.New())
 
@@ -0,0 +1,72 @@
+// Package fetch reads spec sources given as a file path, URL or command.
+package fetch
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/roberthamel/skill-compiler/internal/instructions"
+)
+
+// Source returns the contents of a spec source: the file at its path, the
+// body of its URL, or the output of its command. plugin names the caller in
+// the error for a source with none of them.
+func Source(plugin string, source instructions.SpecSource) ([]byte, error) {
+	switch {
+	case source.Path != "":
+		return os.ReadFile(source.Path)
+	case source.URL != "":
+		return URL(source.URL)
+	case source.Command != "":
+		return Command(source.Command)
+	}
+	return nil, fmt.Errorf("%s plugin: no path, url, or command in spec source", plugin)
+}
+
+// URL downloads u, failing on any status other than 200.
+func URL(u string) ([]byte, error) {
+	resp, err := http.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("fetching URL %s: %w", u, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching URL %s: HTTP %d", u, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Command runs a whitespace-separated command line and returns its stdout.
+func Command(command string) ([]byte, error) {
+	parts := strings.Fields(command)
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	out, err := exec.Command(parts[0], parts[1:]...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("running command %q: %w", command, err)
+	}
+	return out, nil
+}
+
+// sniffSize is how much of a file HeadMatches reads.
+const sniffSize = 4096
+
+// HeadMatches reports whether the start of the file at path matches re.
+// Detect uses it to recognize a format from a top-level key without
+// reading the whole file.
+func HeadMatches(path string, re *regexp.Regexp) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = f.Close() }()
+	head := make([]byte, sniffSize)
+	n, _ := io.ReadFull(f, head)
+	return re.Match(head[:n])
+}
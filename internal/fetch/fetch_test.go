@@ -0,0 +1,76 @@
+package fetch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/roberthamel/skill-compiler/internal/instructions"
+)
+
+func TestSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/spec.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("from url"))
+	}))
+	defer srv.Close()
+
+	file := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(file, []byte("from file"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		source  instructions.SpecSource
+		want    string
+		wantErr string
+	}{
+		{"path", instructions.SpecSource{Path: file}, "from file", ""},
+		{"url", instructions.SpecSource{URL: srv.URL + "/spec.yaml"}, "from url", ""},
+		{"command", instructions.SpecSource{Command: "echo from command"}, "from command\n", ""},
+		{"http error", instructions.SpecSource{URL: srv.URL + "/missing"}, "", "HTTP 404"},
+		{"empty", instructions.SpecSource{}, "", "test plugin: no path, url, or command"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Source("test", tt.source)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeadMatches(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "spec.yaml")
+	head := "asyncapi: 3.0.0\n"
+	if err := os.WriteFile(file, []byte(head+strings.Repeat("# padding\n", 1000)+"openapi: 3.1.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if !HeadMatches(file, regexp.MustCompile(`(?m)^asyncapi:`)) {
+		t.Error("a key at the start of the file should match")
+	}
+	if HeadMatches(file, regexp.MustCompile(`(?m)^openapi:`)) {
+		t.Error("a key past the sniffed head should not match")
+	}
+	if HeadMatches(filepath.Join(t.TempDir(), "missing.yaml"), regexp.MustCompile(`.`)) {
+		t.Error("a missing file should not match")
+	}
+}
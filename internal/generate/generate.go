@@ -335,11 +335,21 @@ func (p *Pipeline) userMessage(id ArtifactID) string {
 		}
 	}
 
+	if typesOnly(p.IR) {
+		parts = append(parts, "## Note\nThe spec defines types only, with no operations: document the data format it describes rather than an API.")
+	}
+
 	parts = append(parts, fmt.Sprintf("## Spec (Intermediate Representation)\n```json\n%s\n```", string(irJSON)))
 
 	return strings.Join(parts, "\n\n")
 }
 
+// typesOnly reports whether the IR describes a data format (e.g. from a JSON
+// Schema) rather than an interface with operations.
+func typesOnly(r *ir.IntermediateRepr) bool {
+	return r != nil && len(r.Operations) == 0 && len(r.Types) > 0
+}
+
 func (p *Pipeline) artifactPath(id ArtifactID) string {
 	name := p.Inst.Frontmatter.Name
 	artifactKey := string(id)
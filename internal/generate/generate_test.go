@@ -209,3 +209,15 @@ func TestUserMessage_FirstGeneration(t *testing.T) {
 		t.Error("first-gen changelog should note no previous artifacts")
 	}
 }
+
+func TestUserMessage_TypesOnly(t *testing.T) {
+	p := testPipeline(t)
+	if msg := p.userMessage(ArtifactSkill); strings.Contains(msg, "types only") {
+		t.Error("empty IR should not be described as types-only")
+	}
+
+	p.IR = &ir.IntermediateRepr{Types: []ir.TypeDef{{Name: "DeployManifest"}}}
+	if msg := p.userMessage(ArtifactReference); !strings.Contains(msg, "types only") {
+		t.Error("IR without operations should be described as types-only")
+	}
+}
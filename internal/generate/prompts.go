@@ -11,13 +11,26 @@ Your output must be a complete SKILL.md file with:
    - Any additional metadata fields provided (license, compatibility, metadata, allowed-tools)
 
 2. Markdown body (UNDER 500 lines) structured for progressive disclosure:
-   - ## Configuration — environment variables, authentication setup
+   - ## Configuration — base URL (from the spec's servers, if any, noting operations with their own
+     "baseUrl"), environment variables, authentication setup
+     (how to obtain credentials, e.g. the OAuth2 flow and its token URL)
    - ## Core Concepts — mental model for the tool
-   - ## Key Operations — most important operations with brief usage
+   - ## Key Operations — most important operations with brief usage (grpcurl invocations for gRPC methods)
    - ## Value Formats — important data types and formats
-   - ## Best Practices — guardrails, conventions, common pitfalls
+   - ## Best Practices — guardrails, conventions, common pitfalls; include every operation "hint"
+     and the "x-sc-hint" metadata entry, which are guidance from the spec's authors
    - ## File References — pointers to references/ and scripts/ for details
 
+If the spec has types but no operations (e.g. a JSON Schema for a config file or data
+format), the skill teaches agents to author documents of that format: replace Key
+Operations with ## Document Structure (the root type, required fields, defaults and
+constraints such as patterns and ranges) and cover validation in Best Practices.
+
+If the operations are library functions and methods (FUNC, METHOD and CONSTRUCTOR),
+the skill teaches agents to call the library from code: show how to import it (the
+import path or package metadata) and each key operation's signature, and use code
+snippets instead of shell commands.
+
 The body should be optimized for an AI agent to quickly understand and use the tool.
 Keep it concise but comprehensive. Use relative file references (e.g., references/reference.md).
 Do NOT include raw API specs — that goes in references/.
@@ -28,9 +41,21 @@ const ReferencePrompt = `You are generating a reference.md file — an exhaustiv
 Your output must be a complete markdown document listing EVERY operation with:
 - Full path/command syntax
 - All parameters, flags, arguments with types and descriptions
-- Request/response body shapes (for APIs)
+- Persistent (global) flags once, on the command that declares them, noting that its
+  subcommands accept them too; a flag with inheritedFrom refers to that declaration
+- Request/response body shapes (for APIs), including union variants (oneOf/anyOf),
+  the discriminator value that selects each variant, and fields inherited via extends
 - Error codes and their meanings
-- Authentication requirements
+- Authentication requirements, including the OAuth2 scopes each operation needs
+- Field defaults and constraints (patterns, ranges, lengths)
+- The operation's examples, when it has them, as fenced code blocks
+
+For a spec with types but no operations, document every type and field instead,
+starting from the root type named in the metadata.
+
+For library functions and methods (FUNC, METHOD and CONSTRUCTOR operations), give
+each one's signature and import path, and document returned or thrown errors instead
+of error codes.
 
 Organize by resource/domain area. Use consistent formatting.
 Be thorough — this is the complete reference an agent loads on demand.`
@@ -41,11 +66,13 @@ Your output must show realistic, end-to-end workflows that combine multiple oper
 Each example should:
 - Have a clear title describing the goal
 - Show the complete sequence of operations
-- Include realistic sample data
+- Include realistic sample data, using example payloads from the spec (content examples in the IR) where available
 - Explain what each step does and why
 - Show expected responses/outputs
 
 Focus on the most common workflows agents would perform.
+For a spec with types but no operations, show complete, valid example documents
+for common scenarios instead, and how to check them against the schema.
 Pull from any provided workflow descriptions, common patterns, and domain knowledge.`
 
 const ScriptsPrompt = `You are generating executable shell scripts for a skill's scripts/ directory.
@@ -62,6 +89,16 @@ Types of scripts to generate:
 - discover.sh: List available resources
 - Custom workflow scripts that save multi-step operations
 
+For gRPC services (operations with UNARY or *_STREAMING methods and paths like
+/package.Service/Method), call methods with grpcurl.
+
+For event-driven APIs (PUBLISH and SUBSCRIBE operations on channels), use a
+client for the protocol listed in the "servers" metadata, e.g. mosquitto_pub /
+mosquitto_sub for MQTT or kcat for Kafka.
+
+For a spec with types but no operations, generate a script that validates a document
+against the schema (e.g. with check-jsonschema) and one that writes a starter document.
+
 Output format: Output each script as a code block with the filename as the info string.
 Example:
 ` + "```health-check.sh" + `
@@ -89,7 +126,8 @@ const LlmsAPITxtPrompt = `You are generating an llms-api.txt file — a concise
 
 Your output must include:
 - Quick start (authentication, base URL)
-- Every operation as a ONE-LINE summary (method + path + brief description)
+- Every operation as a ONE-LINE summary (method + path + brief description); for a spec
+  with types but no operations, every type and field instead
 - Common patterns (pagination, filtering, error handling)
 - Error codes table
 
@@ -101,7 +139,8 @@ const LlmsFullTxtPrompt = `You are generating an llms-full.txt file — complete
 Your output must include:
 - Overview and core concepts
 - Authentication and configuration
-- All operations with full details (parameters, request/response shapes, examples)
+- All operations with full details (parameters, request/response shapes and media types,
+  examples — prefer example payloads from the spec over invented data)
 - Worked examples of common workflows
 - Error handling and troubleshooting
 - Best practices and conventions
@@ -3,6 +3,7 @@ package instructions
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -33,16 +34,49 @@ type SpecSource struct {
 	URL string `yaml:"url,omitempty"`
 	// For shell commands
 	Command string `yaml:"command,omitempty"`
-	// Type: openapi, cli, codebase
+	// Type: openapi (or swagger), graphql, protobuf (or grpc), asyncapi,
+	// postman (or har), jsonschema, goapi, dts (or typescript), cli or
+	// codebase; detected from the path when empty
 	Type string `yaml:"type,omitempty"`
 	// CLI-specific
-	Binary   string   `yaml:"binary,omitempty"`
-	HelpFlag string   `yaml:"help-flag,omitempty"`
-	MaxDepth int      `yaml:"max-depth,omitempty"`
-	Exclude  []string `yaml:"exclude,omitempty"`
+	Binary      string   `yaml:"binary,omitempty"`
+	HelpFlag    string   `yaml:"help-flag,omitempty"`
+	HelpFormat  string   `yaml:"help-format,omitempty"` // auto (default), cobra, yargs or sectioned (click, argparse, clap, commander, Go flag)
+	MaxDepth    int      `yaml:"max-depth,omitempty"`
+	Exclude     []string `yaml:"exclude,omitempty"`
+	Man         bool     `yaml:"man,omitempty"`         // read man pages (man -w) instead of running the help flag
+	Completion  string   `yaml:"completion,omitempty"`  // auto, cobra, fish or a .fish script to read commands and flags from
+	Timeout     string   `yaml:"timeout,omitempty"`     // per-command crawl timeout, e.g. 10s (default 5s)
+	Concurrency int      `yaml:"concurrency,omitempty"` // commands crawled at once (default 4)
+	NoCache     bool     `yaml:"no-cache,omitempty"`    // recrawl even if the binary is unchanged
+	Env         []string `yaml:"env,omitempty"`         // variables passed to the crawled binary: NAME, or NAME=value
 	// Codebase-specific
 	MaxFiles int      `yaml:"max-files,omitempty"`
 	Include  []string `yaml:"include,omitempty"`
+	// Operation filters, applied to the parsed IR for any plugin
+	IncludeTags       []string `yaml:"include-tags,omitempty"`
+	ExcludeTags       []string `yaml:"exclude-tags,omitempty"`
+	IncludePaths      []string `yaml:"include-paths,omitempty"` // globs; ** matches across /
+	ExcludePaths      []string `yaml:"exclude-paths,omitempty"`
+	IncludeOperations []string `yaml:"include-operations,omitempty"` // operation IDs
+	ExcludeOperations []string `yaml:"exclude-operations,omitempty"`
+	ExcludeDeprecated bool     `yaml:"exclude-deprecated,omitempty"`
+}
+
+// Excludes reports whether the slash-separated path rel, or any of its path
+// components, matches one of the Exclude globs.
+func (s SpecSource) Excludes(rel string) bool {
+	for _, pattern := range s.Exclude {
+		if matched, _ := filepath.Match(pattern, rel); matched {
+			return true
+		}
+		for _, part := range strings.Split(rel, "/") {
+			if matched, _ := filepath.Match(pattern, part); matched {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // Artifact controls per-artifact settings.
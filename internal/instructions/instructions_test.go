@@ -157,3 +157,23 @@ func TestEnvPrefix(t *testing.T) {
 		}
 	}
 }
+
+func TestSpecSourceExcludes(t *testing.T) {
+	src := SpecSource{Exclude: []string{"gen", "*.pb.go", "api/v1/*"}}
+	tests := []struct {
+		rel  string
+		want bool
+	}{
+		{"gen", true},
+		{"pkg/gen/types.go", true},
+		{"pkg/types.pb.go", true},
+		{"api/v1/users.proto", true},
+		{"api/v2/users.proto", false},
+		{"generator/main.go", false},
+	}
+	for _, tt := range tests {
+		if got := src.Excludes(tt.rel); got != tt.want {
+			t.Errorf("Excludes(%q) = %v, want %v", tt.rel, got, tt.want)
+		}
+	}
+}
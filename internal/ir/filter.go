@@ -0,0 +1,247 @@
+package ir
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/roberthamel/skill-compiler/internal/instructions"
+)
+
+// hasFilters reports whether the source sets any operation filter.
+func hasFilters(src instructions.SpecSource) bool {
+	return len(src.IncludeTags) > 0 || len(src.ExcludeTags) > 0 ||
+		len(src.IncludePaths) > 0 || len(src.ExcludePaths) > 0 ||
+		len(src.IncludeOperations) > 0 || len(src.ExcludeOperations) > 0 ||
+		src.ExcludeDeprecated
+}
+
+// Filter drops operations that don't pass the source's operation filters,
+// then prunes groups, types and auth schemes no remaining operation uses.
+// An operation is kept if it matches any include filter (or none are set)
+// and no exclude filter. Without filters the IR is left untouched.
+func (ir *IntermediateRepr) Filter(src instructions.SpecSource) {
+	if !hasFilters(src) {
+		return
+	}
+
+	referenced := authRefs(ir.Operations)
+	kept := make(map[string]bool)
+	dropped := make(map[string]Operation)
+	var ops []Operation
+	for _, op := range ir.Operations {
+		if keepOperation(op, src) {
+			ops = append(ops, op)
+			kept[op.ID] = true
+		} else {
+			dropped[op.ID] = op
+		}
+	}
+	ir.Operations = ops
+	restoreInherited(ops, dropped)
+
+	var groups []Group
+	for _, g := range ir.Groups {
+		if len(g.Operations) == 0 {
+			groups = append(groups, g)
+			continue
+		}
+		var ids []string
+		for _, id := range g.Operations {
+			if kept[id] {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) > 0 {
+			g.Operations = ids
+			groups = append(groups, g)
+		}
+	}
+	ir.Groups = groups
+
+	ir.pruneTypes()
+	ir.pruneAuth(referenced)
+}
+
+func keepOperation(op Operation, src instructions.SpecSource) bool {
+	if src.ExcludeDeprecated && op.Deprecated {
+		return false
+	}
+	if containsAny(op.Tags, src.ExcludeTags) || matchesGlob(op.Path, src.ExcludePaths) || contains(src.ExcludeOperations, op.ID) {
+		return false
+	}
+	if len(src.IncludeTags) == 0 && len(src.IncludePaths) == 0 && len(src.IncludeOperations) == 0 {
+		return true
+	}
+	return containsAny(op.Tags, src.IncludeTags) || matchesGlob(op.Path, src.IncludePaths) || contains(src.IncludeOperations, op.ID)
+}
+
+// restoreInherited writes out in full the inherited flags of operations
+// whose declaring command was dropped.
+func restoreInherited(ops []Operation, dropped map[string]Operation) {
+	for i := range ops {
+		for j, p := range ops[i].Parameters {
+			from, ok := dropped[p.InheritedFrom]
+			if p.InheritedFrom == "" || !ok {
+				continue
+			}
+			for _, d := range from.Parameters {
+				if d.Name == p.Name && d.In == p.In {
+					d.Persistent = false
+					ops[i].Parameters[j] = d
+					break
+				}
+			}
+		}
+	}
+}
+
+// pruneTypes keeps the types reachable from the remaining operations'
+// parameters and bodies, following field types, extends and union variants.
+func (ir *IntermediateRepr) pruneTypes() {
+	byName := make(map[string]TypeDef, len(ir.Types))
+	for _, td := range ir.Types {
+		byName[td.Name] = td
+	}
+
+	used := make(map[string]bool)
+	var queue []string
+	use := func(typeExpr string) {
+		for _, name := range typeNames(typeExpr) {
+			if _, ok := byName[name]; ok && !used[name] {
+				used[name] = true
+				queue = append(queue, name)
+			}
+		}
+	}
+	useRef := func(ref *TypeRef) {
+		if ref == nil {
+			return
+		}
+		use(ref.TypeName)
+		for _, mt := range ref.Content {
+			use(mt.TypeName)
+		}
+	}
+	for _, op := range ir.Operations {
+		for _, p := range op.Parameters {
+			use(p.Type)
+		}
+		useRef(op.RequestBody)
+		for _, resp := range op.Responses {
+			useRef(resp.Body)
+			for _, h := range resp.Headers {
+				use(h.Type)
+			}
+		}
+	}
+	for len(queue) > 0 {
+		td := byName[queue[0]]
+		queue = queue[1:]
+		for _, f := range td.Fields {
+			use(f.Type)
+		}
+		for _, names := range [][]string{td.Extends, td.OneOf, td.AnyOf} {
+			for _, name := range names {
+				use(name)
+			}
+		}
+		if td.Discriminator != nil {
+			for _, name := range td.Discriminator.Mapping {
+				use(name)
+			}
+		}
+	}
+
+	var types []TypeDef
+	for _, td := range ir.Types {
+		if used[td.Name] {
+			types = append(types, td)
+		}
+	}
+	ir.Types = types
+}
+
+// pruneAuth drops the auth schemes that were referenced before filtering
+// and are no longer referenced by the remaining operations. Schemes no
+// operation referenced to begin with (e.g. declared without security
+// requirements) are kept.
+func (ir *IntermediateRepr) pruneAuth(referenced map[string]bool) {
+	used := authRefs(ir.Operations)
+	var auth []AuthScheme
+	for _, a := range ir.Auth {
+		if used[a.ID] || !referenced[a.ID] {
+			auth = append(auth, a)
+		}
+	}
+	ir.Auth = auth
+}
+
+// authRefs returns the IDs of the auth schemes the operations reference.
+func authRefs(ops []Operation) map[string]bool {
+	refs := make(map[string]bool)
+	for _, op := range ops {
+		for _, id := range op.Auth {
+			refs[id] = true
+		}
+	}
+	return refs
+}
+
+var typeNameRe = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_.]*`)
+
+// typeNames extracts the candidate type names from a type expression such as
+// "[]Pet", "Cat|Dog" or "map[string]Order".
+func typeNames(expr string) []string {
+	return typeNameRe.FindAllString(expr, -1)
+}
+
+// matchesGlob reports whether s matches any of the patterns. * matches
+// within a path segment and ** across segments.
+func matchesGlob(s string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if globRegexp(pattern).MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func globRegexp(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(list, candidates []string) bool {
+	for _, c := range candidates {
+		if contains(list, c) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package ir
+
+import (
+	"testing"
+
+	"github.com/roberthamel/skill-compiler/internal/instructions"
+)
+
+func filterFixture() *IntermediateRepr {
+	return &IntermediateRepr{
+		Operations: []Operation{
+			{ID: "listOrders", Path: "/orders", Tags: []string{"orders"}, Auth: []string{"oauth"},
+				Responses: []Response{{StatusCode: "200", Body: &TypeRef{TypeName: "[]Order"}}}},
+			{ID: "getOrderItem", Path: "/orders/{id}/items/{itemId}", Tags: []string{"orders"},
+				Parameters: []Parameter{{Name: "id", Type: "string"}}},
+			{ID: "listStock", Path: "/inventory", Tags: []string{"inventory"}, Deprecated: true, Auth: []string{"apiKey"},
+				Responses: []Response{{StatusCode: "200", Body: &TypeRef{TypeName: "Stock"}}}},
+			{ID: "listUsers", Path: "/users", Tags: []string{"users"}, Auth: []string{"basic"},
+				Responses: []Response{{StatusCode: "200", Body: &TypeRef{TypeName: "User"}}}},
+		},
+		Types: []TypeDef{
+			{Name: "Order", Fields: []TypeField{{Name: "lines", Type: "[]LineItem"}}},
+			{Name: "LineItem", Extends: []string{"Product"}},
+			{Name: "Product"},
+			{Name: "Stock"},
+			{Name: "User"},
+		},
+		Auth: []AuthScheme{{ID: "apiKey"}, {ID: "basic"}, {ID: "oauth"}},
+		Groups: []Group{
+			{Name: "orders", Operations: []string{"listOrders", "getOrderItem"}},
+			{Name: "inventory", Operations: []string{"listStock"}},
+			{Name: "users", Operations: []string{"listUsers"}},
+		},
+	}
+}
+
+func opIDs(ir *IntermediateRepr) []string {
+	var ids []string
+	for _, op := range ir.Operations {
+		ids = append(ids, op.ID)
+	}
+	return ids
+}
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		name string
+		src  instructions.SpecSource
+		want []string
+	}{
+		{"no filters", instructions.SpecSource{}, []string{"listOrders", "getOrderItem", "listStock", "listUsers"}},
+		{"include tags", instructions.SpecSource{IncludeTags: []string{"orders", "inventory"}}, []string{"listOrders", "getOrderItem", "listStock"}},
+		{"exclude tags", instructions.SpecSource{ExcludeTags: []string{"orders"}}, []string{"listStock", "listUsers"}},
+		{"single-segment glob", instructions.SpecSource{IncludePaths: []string{"/orders/*"}}, nil},
+		{"double-star glob", instructions.SpecSource{IncludePaths: []string{"/orders/**"}}, []string{"getOrderItem"}},
+		{"exclude path", instructions.SpecSource{ExcludePaths: []string{"/orders", "/orders/**"}}, []string{"listStock", "listUsers"}},
+		{"include operations", instructions.SpecSource{IncludeOperations: []string{"listUsers"}}, []string{"listUsers"}},
+		{"include is a union", instructions.SpecSource{IncludeTags: []string{"users"}, IncludeOperations: []string{"listOrders"}}, []string{"listOrders", "listUsers"}},
+		{"exclude wins", instructions.SpecSource{IncludeTags: []string{"orders"}, ExcludeOperations: []string{"listOrders"}}, []string{"getOrderItem"}},
+		{"exclude deprecated", instructions.SpecSource{ExcludeDeprecated: true}, []string{"listOrders", "getOrderItem", "listUsers"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ir := filterFixture()
+			ir.Filter(tt.src)
+			got := opIDs(ir)
+			if len(got) != len(tt.want) {
+				t.Fatalf("operations = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("operations = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFilter_Prunes(t *testing.T) {
+	ir := filterFixture()
+	ir.Filter(instructions.SpecSource{IncludeTags: []string{"orders"}})
+
+	var types []string
+	for _, td := range ir.Types {
+		types = append(types, td.Name)
+	}
+	if len(types) != 3 || types[0] != "Order" || types[1] != "LineItem" || types[2] != "Product" {
+		t.Errorf("types = %v, want Order and the types it reaches", types)
+	}
+	if len(ir.Auth) != 1 || ir.Auth[0].ID != "oauth" {
+		t.Errorf("auth = %+v, want only oauth", ir.Auth)
+	}
+	if len(ir.Groups) != 1 || ir.Groups[0].Name != "orders" {
+		t.Errorf("groups = %+v, want only orders", ir.Groups)
+	}
+}
+
+func TestFilter_KeepsUnreferencedAuth(t *testing.T) {
+	ir := &IntermediateRepr{
+		Operations: []Operation{
+			{ID: "listOrders", Path: "/orders"},
+			{ID: "listUsers", Path: "/users", Deprecated: true},
+		},
+		Auth: []AuthScheme{{ID: "bearer"}},
+	}
+	ir.Filter(instructions.SpecSource{ExcludeDeprecated: true})
+	if len(ir.Auth) != 1 {
+		t.Errorf("auth = %+v, want bearer, which no operation referenced before filtering", ir.Auth)
+	}
+}
+
+func TestFilter_RestoresInheritedFlags(t *testing.T) {
+	ir := &IntermediateRepr{
+		Operations: []Operation{
+			{ID: "tool", Path: "tool", Parameters: []Parameter{{Name: "--config", In: "flag", Type: "string", Description: "Config file", Persistent: true}}},
+			{ID: "tool_get", Path: "tool get", Parameters: []Parameter{{Name: "--config", In: "flag", InheritedFrom: "tool"}}},
+		},
+	}
+	ir.Filter(instructions.SpecSource{ExcludeOperations: []string{"tool"}})
+
+	got := ir.Operations[0].Parameters[0]
+	if got.InheritedFrom != "" || got.Persistent || got.Type != "string" || got.Description != "Config file" {
+		t.Errorf("--config = %+v, want the declaration from the dropped tool", got)
+	}
+}
+
+func TestRegistry_ProcessSources_Filters(t *testing.T) {
+	plugin := &mockPlugin{
+		name:     "mock",
+		detectFn: func(s instructions.SpecSource) bool { return s.Type == "mock" },
+		ir:       filterFixture(),
+	}
+	reg := NewRegistry()
+	reg.Register(plugin)
+
+	result, _, err := reg.ProcessSources([]instructions.SpecSource{{Type: "mock", IncludeOperations: []string{"listUsers"}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := opIDs(result); len(got) != 1 || got[0] != "listUsers" {
+		t.Errorf("operations = %v, want [listUsers]", got)
+	}
+	if len(result.Types) != 1 || result.Types[0].Name != "User" {
+		t.Errorf("types = %+v, want [User]", result.Types)
+	}
+}
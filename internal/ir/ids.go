@@ -0,0 +1,23 @@
+package ir
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OperationID builds an ID for an operation the spec doesn't name, e.g.
+// "get_users_{id}" for GET /users/{id}.
+func OperationID(method, path string) string {
+	return strings.ToLower(method) + "_" + strings.ReplaceAll(strings.Trim(path, "/"), "/", "_")
+}
+
+// UniqueID returns id, suffixed with a counter if it was already used, and
+// marks the result as used.
+func UniqueID(id string, used map[string]bool) string {
+	unique := id
+	for i := 2; used[unique]; i++ {
+		unique = fmt.Sprintf("%s_%d", id, i)
+	}
+	used[unique] = true
+	return unique
+}
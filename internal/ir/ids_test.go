@@ -0,0 +1,20 @@
+package ir
+
+import "testing"
+
+func TestOperationID(t *testing.T) {
+	if got := OperationID("GET", "/users/{id}/"); got != "get_users_{id}" {
+		t.Errorf("OperationID = %q", got)
+	}
+}
+
+func TestUniqueID(t *testing.T) {
+	used := map[string]bool{}
+	var got []string
+	for _, id := range []string{"get_users", "get_users", "get_users"} {
+		got = append(got, UniqueID(id, used))
+	}
+	if got[0] != "get_users" || got[1] != "get_users_2" || got[2] != "get_users_3" {
+		t.Errorf("UniqueID = %v", got)
+	}
+}
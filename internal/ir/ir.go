@@ -8,24 +8,37 @@ type IntermediateRepr struct {
 	Groups     []Group           `json:"groups,omitempty"`
 	Structure  *ProjectStructure `json:"structure,omitempty"`
 	Metadata   map[string]string `json:"metadata,omitempty"`
+
+	// Warnings found while fetching or parsing the source (e.g. unresolved
+	// $refs). They are reported with Validate's and are not part of the IR
+	// given to the model.
+	Warnings []Warning `json:"-"`
 }
 
 // Operation represents an endpoint, command, or RPC.
 type Operation struct {
-	ID          string      `json:"id"`
-	Name        string      `json:"name"`
-	Description string      `json:"description,omitempty"`
-	Method      string      `json:"method,omitempty"` // HTTP method or empty for CLI
-	Path        string      `json:"path,omitempty"`   // HTTP path or command path
-	Parameters  []Parameter `json:"parameters,omitempty"`
-	RequestBody *TypeRef    `json:"requestBody,omitempty"`
-	Responses   []Response  `json:"responses,omitempty"`
-	Tags        []string    `json:"tags,omitempty"`
-	Deprecated  bool        `json:"deprecated,omitempty"`
-	Auth        []string    `json:"auth,omitempty"` // references to AuthScheme IDs
+	ID          string              `json:"id"`
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Method      string              `json:"method,omitempty"`  // HTTP method or empty for CLI
+	Path        string              `json:"path,omitempty"`    // HTTP path or command path
+	BaseURL     string              `json:"baseUrl,omitempty"` // server overriding the API's base URL for this operation
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *TypeRef            `json:"requestBody,omitempty"`
+	Responses   []Response          `json:"responses,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Deprecated  bool                `json:"deprecated,omitempty"`
+	Auth        []string            `json:"auth,omitempty"`       // references to AuthScheme IDs
+	Scopes      map[string][]string `json:"scopes,omitempty"`     // OAuth2/OIDC scopes required, keyed by AuthScheme ID
+	Webhook     bool                `json:"webhook,omitempty"`    // sent by the API to subscribers rather than called by clients
+	Hint        string              `json:"hint,omitempty"`       // guidance for agents supplied by the spec author
+	Extensions  map[string]string   `json:"extensions,omitempty"` // vendor extensions (x-*); non-string values JSON-encoded
 	// CLI-specific
 	Aliases     []string `json:"aliases,omitempty"`
 	RawHelpText string   `json:"rawHelpText,omitempty"`
+	Examples    []string `json:"examples,omitempty"` // example invocations, each optionally led by # comment lines
+	// Library-specific
+	Signature string `json:"signature,omitempty"` // declaration as written in the source language
 }
 
 // Parameter represents a flag, query param, path param, or header.
@@ -37,36 +50,95 @@ type Parameter struct {
 	Type        string `json:"type,omitempty"`
 	Default     string `json:"default,omitempty"`
 	Shorthand   string `json:"shorthand,omitempty"` // CLI short flag
+	// CLI flags subcommands inherit are listed once, on the command that
+	// declares them; a subcommand lists just the name and that command's ID
+	Persistent    bool   `json:"persistent,omitempty"`
+	InheritedFrom string `json:"inheritedFrom,omitempty"`
+
+	Extensions map[string]string `json:"extensions,omitempty"`
 }
 
 // TypeDef represents a schema, message type, or complex value type.
 type TypeDef struct {
-	Name        string      `json:"name"`
-	Description string      `json:"description,omitempty"`
-	Fields      []TypeField `json:"fields,omitempty"`
-	Enum        []string    `json:"enum,omitempty"`
+	Name          string            `json:"name"`
+	Kind          string            `json:"kind,omitempty"` // e.g. object, input, enum, interface, union, scalar; empty for plain schemas
+	Description   string            `json:"description,omitempty"`
+	Fields        []TypeField       `json:"fields,omitempty"`
+	Enum          []string          `json:"enum,omitempty"`
+	Examples      []string          `json:"examples,omitempty"`      // JSON-encoded example values
+	Extends       []string          `json:"extends,omitempty"`       // types whose fields are inherited (allOf)
+	OneOf         []string          `json:"oneOf,omitempty"`         // variants; exactly one applies
+	AnyOf         []string          `json:"anyOf,omitempty"`         // variants; one or more apply
+	Discriminator *Discriminator    `json:"discriminator,omitempty"` // selects the variant of a OneOf/AnyOf union
+	Constraints   map[string]string `json:"constraints,omitempty"`   // validation keywords (pattern, minimum, ...) -> value
+
+	Extensions map[string]string `json:"extensions,omitempty"`
+}
+
+// Discriminator names the property whose value selects a union variant.
+type Discriminator struct {
+	Property string            `json:"property"`
+	Mapping  map[string]string `json:"mapping,omitempty"` // property value -> variant type name
 }
 
 // TypeField is a field within a TypeDef.
 type TypeField struct {
-	Name        string `json:"name"`
-	Type        string `json:"type"`
-	Description string `json:"description,omitempty"`
-	Required    bool   `json:"required,omitempty"`
+	Name          string            `json:"name"`
+	Type          string            `json:"type"`
+	Description   string            `json:"description,omitempty"`
+	Required      bool              `json:"required,omitempty"`
+	Nullable      bool              `json:"nullable,omitempty"`
+	Const         string            `json:"const,omitempty"`         // fixed value, JSON-encoded
+	Enum          []string          `json:"enum,omitempty"`          // allowed values
+	Examples      []string          `json:"examples,omitempty"`      // JSON-encoded example values
+	Default       string            `json:"default,omitempty"`       // value used when the field is omitted, JSON-encoded
+	InheritedFrom string            `json:"inheritedFrom,omitempty"` // parent type the field was flattened from (allOf)
+	Constraints   map[string]string `json:"constraints,omitempty"`   // validation keywords (pattern, minimum, ...) -> value
+
+	Extensions map[string]string `json:"extensions,omitempty"`
 }
 
 // TypeRef references a type by name, used for request/response bodies.
+// TypeName and ContentType describe the preferred media type; Content lists
+// every media type the body may use.
 type TypeRef struct {
-	TypeName    string `json:"typeName,omitempty"`
-	Description string `json:"description,omitempty"`
-	ContentType string `json:"contentType,omitempty"`
+	TypeName    string      `json:"typeName,omitempty"`
+	Description string      `json:"description,omitempty"`
+	ContentType string      `json:"contentType,omitempty"`
+	Content     []MediaType `json:"content,omitempty"`
+}
+
+// MediaType is one representation of a request or response body.
+type MediaType struct {
+	ContentType string    `json:"contentType"`
+	TypeName    string    `json:"typeName,omitempty"`
+	Examples    []Example `json:"examples,omitempty"`
+}
+
+// Example is a sample payload taken from the spec.
+type Example struct {
+	Name          string `json:"name,omitempty"`
+	Summary       string `json:"summary,omitempty"`
+	Value         string `json:"value,omitempty"`         // JSON-encoded for JSON media types, raw text otherwise
+	ExternalValue string `json:"externalValue,omitempty"` // URL of the example when not inlined
 }
 
 // Response represents an HTTP response or command output.
 type Response struct {
-	StatusCode  string   `json:"statusCode"`
-	Description string   `json:"description,omitempty"`
-	Body        *TypeRef `json:"body,omitempty"`
+	StatusCode  string      `json:"statusCode"`
+	Description string      `json:"description,omitempty"`
+	Body        *TypeRef    `json:"body,omitempty"`
+	Headers     []Parameter `json:"headers,omitempty"` // e.g. rate-limit or pagination headers
+	Links       []Link      `json:"links,omitempty"`
+}
+
+// Link describes how values from a response feed into a follow-up operation.
+type Link struct {
+	Name        string            `json:"name"`
+	OperationID string            `json:"operationId,omitempty"` // target operation ID or reference
+	Parameters  map[string]string `json:"parameters,omitempty"`  // target parameter -> value or runtime expression
+	RequestBody string            `json:"requestBody,omitempty"`
+	Description string            `json:"description,omitempty"`
 }
 
 // AuthScheme represents an authentication method.
@@ -77,6 +149,19 @@ type AuthScheme struct {
 	In          string `json:"in,omitempty"`     // header, query, cookie
 	Scheme      string `json:"scheme,omitempty"` // bearer, basic
 	Description string `json:"description,omitempty"`
+
+	BearerFormat     string      `json:"bearerFormat,omitempty"` // e.g. JWT
+	OpenIDConnectURL string      `json:"openIdConnectUrl,omitempty"`
+	Flows            []OAuthFlow `json:"flows,omitempty"`
+}
+
+// OAuthFlow describes one OAuth2 grant supported by an AuthScheme.
+type OAuthFlow struct {
+	Type             string            `json:"type"` // authorizationCode, implicit, password, clientCredentials
+	AuthorizationURL string            `json:"authorizationUrl,omitempty"`
+	TokenURL         string            `json:"tokenUrl,omitempty"`
+	RefreshURL       string            `json:"refreshUrl,omitempty"`
+	Scopes           map[string]string `json:"scopes,omitempty"` // scope name -> description
 }
 
 // Group organizes operations by resource, tag, or subcommand tree.
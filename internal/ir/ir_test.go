@@ -87,6 +87,30 @@ func TestRegistry_ProcessSources(t *testing.T) {
 	}
 }
 
+func TestRegistry_ProcessSources_Warnings(t *testing.T) {
+	plugin := &mockPlugin{
+		name:     "mock",
+		detectFn: func(s instructions.SpecSource) bool { return s.Type == "mock" },
+		ir: &IntermediateRepr{
+			Operations: []Operation{{ID: "mock_op"}},
+			Warnings:   []Warning{{Message: "unresolved $ref", Path: "spec.yaml"}},
+		},
+		warnings:  []Warning{{Message: "operation mock_op has no description"}},
+		fetchData: []byte("data"),
+	}
+
+	reg := NewRegistry()
+	reg.Register(plugin)
+
+	_, warnings, err := reg.ProcessSources([]instructions.SpecSource{{Type: "mock"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(warnings) != 2 || warnings[0].Message != "unresolved $ref" || warnings[1].Message != "operation mock_op has no description" {
+		t.Errorf("warnings = %v, want the parse warning, then Validate's", warnings)
+	}
+}
+
 func TestRegistry_Detect(t *testing.T) {
 	openapi := &mockPlugin{
 		name:     "openapi",
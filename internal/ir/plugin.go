@@ -80,8 +80,10 @@ func (r *Registry) ProcessSources(sources []instructions.SpecSource) (*Intermedi
 			return nil, nil, fmt.Errorf("[%s] parse: %w", plugin.Name(), err)
 		}
 
-		warnings := plugin.Validate(parsed)
-		allWarnings = append(allWarnings, warnings...)
+		parsed.Filter(src)
+
+		allWarnings = append(allWarnings, parsed.Warnings...)
+		allWarnings = append(allWarnings, plugin.Validate(parsed)...)
 
 		merged.Merge(parsed)
 	}
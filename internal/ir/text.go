@@ -0,0 +1,12 @@
+package ir
+
+// JoinSentence appends a sentence to a description. Either may be empty.
+func JoinSentence(desc, sentence string) string {
+	switch {
+	case sentence == "":
+		return desc
+	case desc == "":
+		return sentence
+	}
+	return desc + " " + sentence
+}
@@ -0,0 +1,509 @@
+package asyncapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/roberthamel/skill-compiler/internal/fetch"
+	"github.com/roberthamel/skill-compiler/internal/instructions"
+	"github.com/roberthamel/skill-compiler/internal/ir"
+	"github.com/roberthamel/skill-compiler/internal/schema"
+	"gopkg.in/yaml.v3"
+)
+
+// Plugin handles AsyncAPI 2.x and 3.x documents.
+type Plugin struct{}
+
+func New() *Plugin { return &Plugin{} }
+
+func (p *Plugin) Name() string { return "asyncapi" }
+
+// asyncAPIKeyRe finds the top-level asyncapi version key in YAML or JSON.
+var asyncAPIKeyRe = regexp.MustCompile(`(?m)^\s*\{?\s*"?asyncapi"?\s*:`)
+
+// Detect accepts explicitly typed sources and untyped YAML/JSON files that
+// declare an asyncapi version. It must be registered before the openapi
+// plugin, which claims every untyped YAML/JSON file.
+func (p *Plugin) Detect(source instructions.SpecSource) bool {
+	if source.Type == "asyncapi" {
+		return true
+	}
+	if source.Type != "" || source.Path == "" {
+		return false
+	}
+	switch strings.ToLower(filepath.Ext(source.Path)) {
+	case ".yaml", ".yml", ".json":
+	default:
+		return false
+	}
+	return fetch.HeadMatches(source.Path, asyncAPIKeyRe)
+}
+
+func (p *Plugin) Fetch(source instructions.SpecSource) ([]byte, error) {
+	return fetch.Source("asyncapi", source)
+}
+
+// asyncDoc covers both 2.x (operations nested in channels) and 3.x
+// (top-level operations referencing channels).
+type asyncDoc struct {
+	AsyncAPI           string                    `yaml:"asyncapi"`
+	Info               asyncInfo                 `yaml:"info"`
+	DefaultContentType string                    `yaml:"defaultContentType"`
+	Servers            map[string]asyncServer    `yaml:"servers"`
+	Channels           map[string]asyncChannel   `yaml:"channels"`
+	Operations         map[string]asyncOperation `yaml:"operations"` // 3.x
+	Components         struct {
+		Schemas         map[string]*schema.Schema      `yaml:"schemas"`
+		SecuritySchemes map[string]asyncSecurityScheme `yaml:"securitySchemes"`
+	} `yaml:"components"`
+}
+
+type asyncInfo struct {
+	Title       string `yaml:"title"`
+	Version     string `yaml:"version"`
+	Description string `yaml:"description"`
+}
+
+type asyncServer struct {
+	URL             string                 `yaml:"url"`      // 2.x
+	Host            string                 `yaml:"host"`     // 3.x
+	Pathname        string                 `yaml:"pathname"` // 3.x
+	Protocol        string                 `yaml:"protocol"`
+	ProtocolVersion string                 `yaml:"protocolVersion"`
+	Description     string                 `yaml:"description"`
+	Bindings        map[string]interface{} `yaml:"bindings"`
+	Security        []interface{}          `yaml:"security"`
+}
+
+type asyncChannel struct {
+	Address     string                    `yaml:"address"` // 3.x; 2.x uses the channel key
+	Description string                    `yaml:"description"`
+	Parameters  map[string]asyncParameter `yaml:"parameters"`
+	Subscribe   *asyncOperation           `yaml:"subscribe"` // 2.x
+	Publish     *asyncOperation           `yaml:"publish"`   // 2.x
+	Messages    map[string]asyncMessage   `yaml:"messages"`  // 3.x
+	ResolvedRef string                    `yaml:"$resolvedRef"`
+}
+
+type asyncParameter struct {
+	Description string         `yaml:"description"`
+	Schema      *schema.Schema `yaml:"schema"` // 2.x
+	Enum        []string       `yaml:"enum"`   // 3.x
+	Default     interface{}    `yaml:"default"`
+}
+
+type asyncOperation struct {
+	OperationID string         `yaml:"operationId"` // 2.x; 3.x uses the operation key
+	Action      string         `yaml:"action"`      // 3.x: send or receive
+	Channel     *asyncChannel  `yaml:"channel"`     // 3.x
+	Summary     string         `yaml:"summary"`
+	Description string         `yaml:"description"`
+	Deprecated  bool           `yaml:"deprecated"`
+	Tags        []asyncTag     `yaml:"tags"`
+	Message     *asyncMessage  `yaml:"message"`  // 2.x: a message or {oneOf: [...]}
+	Messages    []asyncMessage `yaml:"messages"` // 3.x
+	Security    []interface{}  `yaml:"security"` // overrides the servers' security
+}
+
+type asyncTag struct {
+	Name string `yaml:"name"`
+}
+
+type asyncMessage struct {
+	Name        string         `yaml:"name"`
+	MessageID   string         `yaml:"messageId"` // 2.x
+	Title       string         `yaml:"title"`
+	Summary     string         `yaml:"summary"`
+	Description string         `yaml:"description"`
+	ContentType string         `yaml:"contentType"`
+	Payload     interface{}    `yaml:"payload"`
+	Headers     interface{}    `yaml:"headers"`
+	Examples    []asyncExample `yaml:"examples"`
+	OneOf       []asyncMessage `yaml:"oneOf"` // 2.x
+	ResolvedRef string         `yaml:"$resolvedRef"`
+}
+
+type asyncExample struct {
+	Name    string      `yaml:"name"`
+	Summary string      `yaml:"summary"`
+	Payload interface{} `yaml:"payload"`
+}
+
+type asyncSecurityScheme struct {
+	Type        string `yaml:"type"`
+	Name        string `yaml:"name"`
+	In          string `yaml:"in"`
+	Scheme      string `yaml:"scheme"`
+	Description string `yaml:"description"`
+}
+
+func (p *Plugin) Parse(raw []byte, source instructions.SpecSource) (*ir.IntermediateRepr, error) {
+	rawDoc, warnings, err := schema.Load(raw, source)
+	if err != nil {
+		return nil, fmt.Errorf("parsing AsyncAPI document: %w", err)
+	}
+
+	resolved, err := yaml.Marshal(rawDoc)
+	if err != nil {
+		return nil, fmt.Errorf("re-marshaling resolved document: %w", err)
+	}
+	var doc asyncDoc
+	if err := yaml.Unmarshal(resolved, &doc); err != nil {
+		return nil, fmt.Errorf("parsing resolved AsyncAPI document: %w", err)
+	}
+	major := strings.SplitN(doc.AsyncAPI, ".", 2)[0]
+	if major != "2" && major != "3" {
+		return nil, fmt.Errorf("unsupported AsyncAPI version: %q (only 2.x and 3.x supported)", doc.AsyncAPI)
+	}
+
+	result := &ir.IntermediateRepr{
+		Metadata: map[string]string{
+			"title":       doc.Info.Title,
+			"description": doc.Info.Description,
+			"version":     doc.Info.Version,
+			"asyncapi":    doc.AsyncAPI,
+		},
+		Warnings: warnings,
+	}
+	if doc.DefaultContentType != "" {
+		result.Metadata["defaultContentType"] = doc.DefaultContentType
+	}
+	if len(doc.Servers) > 0 {
+		result.Metadata["servers"] = describeServers(doc.Servers)
+	}
+
+	schemas := schema.NewSet(doc.Components.Schemas)
+	b := &builder{doc: &doc, schemas: schemas, groups: make(map[string][]string)}
+	for _, name := range sortedKeys(doc.Servers) {
+		b.serverSecurity = append(b.serverSecurity, doc.Servers[name].Security...)
+	}
+
+	if major == "2" {
+		for _, key := range sortedKeys(doc.Channels) {
+			ch := doc.Channels[key]
+			// 2.x operations are described from the client's point of view
+			if ch.Publish != nil {
+				b.add(key, ch, *ch.Publish, "PUBLISH", messageList(ch.Publish.Message))
+			}
+			if ch.Subscribe != nil {
+				b.add(key, ch, *ch.Subscribe, "SUBSCRIBE", messageList(ch.Subscribe.Message))
+			}
+		}
+	} else {
+		for _, id := range sortedKeys(doc.Operations) {
+			op := doc.Operations[id]
+			if op.OperationID == "" {
+				op.OperationID = id
+			}
+			var ch asyncChannel
+			if op.Channel != nil {
+				ch = *op.Channel
+			}
+			address := ch.Address
+			if address == "" && ch.ResolvedRef != "" {
+				address = schema.RefName(ch.ResolvedRef)
+			}
+			messages := op.Messages
+			if len(messages) == 0 {
+				for _, name := range sortedKeys(ch.Messages) {
+					messages = append(messages, ch.Messages[name])
+				}
+			}
+			// 3.x actions are the application's; an application that
+			// receives is one clients publish to
+			method := "SUBSCRIBE"
+			if op.Action == "receive" {
+				method = "PUBLISH"
+			}
+			b.add(address, ch, op, method, messages)
+		}
+	}
+	result.Operations = b.ops
+
+	for _, name := range sortedKeys(b.groups) {
+		result.Groups = append(result.Groups, ir.Group{Name: name, Operations: b.groups[name]})
+	}
+	result.Types = schemas.Types()
+
+	for _, name := range sortedKeys(doc.Components.SecuritySchemes) {
+		scheme := doc.Components.SecuritySchemes[name]
+		result.Auth = append(result.Auth, ir.AuthScheme{
+			ID:          name,
+			Type:        scheme.Type,
+			Name:        scheme.Name,
+			In:          scheme.In,
+			Scheme:      scheme.Scheme,
+			Description: scheme.Description,
+		})
+	}
+
+	return result, nil
+}
+
+// nonIdentRe matches runs of characters not allowed in a synthesized ID.
+var nonIdentRe = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// builder accumulates operations and their channel groups.
+type builder struct {
+	doc            *asyncDoc
+	schemas        *schema.Set
+	serverSecurity []interface{} // security requirements of every server, for operations without their own
+	ops            []ir.Operation
+	groups         map[string][]string
+}
+
+// add records an operation on the channel at address. Messages a client
+// publishes become the request body; messages it receives become responses,
+// one per message.
+func (b *builder) add(address string, ch asyncChannel, op asyncOperation, method string, messages []asyncMessage) {
+	id := op.OperationID
+	if id == "" {
+		id = strings.ToLower(method) + "_" + strings.Trim(nonIdentRe.ReplaceAllString(address, "_"), "_")
+	}
+	desc := op.Description
+	if desc == "" {
+		desc = op.Summary
+	}
+	if desc == "" {
+		desc = ch.Description
+	}
+	irOp := ir.Operation{
+		ID:          id,
+		Name:        op.Summary,
+		Description: desc,
+		Method:      method,
+		Path:        address,
+		Deprecated:  op.Deprecated,
+	}
+	for _, tag := range op.Tags {
+		irOp.Tags = append(irOp.Tags, tag.Name)
+	}
+	security := op.Security
+	if security == nil {
+		security = b.serverSecurity
+	}
+	irOp.Auth, irOp.Scopes = securityAuth(security)
+
+	for _, name := range sortedKeys(ch.Parameters) {
+		param := ch.Parameters[name]
+		irParam := ir.Parameter{
+			Name:        name,
+			In:          "channel",
+			Description: param.Description,
+			Required:    true,
+			Type:        "string",
+		}
+		if param.Schema != nil {
+			irParam.Type = b.schemas.TypeName(param.Schema, schema.PascalCase(name)+"Parameter")
+		}
+		if len(param.Enum) > 0 {
+			irParam.Type = "string(" + strings.Join(param.Enum, "|") + ")"
+		}
+		if param.Default != nil {
+			irParam.Default = schema.FormatValue(param.Default)
+		}
+		irOp.Parameters = append(irOp.Parameters, irParam)
+	}
+
+	var body *ir.TypeRef
+	for i, msg := range messages {
+		name := messageName(msg)
+		if name == "" {
+			name = id
+			if len(messages) > 1 {
+				name = fmt.Sprintf("%s%d", id, i+1)
+			}
+		}
+		ct := msg.ContentType
+		if ct == "" {
+			ct = b.doc.DefaultContentType
+		}
+		if ct == "" {
+			ct = "application/json"
+		}
+		mt := ir.MediaType{
+			ContentType: ct,
+			TypeName:    b.schemas.TypeName(schema.Decode(msg.Payload), schema.PascalCase(name)+"Payload"),
+		}
+		for _, ex := range msg.Examples {
+			if ex.Payload == nil {
+				continue
+			}
+			mt.Examples = append(mt.Examples, ir.Example{
+				Name:    ex.Name,
+				Summary: ex.Summary,
+				Value:   schema.FormatExample(ct, ex.Payload),
+			})
+		}
+		headers := b.headers(msg)
+
+		if method == "PUBLISH" {
+			if body == nil {
+				body = &ir.TypeRef{TypeName: mt.TypeName, ContentType: ct, Description: messageDescription(msg)}
+			}
+			body.Content = append(body.Content, mt)
+			irOp.Parameters = append(irOp.Parameters, headers...)
+			continue
+		}
+		irOp.Responses = append(irOp.Responses, ir.Response{
+			StatusCode:  name,
+			Description: messageDescription(msg),
+			Body: &ir.TypeRef{
+				TypeName:    mt.TypeName,
+				ContentType: ct,
+				Content:     []ir.MediaType{mt},
+			},
+			Headers: headers,
+		})
+	}
+	irOp.RequestBody = body
+
+	b.ops = append(b.ops, irOp)
+	b.groups[address] = append(b.groups[address], irOp.ID)
+}
+
+// headers converts a message's headers schema into header parameters.
+func (b *builder) headers(msg asyncMessage) []ir.Parameter {
+	var params []ir.Parameter
+	for _, f := range b.schemas.Fields(schema.Decode(msg.Headers), schema.PascalCase(messageName(msg))+"Headers") {
+		params = append(params, ir.Parameter{
+			Name:        f.Name,
+			In:          "header",
+			Description: f.Description,
+			Required:    f.Required,
+			Type:        f.Type,
+		})
+	}
+	return params
+}
+
+// messageList flattens a 2.x operation message, which may be a oneOf.
+func messageList(msg *asyncMessage) []asyncMessage {
+	if msg == nil {
+		return nil
+	}
+	if len(msg.OneOf) > 0 {
+		return msg.OneOf
+	}
+	return []asyncMessage{*msg}
+}
+
+func messageName(msg asyncMessage) string {
+	switch {
+	case msg.Name != "":
+		return msg.Name
+	case msg.MessageID != "":
+		return msg.MessageID
+	case msg.ResolvedRef != "":
+		return schema.RefName(msg.ResolvedRef)
+	}
+	return ""
+}
+
+func messageDescription(msg asyncMessage) string {
+	for _, s := range []string{msg.Description, msg.Summary, msg.Title} {
+		if s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// securityAuth converts security requirements into the IDs of the schemes
+// they name, each listed once, and the scopes they require. 2.x requirements
+// map scheme names to scopes; 3.x requirements are the schemes themselves,
+// referenced from components, with their scopes inside.
+func securityAuth(reqs []interface{}) ([]string, map[string][]string) {
+	var auth []string
+	var scopes map[string][]string
+	add := func(name string, list interface{}) {
+		if !slices.Contains(auth, name) {
+			auth = append(auth, name)
+		}
+		items, _ := list.([]interface{})
+		for _, item := range items {
+			scope, ok := item.(string)
+			if !ok || slices.Contains(scopes[name], scope) {
+				continue
+			}
+			if scopes == nil {
+				scopes = make(map[string][]string)
+			}
+			scopes[name] = append(scopes[name], scope)
+		}
+	}
+	for _, req := range reqs {
+		m, ok := req.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if ref, ok := m["$resolvedRef"].(string); ok {
+			add(schema.RefName(ref), m["scopes"])
+			continue
+		}
+		if _, ok := m["type"]; ok {
+			// an inline 3.x scheme has no ID to reference
+			continue
+		}
+		for _, name := range sortedKeys(m) {
+			add(name, m[name])
+		}
+	}
+	return auth, scopes
+}
+
+// describeServers lists each server with its protocol and bindings.
+func describeServers(servers map[string]asyncServer) string {
+	var lines []string
+	for _, name := range sortedKeys(servers) {
+		s := servers[name]
+		url := s.URL
+		if url == "" {
+			url = s.Host + s.Pathname
+		}
+		line := fmt.Sprintf("%s: %s", name, url)
+		if s.Protocol != "" {
+			line += " (" + strings.TrimSpace(s.Protocol+" "+s.ProtocolVersion) + ")"
+		}
+		if s.Description != "" {
+			line += " — " + s.Description
+		}
+		if len(s.Bindings) > 0 {
+			bindings, _ := json.Marshal(s.Bindings)
+			line += "; bindings: " + string(bindings)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (p *Plugin) Validate(parsed *ir.IntermediateRepr) []ir.Warning {
+	var warnings []ir.Warning
+	for _, op := range parsed.Operations {
+		if op.Description == "" && op.Name == "" {
+			warnings = append(warnings, ir.Warning{
+				Message: fmt.Sprintf("operation %s has no description or summary", op.ID),
+			})
+		}
+		if op.RequestBody == nil && len(op.Responses) == 0 {
+			warnings = append(warnings, ir.Warning{
+				Message: fmt.Sprintf("operation %s on %s has no messages", op.ID, op.Path),
+			})
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,193 @@
+package asyncapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/roberthamel/skill-compiler/internal/instructions"
+	"github.com/roberthamel/skill-compiler/internal/ir"
+)
+
+func TestDetect(t *testing.T) {
+	p := New()
+
+	tests := []struct {
+		name   string
+		source instructions.SpecSource
+		want   bool
+	}{
+		{"explicit type", instructions.SpecSource{Type: "asyncapi", URL: "https://example.com/events"}, true},
+		{"asyncapi yaml file", instructions.SpecSource{Path: "testdata/streetlights-v2.yaml"}, true},
+		{"missing yaml file", instructions.SpecSource{Path: "testdata/missing.yaml"}, false},
+		{"typed for another plugin", instructions.SpecSource{Type: "openapi", Path: "testdata/orders-v3.yaml"}, false},
+		{"proto file", instructions.SpecSource{Path: "api.proto"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Detect(tt.source); got != tt.want {
+				t.Errorf("Detect(%+v) = %v, want %v", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func parseFile(t *testing.T, path string) *ir.IntermediateRepr {
+	t.Helper()
+	p := New()
+	source := instructions.SpecSource{Path: path}
+	raw, err := p.Fetch(source)
+	if err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+	result, err := p.Parse(raw, source)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	return result
+}
+
+func TestParse_V2(t *testing.T) {
+	result := parseFile(t, "testdata/streetlights-v2.yaml")
+
+	if result.Metadata["title"] != "Streetlights API" || result.Metadata["asyncapi"] != "2.6.0" {
+		t.Errorf("metadata = %v", result.Metadata)
+	}
+	servers := result.Metadata["servers"]
+	if !strings.Contains(servers, "mqtt.example.com:8883 (mqtt 5)") || !strings.Contains(servers, `"clientId":"streetlights"`) {
+		t.Errorf("servers = %q, want protocol and bindings", servers)
+	}
+	if len(result.Operations) != 2 {
+		t.Fatalf("got %d operations, want 2", len(result.Operations))
+	}
+
+	send := result.Operations[0]
+	if send.ID != "sendCommand" || send.Method != "PUBLISH" || send.Path != "smartylighting/streetlights/{streetlightId}/command" {
+		t.Errorf("sendCommand = %s %s %s", send.ID, send.Method, send.Path)
+	}
+	if len(send.Parameters) != 1 || send.Parameters[0].In != "channel" || !send.Parameters[0].Required {
+		t.Errorf("sendCommand parameters = %+v, want channel parameter", send.Parameters)
+	}
+	if send.RequestBody == nil || len(send.RequestBody.Content) != 2 {
+		t.Fatalf("sendCommand body = %+v, want one media type per oneOf message", send.RequestBody)
+	}
+	if send.RequestBody.Content[0].TypeName != "TurnOnPayload" || send.RequestBody.Content[1].TypeName != "DimLight" {
+		t.Errorf("sendCommand content = %+v", send.RequestBody.Content)
+	}
+
+	recv := result.Operations[1]
+	if recv.ID != "receiveLightMeasurement" || recv.Method != "SUBSCRIBE" || len(recv.Tags) != 1 {
+		t.Errorf("receiveLightMeasurement = %+v", recv)
+	}
+	if len(recv.Responses) != 1 {
+		t.Fatalf("got %d responses, want 1", len(recv.Responses))
+	}
+	resp := recv.Responses[0]
+	if resp.StatusCode != "lightMeasured" || resp.Body.TypeName != "LightMeasured" {
+		t.Errorf("response = %+v", resp)
+	}
+	if len(resp.Headers) != 1 || resp.Headers[0].Name != "traceId" || !resp.Headers[0].Required {
+		t.Errorf("headers = %+v", resp.Headers)
+	}
+	if ex := resp.Body.Content[0].Examples; len(ex) != 1 || ex[0].Value != `{"lumens":820,"sentAt":"2024-05-01T12:00:00Z"}` {
+		t.Errorf("examples = %+v", ex)
+	}
+
+	names := map[string]bool{}
+	for _, td := range result.Types {
+		names[td.Name] = true
+	}
+	for _, name := range []string{"LightMeasured", "DimLight", "TurnOnPayload"} {
+		if !names[name] {
+			t.Errorf("missing type %s", name)
+		}
+	}
+	if len(result.Groups) != 2 || len(result.Auth) != 1 {
+		t.Errorf("groups = %+v, auth = %+v", result.Groups, result.Auth)
+	}
+	for _, op := range result.Operations {
+		if strings.Join(op.Auth, ",") != "userPassword" {
+			t.Errorf("%s auth = %v, want the server's userPassword", op.ID, op.Auth)
+		}
+	}
+	if warnings := New().Validate(result); len(warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+}
+
+func TestParse_V3(t *testing.T) {
+	result := parseFile(t, "testdata/orders-v3.yaml")
+
+	if got := result.Metadata["servers"]; got != "broker: kafka.example.com:9092 (kafka)" {
+		t.Errorf("servers = %q", got)
+	}
+	if len(result.Operations) != 2 {
+		t.Fatalf("got %d operations, want 2", len(result.Operations))
+	}
+
+	// The application sends notifications, so clients subscribe to them
+	created := result.Operations[0]
+	if created.ID != "onOrderCreated" || created.Method != "SUBSCRIBE" || created.Path != "orders.created" {
+		t.Errorf("onOrderCreated = %s %s %s", created.ID, created.Method, created.Path)
+	}
+	if len(created.Responses) != 1 || created.Responses[0].StatusCode != "OrderCreated" || created.Responses[0].Body.TypeName != "Order" {
+		t.Errorf("onOrderCreated responses = %+v", created.Responses)
+	}
+
+	place := result.Operations[1]
+	if place.Method != "PUBLISH" || place.Path != "orders.place" || place.RequestBody == nil || place.RequestBody.TypeName != "PlaceOrderPayload" {
+		t.Errorf("placeOrder = %+v", place)
+	}
+
+	// operation security overrides the server's
+	if strings.Join(created.Auth, ",") != "sasl" {
+		t.Errorf("onOrderCreated auth = %v, want the broker's sasl", created.Auth)
+	}
+	if strings.Join(place.Auth, ",") != "orderToken" || strings.Join(place.Scopes["orderToken"], ",") != "orders:write" {
+		t.Errorf("placeOrder auth = %v, scopes = %v", place.Auth, place.Scopes)
+	}
+}
+
+func TestParse_Filter(t *testing.T) {
+	result := parseFile(t, "testdata/streetlights-v2.yaml")
+	result.Filter(instructions.SpecSource{ExcludeDeprecated: true})
+	if len(result.Operations) != 2 || len(result.Auth) != 1 {
+		t.Errorf("got %d operations and auth %+v, want both operations and userPassword", len(result.Operations), result.Auth)
+	}
+
+	result = parseFile(t, "testdata/orders-v3.yaml")
+	result.Filter(instructions.SpecSource{ExcludeOperations: []string{"placeOrder"}})
+	if len(result.Auth) != 1 || result.Auth[0].ID != "sasl" {
+		t.Errorf("auth = %+v, want only sasl, used by the remaining operation", result.Auth)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	p := New()
+	for _, src := range []string{
+		"openapi: 3.0.0\n",
+		"asyncapi: 1.2.0\n",
+		"asyncapi: [\n",
+	} {
+		if _, err := p.Parse([]byte(src), instructions.SpecSource{Type: "asyncapi"}); err == nil {
+			t.Errorf("Parse(%q): expected error", src)
+		}
+	}
+}
+
+func TestValidate_NoMessages(t *testing.T) {
+	p := New()
+	src := `asyncapi: 2.6.0
+info: {title: T, version: "1"}
+channels:
+  pings:
+    subscribe:
+      operationId: onPing
+`
+	result, err := p.Parse([]byte(src), instructions.SpecSource{Type: "asyncapi"})
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if warnings := New().Validate(result); len(warnings) != 2 {
+		t.Errorf("got %d warnings, want 2 (no description, no messages): %v", len(warnings), warnings)
+	}
+}
@@ -1,94 +1,162 @@
 package cli
 
 import (
-	"context"
+	"encoding/json"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
-	"time"
 
 	"github.com/roberthamel/skill-compiler/internal/instructions"
 	"github.com/roberthamel/skill-compiler/internal/ir"
 )
 
-// Plugin handles CLI binary help-tree crawling.
+// Plugin handles CLI binary help-tree crawling, and man pages for tools
+// documented there instead.
 type Plugin struct{}
 
 func New() *Plugin { return &Plugin{} }
 
 func (p *Plugin) Name() string { return "cli" }
 
+// manFileRe matches man page file names, e.g. git-commit.1 or tar.1.gz.
+var manFileRe = regexp.MustCompile(`\.[1-9][a-z]*(\.gz|\.bz2)?$`)
+
+// Detect accepts cli sources naming a binary or man page files, and untyped
+// paths to a man page.
 func (p *Plugin) Detect(source instructions.SpecSource) bool {
-	return source.Type == "cli" && source.Binary != ""
+	if source.Type == "cli" {
+		return source.Binary != "" || source.Path != ""
+	}
+	return source.Type == "" && manFileRe.MatchString(source.Path)
 }
 
 func (p *Plugin) Fetch(source instructions.SpecSource) ([]byte, error) {
+	if source.Path != "" {
+		return fetchManFiles(source)
+	}
+
+	r, err := newRunner(source)
+	if err != nil {
+		return nil, err
+	}
+	defer r.close()
+	if source.Man {
+		return fetchManPages(r, source)
+	}
+
 	binary := source.Binary
 	if _, err := exec.LookPath(binary); err != nil {
 		return nil, fmt.Errorf("binary %q not found in PATH", binary)
 	}
 
+	var key string
+	if !source.NoCache {
+		key = crawlCacheKey(binary, source)
+		if data, ok := readCrawlCache(key); ok {
+			return data, nil
+		}
+	}
+
+	if _, err := helpDialectFor(source.HelpFormat, ""); err != nil {
+		return nil, err
+	}
 	helpFlag := source.HelpFlag
 	if helpFlag == "" {
 		helpFlag = "--help"
 	}
-	maxDepth := source.MaxDepth
-	if maxDepth <= 0 {
-		maxDepth = 3
-	}
 
-	excludeSet := make(map[string]bool)
-	for _, e := range source.Exclude {
-		excludeSet[e] = true
-	}
-
-	// BFS crawl the help tree
-	type cmdEntry struct {
-		path  []string
-		depth int
+	// Completion sources, when asked for and available, name commands and
+	// flags exactly; help output is still read for descriptions
+	var complete completer
+	var warnings []string
+	if source.Completion != "" {
+		c, err := newCompleter(r, binary, source.Completion)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("completion unavailable, falling back to help output: %v", err))
+		}
+		complete = c
 	}
 
-	var results []crawlResult
-	queue := []cmdEntry{{path: nil, depth: 0}}
-
-	for len(queue) > 0 {
-		entry := queue[0]
-		queue = queue[1:]
-
-		args := append(entry.path, helpFlag)
-		output, err := runWithTimeout(binary, args, 5*time.Second)
-		if err != nil {
-			// Log warning but continue
-			results = append(results, crawlResult{
-				commandPath: entry.path,
-				helpText:    fmt.Sprintf("(error: %s)", err),
-			})
-			continue
+	results, err := crawlTree(concurrency(source), maxDepth(source), excludeSet(source), func(path []string) (crawlResult, []string, error) {
+		var comp *completion
+		if complete != nil {
+			comp = complete(path)
 		}
 
-		result := crawlResult{
-			commandPath: entry.path,
-			helpText:    output,
+		args := append(append([]string(nil), path...), helpFlag)
+		output, err := r.run(binary, args...)
+		if err != nil && comp == nil {
+			return crawlResult{}, nil, err
 		}
-		result.parsed = parseHelpOutput(output)
-		results = append(results, result)
 
-		// Discover subcommands for BFS
-		if entry.depth < maxDepth {
-			for _, sub := range result.parsed.subcommands {
-				if excludeSet[sub] {
-					continue
+		result := crawlResult{helpText: output, completion: comp}
+		if err != nil {
+			result.helpText = fmt.Sprintf("(error: %s)", err)
+		}
+		d, _ := helpDialectFor(source.HelpFormat, result.helpText)
+		result.parsed = d.parse(result.helpText)
+
+		subcommands := result.parsed.subcommands
+		if comp != nil {
+			subcommands = nil
+			// Cobra also completes a leaf command's arguments; only a
+			// command whose usage takes a [command] has subcommands
+			if err != nil || !strings.Contains(output, "Usage:") || strings.Contains(output, "[command]") {
+				for _, s := range comp.Subcommands {
+					subcommands = append(subcommands, s.Name)
 				}
-				newPath := make([]string, len(entry.path))
-				copy(newPath, entry.path)
-				newPath = append(newPath, sub)
-				queue = append(queue, cmdEntry{path: newPath, depth: entry.depth + 1})
 			}
 		}
+		return result, subcommands, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("running %s %s: %w", binary, helpFlag, err)
+	}
+
+	data := formatBlocks(binary, results)
+	if len(warnings) > 0 {
+		// A degraded crawl is not cached; its warnings travel ahead of the
+		// command blocks for Parse to report
+		var buf strings.Builder
+		for _, w := range warnings {
+			fmt.Fprintf(&buf, "=== WARNING: %s ===\n", strings.ReplaceAll(w, "\n", " "))
+		}
+		return append([]byte(buf.String()), data...), nil
 	}
+	writeCrawlCache(key, results, data)
+	return data, nil
+}
 
-	// Serialize results as structured text for Parse to consume
+func maxDepth(source instructions.SpecSource) int {
+	if source.MaxDepth <= 0 {
+		return 3
+	}
+	return source.MaxDepth
+}
+
+func concurrency(source instructions.SpecSource) int {
+	if source.Concurrency <= 0 {
+		return defaultConcurrency
+	}
+	return source.Concurrency
+}
+
+func excludeSet(source instructions.SpecSource) map[string]bool {
+	set := make(map[string]bool)
+	for _, e := range source.Exclude {
+		set[e] = true
+	}
+	return set
+}
+
+// formatBlocks serializes crawl results as structured text for Parse to
+// consume.
+func formatBlocks(binary string, results []crawlResult) []byte {
 	var buf strings.Builder
 	for _, r := range results {
 		cmdPath := binary
@@ -97,16 +165,128 @@ func (p *Plugin) Fetch(source instructions.SpecSource) ([]byte, error) {
 		}
 		fmt.Fprintf(&buf, "=== COMMAND: %s ===\n", cmdPath)
 		fmt.Fprintf(&buf, "%s\n", r.helpText)
+		if r.completion != nil {
+			data, _ := json.Marshal(r.completion)
+			fmt.Fprintf(&buf, "=== COMPLETION ===\n%s\n", data)
+		}
 		fmt.Fprintf(&buf, "=== END ===\n\n")
 	}
+	return []byte(buf.String())
+}
+
+// fetchManPages crawls the man pages of a binary and its subcommands, found
+// with man -w. Subcommands are the pages the binary's page refers to that
+// are named after it (git-commit(1) for git commit).
+func fetchManPages(r *runner, source instructions.SpecSource) ([]byte, error) {
+	binary := source.Binary
+	if _, err := exec.LookPath("man"); err != nil {
+		return nil, fmt.Errorf("man not found in PATH")
+	}
+	results, err := crawlTree(concurrency(source), maxDepth(source), excludeSet(source), func(path []string) (crawlResult, []string, error) {
+		page := strings.Join(append([]string{binary}, path...), "-")
+		src, err := lookupManPage(r, page)
+		if err != nil {
+			return crawlResult{}, nil, err
+		}
+		return crawlResult{helpText: src}, parseManPage(src).subpages(page), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return formatBlocks(binary, results), nil
+}
+
+// lookupManPage finds a page with man -w and reads its roff source.
+func lookupManPage(r *runner, name string) (string, error) {
+	out, err := r.run("man", "-w", name)
+	if err != nil {
+		return "", fmt.Errorf("looking up man page %s: %w", name, err)
+	}
+	path := strings.TrimSpace(strings.SplitN(out, "\n", 2)[0])
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("no man page for %s", name)
+	}
+	return readManFile(path)
+}
+
+// fetchManFiles reads man pages from a file, or every man page in a
+// directory. Pages named after the binary (the shortest page name when no
+// binary is given) become its subcommands.
+func fetchManFiles(source instructions.SpecSource) ([]byte, error) {
+	info, err := os.Stat(source.Path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{source.Path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(source.Path)
+		if err != nil {
+			return nil, err
+		}
+		files = nil
+		for _, e := range entries {
+			if !e.IsDir() && manFileRe.MatchString(e.Name()) {
+				files = append(files, filepath.Join(source.Path, e.Name()))
+			}
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no man pages found in %s", source.Path)
+		}
+	}
+
+	type page struct {
+		name, src string
+	}
+	var pages []page
+	for _, f := range files {
+		src, err := readManFile(f)
+		if err != nil {
+			return nil, err
+		}
+		name, _ := parseManPage(src).nameLine()
+		if name == "" {
+			name = strings.SplitN(filepath.Base(f), ".", 2)[0]
+		}
+		pages = append(pages, page{name: name, src: src})
+	}
+	binary := source.Binary
+	if binary == "" {
+		binary = pages[0].name
+		for _, pg := range pages {
+			if len(pg.name) < len(binary) {
+				binary = pg.name
+			}
+		}
+	}
 
-	return []byte(buf.String()), nil
+	exclude := excludeSet(source)
+	var results []crawlResult
+	for _, pg := range pages {
+		var path []string
+		switch {
+		case pg.name == binary:
+		case strings.HasPrefix(pg.name, binary+"-"):
+			path = []string{strings.TrimPrefix(pg.name, binary+"-")}
+		default:
+			path = []string{pg.name}
+		}
+		if len(path) > 0 && exclude[path[0]] {
+			continue
+		}
+		results = append(results, crawlResult{commandPath: path, helpText: pg.src})
+	}
+	// the binary's own page first
+	sort.SliceStable(results, func(i, j int) bool {
+		return len(results[i].commandPath) < len(results[j].commandPath)
+	})
+	return formatBlocks(binary, results), nil
 }
 
 type crawlResult struct {
 	commandPath []string
 	helpText    string
 	parsed      parsedHelp
+	completion  *completion // from the completion source, if any
 }
 
 type parsedHelp struct {
@@ -114,6 +294,9 @@ type parsedHelp struct {
 	subcommands []string
 	flags       []parsedFlag
 	aliases     []string
+	examples    []string
+	usage       []string          // usage lines, e.g. "foo create <name> [flags]"
+	argDescs    map[string]string // positional argument descriptions by name
 }
 
 type parsedFlag struct {
@@ -122,25 +305,59 @@ type parsedFlag struct {
 	flagType  string
 	defVal    string
 	desc      string
+	global    bool // listed as inherited, e.g. under "Global Flags:"
 }
 
 func (p *Plugin) Parse(raw []byte, source instructions.SpecSource) (*ir.IntermediateRepr, error) {
 	content := string(raw)
 	blocks := splitCommandBlocks(content)
 
+	binary := source.Binary
+	if binary == "" && len(blocks) > 0 {
+		// man page files name the binary themselves
+		binary = strings.Fields(blocks[0].command)[0]
+	}
 	result := &ir.IntermediateRepr{
 		Metadata: map[string]string{
-			"binary": source.Binary,
+			"binary": binary,
 			"type":   "cli",
 		},
 	}
+	preamble, _, _ := strings.Cut(content, "=== COMMAND: ")
+	for _, m := range warningRe.FindAllStringSubmatch(preamble, -1) {
+		result.Warnings = append(result.Warnings, ir.Warning{Message: m[1]})
+	}
 
 	groupMap := make(map[string][]string)
+	inherited := make(map[string]map[string]bool) // operation ID -> flags listed as inherited
 
 	for _, block := range blocks {
 		cmdPath := block.command
 		helpText := block.text
-		parsed := parseHelpOutput(helpText)
+		var parsed parsedHelp
+		if isRoff(helpText) {
+			page := parseManPage(helpText)
+			parsed = page.parsed()
+			helpText = page.text()
+			result.Metadata["docs"] = "man"
+		} else {
+			d, err := helpDialectFor(source.HelpFormat, helpText)
+			if err != nil {
+				return nil, err
+			}
+			parsed = d.parse(helpText)
+			if _, ok := result.Metadata["help-format"]; !ok {
+				result.Metadata["help-format"] = d.name
+			}
+		}
+		if block.completion != "" {
+			var comp completion
+			if err := json.Unmarshal([]byte(block.completion), &comp); err != nil {
+				return nil, fmt.Errorf("parsing completion for %s: %w", cmdPath, err)
+			}
+			parsed = mergeCompletion(parsed, comp)
+			result.Metadata["completion"] = "true"
+		}
 
 		opID := strings.ReplaceAll(cmdPath, " ", "_")
 		op := ir.Operation{
@@ -150,13 +367,46 @@ func (p *Plugin) Parse(raw []byte, source instructions.SpecSource) (*ir.Intermed
 			Path:        cmdPath,
 			Aliases:     parsed.aliases,
 			RawHelpText: helpText,
+			Examples:    parsed.examples,
+		}
+
+		args, requiredFlags := parseUsage(usageLine(parsed.usage), len(strings.Fields(cmdPath)), parsed.argDescs)
+		for _, a := range args {
+			if isSubcommandChoice(a, parsed.subcommands) {
+				continue
+			}
+			typ := "string"
+			switch {
+			case len(a.values) > 0:
+				typ = "string(" + strings.Join(a.values, "|") + ")"
+			case a.variadic:
+				typ = "[]string"
+			}
+			op.Parameters = append(op.Parameters, ir.Parameter{
+				Name:        a.name,
+				In:          "argument",
+				Description: parsed.argDescs[a.name],
+				Required:    a.required,
+				Type:        typ,
+			})
 		}
 
 		for _, f := range parsed.flags {
+			if f.global {
+				if inherited[opID] == nil {
+					inherited[opID] = make(map[string]bool)
+				}
+				inherited[opID][f.name] = true
+			}
+			desc, required := requiredMarker(f.desc)
+			for _, rf := range requiredFlags {
+				required = required || rf == f.name || (f.shorthand != "" && rf == f.shorthand)
+			}
 			op.Parameters = append(op.Parameters, ir.Parameter{
 				Name:        f.name,
 				In:          "flag",
-				Description: f.desc,
+				Description: desc,
+				Required:    required,
 				Type:        f.flagType,
 				Default:     f.defVal,
 				Shorthand:   f.shorthand,
@@ -173,6 +423,8 @@ func (p *Plugin) Parse(raw []byte, source instructions.SpecSource) (*ir.Intermed
 		}
 	}
 
+	dedupeInheritedFlags(result.Operations, inherited)
+
 	for name, ops := range groupMap {
 		result.Groups = append(result.Groups, ir.Group{
 			Name:       name,
@@ -183,6 +435,116 @@ func (p *Plugin) Parse(raw []byte, source instructions.SpecSource) (*ir.Intermed
 	return result, nil
 }
 
+// dedupeInheritedFlags lists each flag subcommands inherit once, on the
+// command declaring it, marked persistent; subcommands refer to it by
+// name. A subcommand's flag is inherited when its help lists it as such
+// (cobra's "Global Flags:"), or when it repeats an ancestor's flag exactly.
+func dedupeInheritedFlags(ops []ir.Operation, inherited map[string]map[string]bool) {
+	byPath := make(map[string]*ir.Operation, len(ops))
+	for i := range ops {
+		byPath[ops[i].Path] = &ops[i]
+	}
+	// ancestors first, so a flag is traced to the command declaring it
+	order := make([]*ir.Operation, len(ops))
+	for i := range ops {
+		order[i] = &ops[i]
+	}
+	slices.SortStableFunc(order, func(a, b *ir.Operation) int {
+		return len(strings.Fields(a.Path)) - len(strings.Fields(b.Path))
+	})
+
+	for _, op := range order {
+		words := strings.Fields(op.Path)
+		for i, prm := range op.Parameters {
+			if prm.In != "flag" {
+				continue
+			}
+			for n := len(words) - 1; n > 0; n-- {
+				parent, ok := byPath[strings.Join(words[:n], " ")]
+				if !ok {
+					continue
+				}
+				j := slices.IndexFunc(parent.Parameters, func(p ir.Parameter) bool { return p.In == "flag" && p.Name == prm.Name })
+				if j < 0 {
+					continue
+				}
+				declared := parent.Parameters[j]
+				if !inherited[op.ID][prm.Name] && !sameFlag(prm, declared) {
+					break
+				}
+				from := declared.InheritedFrom
+				if from == "" {
+					from = parent.ID
+					parent.Parameters[j].Persistent = true
+				}
+				op.Parameters[i] = ir.Parameter{Name: prm.Name, In: "flag", InheritedFrom: from}
+				break
+			}
+		}
+	}
+}
+
+// sameFlag reports whether two flags are declared alike.
+func sameFlag(a, b ir.Parameter) bool {
+	return a.Shorthand == b.Shorthand && a.Type == b.Type && a.Default == b.Default && a.Description == b.Description
+}
+
+// isSubcommandChoice reports whether a {a,b} usage argument is the choice of
+// subcommand, as argparse writes it.
+func isSubcommandChoice(a parsedArg, subcommands []string) bool {
+	if len(a.values) == 0 {
+		return false
+	}
+	for _, v := range a.values {
+		if !slices.Contains(subcommands, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeCompletion takes a command's flags from its completion, keeping the
+// argument types and defaults help output gives, and any flags only help
+// output lists. Flags with enumerated values get a type like string(a|b).
+func mergeCompletion(parsed parsedHelp, comp completion) parsedHelp {
+	if parsed.description == "" {
+		parsed.description = comp.Description
+	}
+	fromHelp := make(map[string]parsedFlag)
+	for _, f := range parsed.flags {
+		fromHelp[f.name] = f
+	}
+	var flags []parsedFlag
+	seen := make(map[string]bool)
+	for _, cf := range comp.Flags {
+		f := fromHelp[cf.Name]
+		if f.flagType != "" && strings.TrimSpace(f.flagType+" "+f.desc) == cf.Description {
+			// help parsing took the first word of a boolean's description
+			f.flagType = ""
+		}
+		f.name, f.shorthand = cf.Name, cf.Shorthand
+		if cf.Description != "" {
+			f.desc = cf.Description
+		}
+		if len(cf.Values) > 0 {
+			base := f.flagType
+			if base == "" {
+				base = "string"
+			}
+			f.flagType = base + "(" + strings.Join(cf.Values, "|") + ")"
+		}
+		flags = append(flags, f)
+		seen[f.name] = true
+	}
+	for _, f := range parsed.flags {
+		if !seen[f.name] {
+			flags = append(flags, f)
+		}
+	}
+	parsed.flags = flags
+	return parsed
+}
+
 func (p *Plugin) Validate(parsed *ir.IntermediateRepr) []ir.Warning {
 	var warnings []ir.Warning
 	for _, op := range parsed.Operations {
@@ -196,8 +558,9 @@ func (p *Plugin) Validate(parsed *ir.IntermediateRepr) []ir.Warning {
 }
 
 type commandBlock struct {
-	command string
-	text    string
+	command    string
+	text       string
+	completion string // JSON-encoded completion, if the crawl used one
 }
 
 func splitCommandBlocks(content string) []commandBlock {
@@ -215,30 +578,15 @@ func splitCommandBlocks(content string) []commandBlock {
 		if textEnd >= 0 {
 			text = rest[:textEnd]
 		}
-		blocks = append(blocks, commandBlock{command: cmd, text: strings.TrimSpace(text)})
+		text, comp, _ := strings.Cut(text, "\n=== COMPLETION ===\n")
+		blocks = append(blocks, commandBlock{command: cmd, text: strings.TrimSpace(text), completion: strings.TrimSpace(comp)})
 	}
 	return blocks
 }
 
-func runWithTimeout(binary string, args []string, timeout time.Duration) (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-	cmd := exec.CommandContext(ctx, binary, args...)
-	out, err := cmd.CombinedOutput()
-	if ctx.Err() == context.DeadlineExceeded {
-		return "", fmt.Errorf("command timed out after %s", timeout)
-	}
-	// Many CLIs return non-zero for --help; treat output as valid if we got output
-	if len(out) > 0 {
-		return string(out), nil
-	}
-	if err != nil {
-		return "", err
-	}
-	return string(out), nil
-}
-
 var (
+	// Matches the warning lines Fetch writes ahead of the command blocks
+	warningRe = regexp.MustCompile(`(?m)^=== WARNING: (.*) ===$`)
 	// Matches lines like "  command-name    Description text"
 	subcommandRe = regexp.MustCompile(`^\s{2,}(\S+)\s{2,}(.*)$`)
 	// Matches flag lines like "  -f, --flag string   Description"
@@ -247,9 +595,13 @@ var (
 	longFlagRe = regexp.MustCompile(`^\s+(--[\w-]+)\s+(\S+)?\s*(.*)$`)
 	// Matches aliases line like "Aliases:\n  cmd, c"
 	aliasRe = regexp.MustCompile(`(?i)aliases?:\s*\n?\s*(.+)`)
+	// Matches an inline usage line like "Usage: foo create <name>"
+	usageRe = regexp.MustCompile(`(?i)^usage:\s*(\S.*)$`)
 )
 
-func parseHelpOutput(text string) parsedHelp {
+// parseCobraHelp parses cobra-style help output, and is the fallback for
+// output no dialect recognizes.
+func parseCobraHelp(text string) parsedHelp {
 	var result parsedHelp
 	lines := strings.Split(text, "\n")
 
@@ -257,10 +609,18 @@ func parseHelpOutput(text string) parsedHelp {
 	var descLines []string
 	inDesc := true
 
+	var exampleLines []string
 	section := ""
 	for _, line := range lines {
 		lower := strings.ToLower(strings.TrimSpace(line))
 
+		if m := usageRe.FindStringSubmatch(line); m != nil {
+			inDesc = false
+			section = "usage"
+			result.usage = append(result.usage, strings.TrimSpace(m[1]))
+			continue
+		}
+
 		// Detect sections
 		if strings.HasSuffix(lower, ":") && !strings.HasPrefix(line, " ") {
 			inDesc = false
@@ -271,6 +631,14 @@ func parseHelpOutput(text string) parsedHelp {
 			if inDesc && len(descLines) > 0 {
 				inDesc = false
 			}
+			switch section {
+			case "examples", "example":
+				exampleLines = append(exampleLines, "")
+			case "usage":
+				// argparse-style help describes the command after its usage
+				section = ""
+				inDesc = len(descLines) == 0
+			}
 			continue
 		}
 
@@ -280,6 +648,19 @@ func parseHelpOutput(text string) parsedHelp {
 		}
 
 		switch section {
+		case "usage":
+			if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+				result.usage = append(result.usage, strings.TrimSpace(line))
+			}
+		case "examples", "example":
+			exampleLines = append(exampleLines, line)
+		case "arguments", "positional arguments", "args":
+			if m := subcommandRe.FindStringSubmatch(line); m != nil {
+				if result.argDescs == nil {
+					result.argDescs = make(map[string]string)
+				}
+				result.argDescs[strings.Trim(m[1], "<>[]")] = strings.TrimSpace(m[2])
+			}
 		case "available commands", "commands", "subcommands":
 			if m := subcommandRe.FindStringSubmatch(line); m != nil {
 				result.subcommands = append(result.subcommands, m[1])
@@ -291,18 +672,21 @@ func parseHelpOutput(text string) parsedHelp {
 					name:      m[2],
 					flagType:  m[3],
 					desc:      strings.TrimSpace(m[4]),
+					global:    section == "global flags",
 				})
 			} else if m := longFlagRe.FindStringSubmatch(line); m != nil {
 				result.flags = append(result.flags, parsedFlag{
 					name:     m[1],
 					flagType: m[2],
 					desc:     strings.TrimSpace(m[3]),
+					global:   section == "global flags",
 				})
 			}
 		}
 	}
 
 	result.description = strings.Join(descLines, " ")
+	result.examples = helpExamples(exampleLines)
 
 	// Extract aliases
 	if m := aliasRe.FindStringSubmatch(text); m != nil {
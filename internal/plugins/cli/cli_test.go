@@ -1,12 +1,18 @@
 package cli
 
 import (
+	"compress/gzip"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/roberthamel/skill-compiler/internal/instructions"
+	"github.com/roberthamel/skill-compiler/internal/ir"
 )
 
 func TestDetect(t *testing.T) {
@@ -19,6 +25,10 @@ func TestDetect(t *testing.T) {
 	}{
 		{"cli with binary", instructions.SpecSource{Type: "cli", Binary: "ls"}, true},
 		{"cli no binary", instructions.SpecSource{Type: "cli"}, false},
+		{"cli man pages", instructions.SpecSource{Type: "cli", Path: "man"}, true},
+		{"untyped man page", instructions.SpecSource{Path: "man/tool.1"}, true},
+		{"untyped gzipped man page", instructions.SpecSource{Path: "tool.8.gz"}, true},
+		{"untyped other file", instructions.SpecSource{Path: "tool.txt"}, false},
 		{"openapi type", instructions.SpecSource{Type: "openapi", Path: "api.yaml"}, false},
 	}
 	for _, tt := range tests {
@@ -115,3 +125,723 @@ func TestParse_CommandBlocks(t *testing.T) {
 		t.Error("should have parsed flags into parameters")
 	}
 }
+
+func TestParse_InheritedFlags(t *testing.T) {
+	input := "=== COMMAND: tool ===\nA tool\n\nFlags:\n  -c, --config string   Config file\n      --debug int       Debug level\n=== END ===\n" +
+		"=== COMMAND: tool get ===\nGet things\n\nFlags:\n  -o, --output string   Output format\n      --debug int       Debug level for get\n\nGlobal Flags:\n  -c, --config string   Config file\n=== END ===\n" +
+		"=== COMMAND: tool get pods ===\nGet pods\n\nFlags:\n  -o, --output string   Output format\n\nGlobal Flags:\n  -c, --config string   Config file\n=== END ===\n"
+	result, err := New().Parse([]byte(input), instructions.SpecSource{Type: "cli", Binary: "tool"})
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	flags := map[string]ir.Parameter{}
+	for _, op := range result.Operations {
+		for _, prm := range op.Parameters {
+			flags[op.ID+" "+prm.Name] = prm
+		}
+	}
+	if f := flags["tool --config"]; !f.Persistent || f.Description != "Config file" || f.Type != "string" {
+		t.Errorf("tool --config = %+v, want the persistent declaration", f)
+	}
+	for _, key := range []string{"tool_get --config", "tool_get_pods --config"} {
+		if f := flags[key]; f.InheritedFrom != "tool" || f.Description != "" || f.Type != "" {
+			t.Errorf("%s = %+v, want a reference to tool", key, f)
+		}
+	}
+	// identical to the parent's, though not listed as global
+	if f := flags["tool_get_pods --output"]; f.InheritedFrom != "tool_get" {
+		t.Errorf("tool get pods --output = %+v, want a reference to tool get", f)
+	}
+	if f := flags["tool_get --output"]; !f.Persistent {
+		t.Errorf("tool get --output = %+v, want persistent", f)
+	}
+	// a different flag of the same name is the subcommand's own
+	if f := flags["tool_get --debug"]; f.InheritedFrom != "" || f.Description != "Debug level for get" {
+		t.Errorf("tool get --debug = %+v, want its own declaration", f)
+	}
+	if f := flags["tool --debug"]; f.Persistent {
+		t.Errorf("tool --debug = %+v, want it not persistent", f)
+	}
+}
+
+func TestParseManPage(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "man", "tool.1"))
+	if err != nil {
+		t.Fatalf("reading testdata: %v", err)
+	}
+	if !isRoff(string(data)) {
+		t.Fatal("isRoff should recognize a man page")
+	}
+	page := parseManPage(string(data))
+	result := page.parsed()
+
+	if !strings.HasPrefix(result.description, "manage widgets from the command line\n\ntool creates") {
+		t.Errorf("description = %q", result.description)
+	}
+
+	want := []parsedFlag{
+		{name: "--verbose", shorthand: "-v", desc: "Print each request as it is made."},
+		{name: "--config", shorthand: "-c", flagType: "file", desc: "Read configuration from file instead of the default."},
+		{name: "--color", flagType: "when", desc: "Colorize output: always, never or auto."},
+	}
+	if len(result.flags) != len(want) {
+		t.Fatalf("got %d flags, want %d: %+v", len(result.flags), len(want), result.flags)
+	}
+	for i, f := range result.flags {
+		if f != want[i] {
+			t.Errorf("flag %d = %+v, want %+v", i, f, want[i])
+		}
+	}
+
+	wantExamples := []string{
+		"# List every widget\ntool list --all",
+		"# Create a widget from a file\ntool create -f widget.json",
+	}
+	if strings.Join(result.examples, "|") != strings.Join(wantExamples, "|") {
+		t.Errorf("examples = %q, want %q", result.examples, wantExamples)
+	}
+
+	if subs := page.subpages("tool"); len(subs) != 1 || subs[0] != "create" {
+		t.Errorf("subpages = %v, want [create]", subs)
+	}
+	if text := page.text(); !strings.Contains(text, "SYNOPSIS\n    tool [-v] [-c file] command [args]") {
+		t.Errorf("rendered text missing synopsis:\n%s", text)
+	}
+}
+
+func TestParseManPage_Mdoc(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "mdoc", "fetch.1"))
+	if err != nil {
+		t.Fatalf("reading testdata: %v", err)
+	}
+	page := parseManPage(string(data))
+	result := page.parsed()
+
+	if name, summary := page.nameLine(); name != "fetch" || summary != "retrieve a file by URL" {
+		t.Errorf("nameLine() = %q, %q", name, summary)
+	}
+	flags := map[string]parsedFlag{}
+	for _, f := range result.flags {
+		flags[f.name] = f
+	}
+	if f := flags["-o"]; f.flagType != "file" || !strings.HasPrefix(f.desc, "Write the output to file") {
+		t.Errorf("-o = %+v", f)
+	}
+	if f := flags["--verbose"]; f.shorthand != "-v" {
+		t.Errorf("--verbose = %+v, want shorthand -v", f)
+	}
+	if len(result.examples) != 1 || result.examples[0] != "# Save a page under another name\nfetch -o index.html https://example.com/" {
+		t.Errorf("examples = %q", result.examples)
+	}
+}
+
+func TestFetch_ManFiles(t *testing.T) {
+	p := New()
+	source := instructions.SpecSource{Type: "cli", Path: filepath.Join("testdata", "man")}
+	raw, err := p.Fetch(source)
+	if err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+	result, err := p.Parse(raw, source)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	if result.Metadata["binary"] != "tool" {
+		t.Errorf("binary = %q, want tool", result.Metadata["binary"])
+	}
+	if len(result.Operations) != 2 {
+		t.Fatalf("got %d operations, want 2", len(result.Operations))
+	}
+	if result.Operations[0].ID != "tool" || result.Operations[1].ID != "tool_create" {
+		t.Errorf("operations = %s, %s", result.Operations[0].ID, result.Operations[1].ID)
+	}
+	create := result.Operations[1]
+	if create.Description != "create a widget\n\nCreates a widget from a JSON definition." {
+		t.Errorf("description = %q", create.Description)
+	}
+	if len(create.Parameters) != 2 || create.Parameters[0].Name != "--file" || create.Parameters[0].Shorthand != "-f" {
+		t.Errorf("parameters = %+v", create.Parameters)
+	}
+	if len(create.Examples) != 1 || !strings.HasSuffix(create.Examples[0], "\ntool create -f widget.json --dry-run") {
+		t.Errorf("examples = %q", create.Examples)
+	}
+	if strings.Contains(create.RawHelpText, `\fB`) {
+		t.Error("raw help text should be rendered, not roff source")
+	}
+	if len(result.Groups) != 1 || result.Groups[0].Name != "tool" {
+		t.Errorf("groups = %+v", result.Groups)
+	}
+}
+
+func TestReadManFile(t *testing.T) {
+	// A gzipped page in man1 and a page that includes it with .so
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "man1"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	src, err := os.ReadFile(filepath.Join("testdata", "man", "tool-create.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(filepath.Join(dir, "man1", "tool-create.1.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := gzip.NewWriter(f)
+	if _, err := zw.Write(src); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	alias := filepath.Join(dir, "man1", "tool-new.1")
+	if err := os.WriteFile(alias, []byte(".so man1/tool-create.1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readManFile(alias)
+	if err != nil {
+		t.Fatalf("readManFile: %v", err)
+	}
+	if got != string(src) {
+		t.Errorf("readManFile returned %q", got)
+	}
+}
+
+func TestParseFishCompletions(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "completion", "tool.fish"))
+	if err != nil {
+		t.Fatalf("reading testdata: %v", err)
+	}
+	tree := parseFishCompletions(string(data), "tool")
+
+	root := tree[""]
+	var subs []string
+	for _, s := range root.Subcommands {
+		subs = append(subs, s.Name)
+	}
+	if strings.Join(subs, ",") != "create,delete,config" {
+		t.Errorf("root subcommands = %v, want [create delete config]", subs)
+	}
+	wantRoot := []completionFlag{
+		{Name: "--verbose", Shorthand: "-v", Description: "Print each request as it is made"},
+		{Name: "--config", Shorthand: "-c", Description: "Read configuration from file"},
+		{Name: "--color", Description: "Colorize output", Values: []string{"always", "never", "auto"}},
+	}
+	if len(root.Flags) != len(wantRoot) {
+		t.Fatalf("root flags = %+v", root.Flags)
+	}
+	for i, f := range root.Flags {
+		if f.Name != wantRoot[i].Name || f.Shorthand != wantRoot[i].Shorthand || f.Description != wantRoot[i].Description ||
+			strings.Join(f.Values, ",") != strings.Join(wantRoot[i].Values, ",") {
+			t.Errorf("root flag %d = %+v, want %+v", i, f, wantRoot[i])
+		}
+	}
+
+	create := tree["create"]
+	if create == nil || create.Description != "Create a widget" {
+		t.Fatalf("create = %+v", create)
+	}
+	flags := map[string]completionFlag{}
+	for _, f := range create.Flags {
+		flags[f.Name] = f
+	}
+	if f := flags["--format"]; strings.Join(f.Values, ",") != "json,yaml" || f.Description != "Definition format" {
+		t.Errorf("--format = %+v, want values json,yaml merged across lines", f)
+	}
+	if _, ok := flags["--dry-run"]; !ok {
+		t.Error("--dry-run should apply to create")
+	}
+	if d := tree["delete"]; d == nil || len(d.Flags) != 1 || len(d.Subcommands) != 0 {
+		t.Errorf("delete = %+v, want --dry-run only (computed arguments are not subcommands)", d)
+	}
+	if c := tree["config"]; c == nil || len(c.Subcommands) != 2 {
+		t.Errorf("config = %+v, want subcommands get and set", c)
+	}
+	if s := tree["config set"]; s == nil || len(s.Flags) != 1 || s.Flags[0].Name != "-global" {
+		t.Errorf("config set = %+v, want old-style -global", s)
+	}
+}
+
+// fakeCobra is a CLI answering --help and cobra's __complete protocol.
+const fakeCobra = `#!/bin/sh
+if [ "$1" = "__complete" ]; then
+  shift
+  case "$(printf '%s|' "$@")" in
+    "|") printf 'create\tCreate a widget\n:4\n' ;;
+    "-|") printf -- '--verbose\tVerbose output\n-v\tVerbose output\n--output\tOutput format\n-o\tOutput format\n:4\n' ;;
+    "--output=|") printf 'json\nyaml\n:4\n' ;;
+    "create|") printf 'small\nlarge\n:4\n' ;;
+    "create|-|") printf -- '--size\tWidget size\n--file\tDefinition file\n:4\n' ;;
+    "create|--size=|") printf -- '--size=small\n--size=large\n:4\n' ;;
+    "create|--file=|") printf 'json\n:8\n' ;;
+    *) printf ':0\n' ;;
+  esac
+  exit 0
+fi
+case "$1" in
+  create) printf 'Create a widget\n\nUsage:\n  tool create [size] [flags]\n\nFlags:\n      --size string   Widget size\n' ;;
+  *) printf 'A widget tool\n\nUsage:\n  tool [command]\n\nFlags:\n  -v, --verbose   Verbose output\n' ;;
+esac
+`
+
+func writeFakeCobra(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("needs /bin/sh")
+	}
+	bin := filepath.Join(t.TempDir(), "tool")
+	if err := os.WriteFile(bin, []byte(fakeCobra), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return bin
+}
+
+// chdirTemp runs the test in an empty directory, where Fetch keeps its
+// crawl cache.
+func chdirTemp(t *testing.T) {
+	t.Helper()
+	orig, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(orig) })
+}
+
+func TestFetch_CobraCompletion(t *testing.T) {
+	chdirTemp(t)
+	p := New()
+	source := instructions.SpecSource{Type: "cli", Binary: writeFakeCobra(t), Completion: "auto"}
+	raw, err := p.Fetch(source)
+	if err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+	result, err := p.Parse(raw, source)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if w := p.Validate(result); len(w) != 0 {
+		t.Errorf("unexpected warnings: %v", w)
+	}
+
+	// create's arguments (small, large) must not be crawled as subcommands
+	if len(result.Operations) != 2 {
+		t.Fatalf("got %d operations, want 2", len(result.Operations))
+	}
+	bin := source.Binary
+	params := map[string]string{}
+	for _, op := range result.Operations {
+		for _, prm := range op.Parameters {
+			params[strings.TrimPrefix(op.Name, bin)+" "+prm.Name] = prm.Shorthand + " " + prm.Type
+		}
+	}
+	want := map[string]string{
+		" --verbose":     "-v ",
+		" --output":      "-o string(json|yaml)",
+		" create --size": " string(small|large)",
+		" create --file": " ",
+	}
+	for k, v := range want {
+		if params[k] != v {
+			t.Errorf("%s = %q, want %q", k, params[k], v)
+		}
+	}
+}
+
+func TestFetch_CompletionFallback(t *testing.T) {
+	chdirTemp(t)
+	p := New()
+	source := instructions.SpecSource{Type: "cli", Binary: writeFakeCobra(t), Completion: filepath.Join(t.TempDir(), "missing.fish")}
+	raw, err := p.Fetch(source)
+	if err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+	if strings.Contains(string(raw), "=== COMPLETION ===") {
+		t.Error("completion should not be used when unavailable")
+	}
+	result, err := p.Parse(raw, source)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if len(result.Warnings) == 0 || !strings.Contains(result.Warnings[0].Message, "falling back to help output") {
+		t.Errorf("warnings = %v, want a fallback warning", result.Warnings)
+	}
+	if len(result.Operations) != 1 || len(result.Operations[0].Parameters) != 1 {
+		t.Errorf("help crawl should still run: %+v", result.Operations)
+	}
+}
+
+func TestParseUsage(t *testing.T) {
+	tests := []struct {
+		line         string
+		pathLen      int
+		wantArgs     string // name:required:variadic, comma-separated
+		wantRequired string
+	}{
+		{"foo create <name> [--region R] [files...]", 2, "name:true:false,files:false:true", ""},
+		{"foo create [flags]", 2, "", ""},
+		{"/usr/bin/foo cp SRC... DEST", 2, "SRC:true:true,DEST:true:false", ""},
+		{"foo deploy --env ENV [-v] <service>", 2, "service:true:false", "--env"},
+		{"foo {start|stop|restart} [<unit>...]", 1, "start|stop|restart:true:false,unit:false:true", ""},
+		{"fetch [-qv] [-o file] URL ...", 1, "URL:true:true", ""},
+		{"git remote add [-t <branch>] <name> <url>", 2, "name:true:false,url:true:false", ""},
+		{"foo run [--] [cmd [args...]]", 2, "cmd:false:false,args:false:true", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			args, required := parseUsage(tt.line, tt.pathLen, nil)
+			var got []string
+			for _, a := range args {
+				got = append(got, fmt.Sprintf("%s:%v:%v", a.name, a.required, a.variadic))
+			}
+			if strings.Join(got, ",") != tt.wantArgs {
+				t.Errorf("args = %s, want %s", strings.Join(got, ","), tt.wantArgs)
+			}
+			if strings.Join(required, ",") != tt.wantRequired {
+				t.Errorf("required flags = %v, want %s", required, tt.wantRequired)
+			}
+		})
+	}
+}
+
+func TestParse_UsageAndExamples(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "usage-help.txt"))
+	if err != nil {
+		t.Fatalf("reading testdata: %v", err)
+	}
+	p := New()
+	input := "=== COMMAND: widgets create ===\n" + string(data) + "\n=== END ===\n"
+	result, err := p.Parse([]byte(input), instructions.SpecSource{Type: "cli", Binary: "widgets"})
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	op := result.Operations[0]
+
+	if op.Description != "Create a widget from a name and optional definition files." {
+		t.Errorf("description = %q", op.Description)
+	}
+	params := map[string]ir.Parameter{}
+	for _, prm := range op.Parameters {
+		params[prm.Name] = prm
+	}
+	if a := params["name"]; a.In != "argument" || !a.Required || a.Type != "string" || a.Description != "Name of the widget" {
+		t.Errorf("name = %+v", a)
+	}
+	if a := params["files"]; a.In != "argument" || a.Required || a.Type != "[]string" {
+		t.Errorf("files = %+v", a)
+	}
+	if f := params["--region"]; !f.Required || f.Description != "Region to create the widget in" {
+		t.Errorf("--region = %+v, want required with the marker removed", f)
+	}
+	if f := params["--from-template"]; f.Required {
+		t.Error("--from-template is only required by the second usage form")
+	}
+
+	want := []string{
+		"# Create a widget in us-east\nwidgets create web --region us-east",
+		"widgets create api --region eu files/a.json \\\n  files/b.json",
+		"widgets create db --region eu",
+	}
+	if strings.Join(op.Examples, "|") != strings.Join(want, "|") {
+		t.Errorf("examples = %q, want %q", op.Examples, want)
+	}
+}
+
+func TestHelpDialects(t *testing.T) {
+	tests := []struct {
+		fixture     string
+		command     string
+		dialect     string
+		description string
+		subcommands []string
+		params      map[string]string // name -> "type default required"
+		metavars    map[string]string // name -> description naming the value
+		examples    []string
+	}{
+		{
+			fixture:     "click",
+			command:     "widgets",
+			dialect:     "sectioned",
+			description: "Manage widgets in the widget registry.",
+			subcommands: []string{"create", "delete", "list"},
+			params: map[string]string{
+				"--format":  "string(json|yaml) json false",
+				"--account": "string  true",
+				"--retries": "int 3 false",
+				"--color":   "bool  false",
+			},
+		},
+		{
+			fixture:     "argparse",
+			command:     "widgets create",
+			dialect:     "sectioned",
+			description: "Create a widget from a name and optional definition files.",
+			params: map[string]string{
+				"name":     "string  true",
+				"files":    "[]string  false",
+				"--region": "string us-east false",
+				"--size":   "string(small|large)  false",
+			},
+			metavars: map[string]string{"--region": "region to create the widget in (value: REGION)"},
+		},
+		{
+			fixture:     "clap",
+			command:     "widgets",
+			dialect:     "sectioned",
+			description: "Manage widgets in the widget registry",
+			subcommands: []string{"create", "delete"},
+			params: map[string]string{
+				"--output":  "string(json|yaml|table) json false",
+				"--account": "string  false",
+				"--verbose": "bool  false",
+			},
+			metavars: map[string]string{"--account": "Account to act on [env: WIDGETS_ACCOUNT=] (value: ID)"},
+		},
+		{
+			fixture:     "commander",
+			command:     "widgets create",
+			dialect:     "sectioned",
+			description: "create a widget from a name and optional definition files",
+			params: map[string]string{
+				"name":     "string  true",
+				"files":    "[]string  false",
+				"--region": "string us-east false",
+				"--size":   "string(small|large) small false",
+			},
+			metavars: map[string]string{"--region": "region to create the widget in (value: region)"},
+		},
+		{
+			fixture:     "yargs",
+			command:     "widgets",
+			dialect:     "yargs",
+			description: "Manage widgets in the widget registry",
+			subcommands: []string{"create", "delete"},
+			params: map[string]string{
+				"--output":  "string(json|yaml) json false",
+				"--account": "string  true",
+			},
+			examples: []string{"# Create a widget, printing YAML\nwidgets create web --output yaml"},
+		},
+		{
+			fixture: "goflag",
+			command: "widgets",
+			dialect: "sectioned",
+			params: map[string]string{
+				"-output":  "string json false",
+				"-retries": "int 3 false",
+				"-v":       "bool  false",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", tt.fixture+"-help.txt"))
+			if err != nil {
+				t.Fatalf("reading testdata: %v", err)
+			}
+			input := "=== COMMAND: " + tt.command + " ===\n" + string(data) + "\n=== END ===\n"
+			result, err := New().Parse([]byte(input), instructions.SpecSource{Type: "cli", Binary: "widgets"})
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if got := result.Metadata["help-format"]; got != tt.dialect {
+				t.Errorf("help-format = %q, want %q", got, tt.dialect)
+			}
+			op := result.Operations[0]
+			if op.Description != tt.description {
+				t.Errorf("description = %q, want %q", op.Description, tt.description)
+			}
+
+			parsed := parseHelpOutput(string(data))
+			for _, sub := range tt.subcommands {
+				if !slices.Contains(parsed.subcommands, sub) {
+					t.Errorf("subcommands = %v, missing %q", parsed.subcommands, sub)
+				}
+			}
+
+			params := map[string]ir.Parameter{}
+			for _, prm := range op.Parameters {
+				params[prm.Name] = prm
+			}
+			for name, want := range tt.params {
+				prm, ok := params[name]
+				if !ok {
+					t.Errorf("missing parameter %s", name)
+					continue
+				}
+				if got := fmt.Sprintf("%s %s %t", prm.Type, prm.Default, prm.Required); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+			for name, want := range tt.metavars {
+				if got := params[name].Description; got != want {
+					t.Errorf("%s description = %q, want %q", name, got, want)
+				}
+			}
+			if strings.Join(op.Examples, "|") != strings.Join(tt.examples, "|") {
+				t.Errorf("examples = %q, want %q", op.Examples, tt.examples)
+			}
+		})
+	}
+}
+
+func TestHelpDialectFor(t *testing.T) {
+	d, err := helpDialectFor("sectioned", "Usage: tool [flags]")
+	if err != nil || d.name != "sectioned" {
+		t.Errorf("helpDialectFor(sectioned) = %q, %v", d.name, err)
+	}
+	if d, _ := helpDialectFor("auto", "Usage: tool [flags]"); d.name != "cobra" {
+		t.Errorf("unrecognized help parsed as %q, want cobra", d.name)
+	}
+	if _, err := helpDialectFor("docopt", ""); err == nil {
+		t.Error("expected an error for an unknown help-format")
+	}
+}
+
+func TestCrawlTree(t *testing.T) {
+	tree := map[string][]string{"": {"a", "b", "c"}, "a": {"x", "y"}, "c": {"z"}, "a x": {"deep"}}
+	visit := func(path []string) (crawlResult, []string, error) {
+		key := strings.Join(path, " ")
+		// finish out of order
+		time.Sleep(time.Duration(len(tree[key])) * time.Millisecond)
+		if key == "c" {
+			return crawlResult{}, nil, fmt.Errorf("boom")
+		}
+		return crawlResult{helpText: "help for " + key}, tree[key], nil
+	}
+
+	results, err := crawlTree(3, 2, map[string]bool{"b": true}, visit)
+	if err != nil {
+		t.Fatalf("crawlTree: %v", err)
+	}
+	var got []string
+	for _, r := range results {
+		got = append(got, strings.Join(r.commandPath, " ")+"="+r.helpText)
+	}
+	want := []string{"=help for ", "a=help for a", "c=(error: boom)", "a x=help for a x", "a y=help for a y"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("results = %q, want %q", got, want)
+	}
+
+	_, err = crawlTree(3, 1, nil, func(path []string) (crawlResult, []string, error) {
+		return crawlResult{}, nil, fmt.Errorf("no such command")
+	})
+	if err == nil {
+		t.Error("an error visiting the root should fail the crawl")
+	}
+}
+
+func TestRunner_Sandbox(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs /bin/sh")
+	}
+	t.Setenv("SECRET_TOKEN", "hunter2")
+	t.Setenv("PASSED_THROUGH", "ok")
+	r, err := newRunner(instructions.SpecSource{Env: []string{"PASSED_THROUGH", "EXTRA=set"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.close()
+
+	out, err := r.run("sh", "-c", `echo "$(pwd)|$HOME|$SECRET_TOKEN|$PASSED_THROUGH|$EXTRA"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, _ := filepath.EvalSymlinks(r.dir)
+	pwd, _, _ := strings.Cut(strings.TrimSpace(out), "|")
+	if pwd, _ = filepath.EvalSymlinks(pwd); pwd != dir {
+		t.Errorf("working directory = %s, want the sandbox %s", pwd, dir)
+	}
+	if want := "|" + r.dir + "||ok|set"; !strings.HasSuffix(strings.TrimSpace(out), want) {
+		t.Errorf("environment = %q, want suffix %q", strings.TrimSpace(out), want)
+	}
+
+	slow, err := newRunner(instructions.SpecSource{Timeout: "100ms"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer slow.close()
+	if _, err := slow.run("sh", "-c", "exec sleep 5"); err == nil || !strings.Contains(err.Error(), "timed out after 100ms") {
+		t.Errorf("err = %v, want a timeout", err)
+	}
+
+	if _, err := newRunner(instructions.SpecSource{Timeout: "soon"}); err == nil {
+		t.Error("an invalid timeout should be an error")
+	}
+}
+
+func TestFetch_RootHelpFails(t *testing.T) {
+	chdirTemp(t)
+	if runtime.GOOS == "windows" {
+		t.Skip("needs /bin/sh")
+	}
+	bin := filepath.Join(t.TempDir(), "broken")
+	if err := os.WriteFile(bin, []byte("#!/bin/sh\nexit 1\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	_, err := New().Fetch(instructions.SpecSource{Type: "cli", Binary: bin})
+	if err == nil || !strings.Contains(err.Error(), "--help") {
+		t.Errorf("err = %v, want the root help failure", err)
+	}
+}
+
+func TestFetch_Cache(t *testing.T) {
+	chdirTemp(t)
+	bin := writeFakeCobra(t)
+	// count the binary's runs outside the sandbox
+	counter := filepath.Join(t.TempDir(), "runs")
+	script, _ := os.ReadFile(bin)
+	script = []byte(strings.Replace(string(script), "#!/bin/sh\n", "#!/bin/sh\necho run >> \"$COUNTER\"\n", 1))
+	if err := os.WriteFile(bin, script, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	runs := func() int {
+		data, _ := os.ReadFile(counter)
+		return strings.Count(string(data), "run")
+	}
+
+	p := New()
+	source := instructions.SpecSource{Type: "cli", Binary: bin, Env: []string{"COUNTER=" + counter}}
+	first, err := p.Fetch(source)
+	if err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+	crawled := runs()
+	second, err := p.Fetch(source)
+	if err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+	if runs() != crawled || string(second) != string(first) {
+		t.Errorf("second fetch ran the binary %d times, want a cache hit", runs()-crawled)
+	}
+
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(bin, later, later); err != nil {
+		t.Fatal(err)
+	}
+	before := runs()
+	if _, err := p.Fetch(source); err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+	if runs() <= before {
+		t.Error("a modified binary should be crawled again")
+	}
+
+	source.NoCache = true
+	before = runs()
+	if _, err := p.Fetch(source); err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+	if runs() <= before {
+		t.Error("no-cache should crawl again")
+	}
+}
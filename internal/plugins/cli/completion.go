@@ -0,0 +1,485 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Shell completion support: cobra's hidden __complete command and fish
+// completion scripts enumerate a CLI's commands, flags and flag values
+// exactly, where help parsing has to guess.
+
+// completion is what a completion source knows about one command.
+type completion struct {
+	Description string           `json:"description,omitempty"`
+	Subcommands []completionItem `json:"subcommands,omitempty"`
+	Flags       []completionFlag `json:"flags,omitempty"`
+}
+
+type completionItem struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type completionFlag struct {
+	Name        string   `json:"name"` // --long, or -s for short-only flags
+	Shorthand   string   `json:"shorthand,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Values      []string `json:"values,omitempty"` // allowed values, when the source enumerates them
+}
+
+// completer returns what a completion source knows about the command at
+// path (relative to the binary), or nil if it knows nothing.
+type completer func(path []string) *completion
+
+// newCompleter sets up the completion source named by mode: cobra, fish, a
+// path to a fish script, or auto to use whichever the binary supports.
+func newCompleter(r *runner, binary, mode string) (completer, error) {
+	switch {
+	case mode == "cobra":
+		return cobraCompleter(r, binary)
+	case mode == "fish":
+		return fishCompleter(binary, "")
+	case strings.HasSuffix(mode, ".fish"):
+		return fishCompleter(binary, mode)
+	case mode == "auto":
+		if c, err := cobraCompleter(r, binary); err == nil {
+			return c, nil
+		}
+		if c, err := fishCompleter(binary, ""); err == nil {
+			return c, nil
+		}
+		return nil, fmt.Errorf("%s has no cobra __complete command or fish completions", binary)
+	}
+	return nil, fmt.Errorf("unknown completion mode %q (want auto, cobra, fish, or a .fish file)", mode)
+}
+
+// cobra directives that make the candidates something other than values.
+const (
+	cobraDirectiveFilterFileExt = 8
+	cobraDirectiveFilterDirs    = 16
+)
+
+// cobraDirectiveRe matches the directive line ending __complete output.
+var cobraDirectiveRe = regexp.MustCompile(`(?m)^:(\d+)$`)
+
+// cobraComplete runs binary __complete args... and returns the candidates
+// and directive.
+func cobraComplete(r *runner, binary string, args []string) ([]completionItem, int, error) {
+	out, err := r.run(binary, append([]string{"__complete"}, args...)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	loc := cobraDirectiveRe.FindStringSubmatchIndex(out)
+	if loc == nil {
+		return nil, 0, fmt.Errorf("no __complete directive in output")
+	}
+	directive, _ := strconv.Atoi(out[loc[2]:loc[3]])
+	var items []completionItem
+	for _, line := range strings.Split(out[:loc[0]], "\n") {
+		if line == "" {
+			continue
+		}
+		name, desc, _ := strings.Cut(line, "\t")
+		items = append(items, completionItem{Name: name, Description: strings.TrimSpace(desc)})
+	}
+	return items, directive, nil
+}
+
+// cobraCompleter completes through a cobra binary's __complete command.
+func cobraCompleter(r *runner, binary string) (completer, error) {
+	if _, _, err := cobraComplete(r, binary, []string{""}); err != nil {
+		return nil, fmt.Errorf("%s has no cobra __complete command: %w", binary, err)
+	}
+	// Parents list their subcommands' descriptions; commands are completed
+	// concurrently
+	var mu sync.Mutex
+	descriptions := make(map[string]string)
+	return func(path []string) *completion {
+		args := append(append([]string(nil), path...), "")
+		subs, _, err := cobraComplete(r, binary, args)
+		if err != nil {
+			return nil
+		}
+		mu.Lock()
+		c := &completion{Description: descriptions[strings.Join(path, " ")]}
+		for _, s := range subs {
+			if strings.HasPrefix(s.Name, "-") {
+				continue
+			}
+			c.Subcommands = append(c.Subcommands, s)
+			descriptions[strings.Join(append(append([]string(nil), path...), s.Name), " ")] = s.Description
+		}
+		mu.Unlock()
+
+		// "-" lists every flag, each long name followed by its shorthand
+		args[len(args)-1] = "-"
+		flags, _, err := cobraComplete(r, binary, args)
+		if err != nil {
+			return c
+		}
+		for _, f := range flags {
+			switch {
+			case strings.HasPrefix(f.Name, "--"):
+				c.Flags = append(c.Flags, completionFlag{Name: f.Name, Description: f.Description})
+			case strings.HasPrefix(f.Name, "-"):
+				if n := len(c.Flags); n > 0 && c.Flags[n-1].Shorthand == "" && c.Flags[n-1].Description == f.Description {
+					c.Flags[n-1].Shorthand = f.Name
+				} else {
+					c.Flags = append(c.Flags, completionFlag{Name: f.Name, Description: f.Description})
+				}
+			}
+		}
+
+		// Flags registered with a completion function enumerate their values
+		for i, f := range c.Flags {
+			args[len(args)-1] = f.Name + "="
+			values, directive, err := cobraComplete(r, binary, args)
+			if err != nil || directive&(cobraDirectiveFilterFileExt|cobraDirectiveFilterDirs) != 0 {
+				continue
+			}
+			for _, v := range values {
+				if v.Name = strings.TrimPrefix(v.Name, f.Name+"="); v.Name != "" {
+					c.Flags[i].Values = append(c.Flags[i].Values, v.Name)
+				}
+			}
+		}
+		return c
+	}, nil
+}
+
+// fishCompletionDirs are where fish looks for completion scripts, most
+// specific first.
+func fishCompletionDirs() []string {
+	var dirs []string
+	if config := os.Getenv("XDG_CONFIG_HOME"); config != "" {
+		dirs = append(dirs, filepath.Join(config, "fish", "completions"))
+	} else if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".config", "fish", "completions"))
+	}
+	for _, prefix := range []string{"/usr/local/share", "/opt/homebrew/share", "/usr/share"} {
+		dirs = append(dirs,
+			filepath.Join(prefix, "fish", "vendor_completions.d"),
+			filepath.Join(prefix, "fish", "completions"))
+	}
+	return dirs
+}
+
+// fishCompleter completes from a fish completion script: the one at script,
+// or the binary's script in fish's completion directories.
+func fishCompleter(binary, script string) (completer, error) {
+	if script == "" {
+		for _, dir := range fishCompletionDirs() {
+			candidate := filepath.Join(dir, filepath.Base(binary)+".fish")
+			if _, err := os.Stat(candidate); err == nil {
+				script = candidate
+				break
+			}
+		}
+		if script == "" {
+			return nil, fmt.Errorf("no fish completion script for %s", binary)
+		}
+	}
+	data, err := os.ReadFile(script)
+	if err != nil {
+		return nil, err
+	}
+	tree := parseFishCompletions(string(data), filepath.Base(binary))
+	if len(tree) == 1 && len(tree[""].Flags) == 0 && len(tree[""].Subcommands) == 0 {
+		return nil, fmt.Errorf("%s has no completions for %s", script, binary)
+	}
+	return func(path []string) *completion {
+		return tree[strings.Join(path, " ")]
+	}, nil
+}
+
+// parseFishCompletions reads the complete commands for binary in a fish
+// script into completions keyed by space-separated command path. Commands
+// are placed by their conditions: __fish_use_subcommand (or "not
+// __fish_seen_subcommand_from ...") offers subcommands, and
+// __fish_seen_subcommand_from x places an entry under x.
+func parseFishCompletions(script, binary string) map[string]*completion {
+	tree := map[string]*completion{"": {}}
+	node := func(path string) *completion {
+		if tree[path] == nil {
+			tree[path] = &completion{}
+		}
+		return tree[path]
+	}
+
+	for _, line := range fishLines(script) {
+		args := fishWords(line)
+		if len(args) == 0 || args[0] != "complete" {
+			continue
+		}
+		e := parseFishComplete(args[1:])
+		if e.command != binary || e.erase {
+			continue
+		}
+		paths, offersSubcommands := fishCondition(e.condition)
+
+		var values []string
+		for _, v := range fishWords(e.arguments) {
+			// command substitutions and variables are computed at completion
+			// time; there is nothing to enumerate
+			if strings.ContainsAny(v, "()$") {
+				values = nil
+				break
+			}
+			v, _, _ = strings.Cut(v, "\t")
+			values = append(values, v)
+		}
+
+		for _, path := range paths {
+			n := node(path)
+			switch {
+			case e.long != "" || e.short != "" || e.old != "":
+				f := completionFlag{Description: e.description, Values: values}
+				switch {
+				case e.long != "":
+					f.Name, f.Shorthand = "--"+e.long, fishShort(e.short)
+				case e.old != "":
+					f.Name, f.Shorthand = "-"+e.old, fishShort(e.short)
+				default:
+					f.Name = "-" + e.short
+				}
+				n.addFlag(f)
+			case offersSubcommands:
+				for _, v := range values {
+					n.addSubcommand(completionItem{Name: v, Description: e.description})
+					sub := node(strings.TrimSpace(path + " " + v))
+					if sub.Description == "" {
+						sub.Description = e.description
+					}
+				}
+			}
+		}
+	}
+	return tree
+}
+
+func fishShort(short string) string {
+	if short == "" {
+		return ""
+	}
+	return "-" + short
+}
+
+func (c *completion) addFlag(f completionFlag) {
+	for i := range c.Flags {
+		if c.Flags[i].Name == f.Name {
+			// values are often listed one complete command at a time
+			c.Flags[i].Values = append(c.Flags[i].Values, f.Values...)
+			if c.Flags[i].Description == "" {
+				c.Flags[i].Description = f.Description
+			}
+			return
+		}
+	}
+	c.Flags = append(c.Flags, f)
+}
+
+func (c *completion) addSubcommand(item completionItem) {
+	for _, s := range c.Subcommands {
+		if s.Name == item.Name {
+			return
+		}
+	}
+	c.Subcommands = append(c.Subcommands, item)
+}
+
+// fishEntry is one complete command.
+type fishEntry struct {
+	command, condition, arguments, description string
+	long, short, old                           string
+	erase                                      bool
+}
+
+// fishOptionArgs are complete's options that take an argument, by short and
+// long name.
+var fishOptionArgs = map[string]string{
+	"c": "command", "n": "condition", "a": "arguments", "d": "description",
+	"l": "long-option", "s": "short-option", "o": "old-option", "w": "wraps", "p": "path",
+}
+
+func fishLongTakesArg(name string) bool {
+	for _, long := range fishOptionArgs {
+		if long == name {
+			return true
+		}
+	}
+	return false
+}
+
+func parseFishComplete(args []string) fishEntry {
+	var e fishEntry
+	set := func(opt, value string) {
+		switch opt {
+		case "command":
+			e.command = value
+		case "condition":
+			e.condition = value
+		case "arguments":
+			e.arguments = value
+		case "description":
+			e.description = value
+		case "long-option":
+			e.long = value
+		case "short-option":
+			e.short = value
+		case "old-option":
+			e.old = value
+		case "erase":
+			e.erase = true
+		}
+	}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case strings.HasPrefix(arg, "--"):
+			name, value, hasValue := strings.Cut(arg[2:], "=")
+			if fishLongTakesArg(name) && !hasValue && i+1 < len(args) {
+				i++
+				value = args[i]
+			}
+			set(name, value)
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			// clustered short options, e.g. -xa 'json yaml' or -lformat
+			for j := 1; j < len(arg); j++ {
+				long, takesArg := fishOptionArgs[arg[j:j+1]]
+				if !takesArg {
+					if arg[j] == 'e' {
+						set("erase", "")
+					}
+					continue
+				}
+				value := arg[j+1:]
+				if value == "" && i+1 < len(args) {
+					i++
+					value = args[i]
+				}
+				set(long, value)
+				break
+			}
+		case e.command == "":
+			// complete tool -l flag: the command may be given positionally
+			e.command = arg
+		}
+	}
+	return e
+}
+
+// fishClauseRe separates the clauses of a condition.
+var fishClauseRe = regexp.MustCompile(`;|&&|\band\b`)
+
+// fishCondition reads the command paths a condition applies to, and
+// whether it is the condition for offering subcommands there.
+func fishCondition(condition string) (paths []string, offersSubcommands bool) {
+	paths = []string{""}
+	if strings.TrimSpace(condition) == "" {
+		return paths, false
+	}
+	for _, clause := range fishClauseRe.Split(condition, -1) {
+		words := fishWords(clause)
+		negated := len(words) > 0 && words[0] == "not"
+		if negated {
+			words = words[1:]
+		}
+		if len(words) == 0 {
+			continue
+		}
+		fn, rest := words[0], words[1:]
+		switch {
+		case fn == "__fish_use_subcommand":
+			offersSubcommands = true
+		case fn == "__fish_seen_subcommand_from" && negated:
+			offersSubcommands = true
+		case fn == "__fish_seen_subcommand_from" && len(rest) > 0:
+			// any of the subcommands: the entry applies under each
+			var next []string
+			for _, p := range paths {
+				for _, sub := range rest {
+					next = append(next, strings.TrimSpace(p+" "+sub))
+				}
+			}
+			paths = next
+		case (strings.HasSuffix(fn, "_using_command") || strings.HasSuffix(fn, "_using_subcommand")) && !negated:
+			// generated helpers name the whole command path
+			for i := range paths {
+				paths[i] = strings.TrimSpace(paths[i] + " " + strings.Join(rest, " "))
+			}
+		}
+	}
+	return paths, offersSubcommands
+}
+
+// fishLines splits a script into logical lines, joining backslash
+// continuations and dropping comments.
+func fishLines(script string) []string {
+	var lines []string
+	var cur strings.Builder
+	for _, line := range strings.Split(strings.ReplaceAll(script, "\r\n", "\n"), "\n") {
+		if strings.HasSuffix(line, `\`) {
+			cur.WriteString(line[:len(line)-1] + " ")
+			continue
+		}
+		cur.WriteString(line)
+		if l := strings.TrimSpace(cur.String()); l != "" && !strings.HasPrefix(l, "#") {
+			lines = append(lines, l)
+		}
+		cur.Reset()
+	}
+	return lines
+}
+
+// fishWords splits a command line into words, honoring fish quoting. A #
+// starting a word comments out the rest of the line.
+func fishWords(line string) []string {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case c == '#' && !inWord:
+			return words
+		case c == '\'' || c == '"':
+			inWord = true
+			for i++; i < len(line) && line[i] != c; i++ {
+				if line[i] == '\\' && i+1 < len(line) && (line[i+1] == c || line[i+1] == '\\') {
+					i++
+				}
+				word.WriteByte(line[i])
+			}
+		case c == '\\' && i+1 < len(line):
+			inWord = true
+			i++
+			switch line[i] {
+			case 't':
+				word.WriteByte('\t')
+			case 'n':
+				word.WriteByte('\n')
+			default:
+				word.WriteByte(line[i])
+			}
+		default:
+			inWord = true
+			word.WriteByte(c)
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words
+}
@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/roberthamel/skill-compiler/internal/cache"
+	"github.com/roberthamel/skill-compiler/internal/instructions"
+)
+
+// Crawling runs the binary once per command, so it runs several at a time,
+// each in a sandbox, and caches the result until the binary changes.
+
+const (
+	defaultTimeout     = 5 * time.Second
+	defaultConcurrency = 4
+)
+
+// sandboxEnv are the variables crawled commands inherit. Everything else,
+// credentials included, is left out.
+var sandboxEnv = []string{"PATH", "LANG", "LC_ALL", "LC_CTYPE", "TZ", "MANPATH", "SYSTEMROOT"}
+
+// runner runs crawled commands with a timeout, in an empty working
+// directory with a scrubbed environment whose HOME is that directory, so
+// a CLI finds no config or credentials to act on.
+type runner struct {
+	timeout time.Duration
+	dir     string
+	env     []string
+}
+
+// newRunner sets up a sandbox for the source's crawl. Variables named in
+// the source's env are passed through (NAME) or set (NAME=value).
+func newRunner(source instructions.SpecSource) (*runner, error) {
+	r := &runner{timeout: defaultTimeout}
+	if source.Timeout != "" {
+		d, err := time.ParseDuration(source.Timeout)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid timeout %q: want a duration like 10s", source.Timeout)
+		}
+		r.timeout = d
+	}
+
+	dir, err := os.MkdirTemp("", "sc-cli-")
+	if err != nil {
+		return nil, fmt.Errorf("creating sandbox directory: %w", err)
+	}
+	r.dir = dir
+
+	set := func(name, value string) {
+		prefix := name + "="
+		for i, kv := range r.env {
+			if strings.HasPrefix(kv, prefix) {
+				r.env[i] = prefix + value
+				return
+			}
+		}
+		r.env = append(r.env, prefix+value)
+	}
+	for _, name := range sandboxEnv {
+		if value, ok := os.LookupEnv(name); ok {
+			set(name, value)
+		}
+	}
+	for _, name := range []string{"HOME", "USERPROFILE", "XDG_CONFIG_HOME", "XDG_CACHE_HOME", "XDG_DATA_HOME", "TMPDIR"} {
+		set(name, dir)
+	}
+	// plain, uncolored output
+	set("TERM", "dumb")
+	set("NO_COLOR", "1")
+	for _, e := range source.Env {
+		if name, value, ok := strings.Cut(e, "="); ok {
+			set(name, value)
+		} else if value, ok := os.LookupEnv(e); ok {
+			set(e, value)
+		}
+	}
+	return r, nil
+}
+
+// close removes the sandbox directory.
+func (r *runner) close() {
+	_ = os.RemoveAll(r.dir)
+}
+
+func (r *runner) run(binary string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Dir = r.dir
+	cmd.Env = r.env
+	// don't wait on children that outlive a killed command
+	cmd.WaitDelay = time.Second
+	out, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("command timed out after %s", r.timeout)
+	}
+	// Many CLIs return non-zero for --help; treat output as valid if we got output
+	if len(out) > 0 {
+		return string(out), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// crawlTree visits a command tree breadth first, up to maxDepth below the
+// root, running up to workers visits at a time. visit returns a command's
+// result and subcommands; an error visiting the root fails the crawl, and
+// elsewhere is recorded in the command's help text. Results come back in
+// breadth-first order however the visits interleave.
+func crawlTree(workers, maxDepth int, exclude map[string]bool, visit func(path []string) (crawlResult, []string, error)) ([]crawlResult, error) {
+	type visited struct {
+		result   crawlResult
+		children []string
+		err      error
+	}
+
+	var results []crawlResult
+	seen := map[string]bool{"": true}
+	level := [][]string{nil}
+	for depth := 0; len(level) > 0; depth++ {
+		out := make([]visited, len(level))
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, workers)
+		for i, path := range level {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				r, children, err := visit(path)
+				out[i] = visited{result: r, children: children, err: err}
+			}()
+		}
+		wg.Wait()
+
+		var next [][]string
+		for i, v := range out {
+			path := level[i]
+			if v.err != nil {
+				if len(path) == 0 {
+					return nil, v.err
+				}
+				results = append(results, crawlResult{
+					commandPath: path,
+					helpText:    fmt.Sprintf("(error: %s)", v.err),
+				})
+				continue
+			}
+			v.result.commandPath = path
+			results = append(results, v.result)
+			if depth >= maxDepth {
+				continue
+			}
+			for _, sub := range v.children {
+				child := append(append([]string(nil), path...), sub)
+				key := strings.Join(child, " ")
+				if exclude[sub] || seen[key] {
+					continue
+				}
+				seen[key] = true
+				next = append(next, child)
+			}
+		}
+		level = next
+	}
+	return results, nil
+}
+
+// crawlCacheKey identifies a crawl of binary: its resolved path,
+// modification time and size, and the options that shape the crawl. It is
+// empty when the binary cannot be identified.
+//
+// The file's identity stands in for its --version output: installing or
+// rebuilding a binary rewrites it, so mtime and size change with every new
+// version, while a cache hit never has to run the binary at all (nor trust
+// that --version exists and is side-effect free).
+func crawlCacheKey(binary string, source instructions.SpecSource) string {
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return ""
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	options, _ := json.Marshal(struct {
+		HelpFlag   string
+		HelpFormat string
+		MaxDepth   int
+		Exclude    []string
+		Completion string
+		Man        bool
+		Env        []string
+	}{source.HelpFlag, source.HelpFormat, source.MaxDepth, source.Exclude, source.Completion, source.Man, source.Env})
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%d\n%d\n%s", path, info.ModTime().UnixNano(), info.Size(), options)
+	return "cli-" + hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// readCrawlCache returns a cached crawl, if there is one for key.
+func readCrawlCache(key string) ([]byte, bool) {
+	if key == "" {
+		return nil, false
+	}
+	data, err := cache.ReadCached(".", key)
+	if err != nil {
+		return nil, false
+	}
+	return []byte(data), true
+}
+
+// writeCrawlCache caches a crawl. Crawls with errors are not cached, so a
+// timeout is retried on the next run.
+func writeCrawlCache(key string, results []crawlResult, data []byte) {
+	if key == "" {
+		return
+	}
+	for _, r := range results {
+		if strings.HasPrefix(r.helpText, "(error: ") {
+			return
+		}
+	}
+	_ = cache.WriteCached(".", key, string(data))
+}
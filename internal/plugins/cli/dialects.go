@@ -0,0 +1,399 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Help output dialects: CLI frameworks lay out help differently. Most
+// (click, argparse, clap, commander and Go's flag package) write titled
+// sections that one parser reads; yargs puts the usage first and pairs
+// examples with descriptions; cobra's layout is the default.
+
+// helpDialect parses the help output of one CLI framework.
+type helpDialect struct {
+	name   string
+	detect func(text string) bool
+	parse  func(text string) parsedHelp
+}
+
+// helpDialects are tried in order; cobra, last, parses anything.
+var helpDialects = []helpDialect{
+	{"sectioned", markerDetector(
+		`^Usage of \S+:`, // Go's flag package
+		`(?m)^\s+-h, --help\s+show this help message and exit`, // argparse
+		`(?m)^\s+--help\s+Show this message and exit\.`,        // click
+		`(?m)^\s+-h, --help\s+Prints? help|^USAGE:\s*$`,        // clap
+		`(?m)^\s+-h, --help\s+display help for command`,        // commander
+	), sectioned(sectionedStyle{})},
+	{"yargs", markerDetector(`(?m)^\s+--help\s+Show help\s+\[boolean\]`), sectioned(sectionedStyle{usageFirst: true, examplePairs: true})},
+	{"cobra", func(string) bool { return true }, parseCobraHelp},
+}
+
+func sectioned(style sectionedStyle) func(string) parsedHelp {
+	return func(text string) parsedHelp { return parseSectionedHelp(text, style) }
+}
+
+// markerDetector recognizes text matching any of the patterns.
+func markerDetector(patterns ...string) func(string) bool {
+	res := make([]*regexp.Regexp, len(patterns))
+	for i, pattern := range patterns {
+		res[i] = regexp.MustCompile(pattern)
+	}
+	return func(text string) bool {
+		for _, re := range res {
+			if re.MatchString(text) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// helpDialectFor returns the dialect named by format, or the one that
+// recognizes text when format is empty or auto.
+func helpDialectFor(format, text string) (helpDialect, error) {
+	for _, d := range helpDialects {
+		if format == "" || format == "auto" {
+			if d.detect(text) {
+				return d, nil
+			}
+		} else if d.name == format {
+			return d, nil
+		}
+	}
+	var names []string
+	for _, d := range helpDialects {
+		names = append(names, d.name)
+	}
+	return helpDialect{}, fmt.Errorf("unknown help-format %q (want auto, %s)", format, strings.Join(names, ", "))
+}
+
+// parseHelpOutput parses help output in whichever dialect it is written in.
+func parseHelpOutput(text string) parsedHelp {
+	d, _ := helpDialectFor("", text)
+	return d.parse(text)
+}
+
+// sectionedStyle adjusts parseSectionedHelp to a framework.
+type sectionedStyle struct {
+	usageFirst   bool // the first paragraph is the usage, without a "Usage:" title
+	examplePairs bool // examples are "command  description" entries
+}
+
+// helpSection is a titled part of help output.
+type helpSection struct {
+	title string // lower-cased, without the colon; "" before the first title
+	lines []string
+}
+
+// sectionTitleRe matches a section title like "Options:" or "positional
+// arguments:".
+var sectionTitleRe = regexp.MustCompile(`^([A-Za-z][\w -]{0,40}):\s*$`)
+
+// inlineUsageRe matches a usage line that is its own section, like
+// "usage: prog [-h]".
+var inlineUsageRe = regexp.MustCompile(`(?i)^usage:\s*(\S.*)$`)
+
+func splitHelpSections(text string) []helpSection {
+	sections := []helpSection{{}}
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimRight(line, " \t")
+		if m := inlineUsageRe.FindStringSubmatch(line); m != nil {
+			sections = append(sections, helpSection{title: "usage", lines: []string{"  " + m[1]}})
+			continue
+		}
+		if m := sectionTitleRe.FindStringSubmatch(line); m != nil {
+			sections = append(sections, helpSection{title: strings.ToLower(m[1])})
+			continue
+		}
+		s := &sections[len(sections)-1]
+		s.lines = append(s.lines, line)
+	}
+	return sections
+}
+
+// paragraphs splits lines at blank lines, trimming each line.
+func paragraphs(lines []string) [][]string {
+	var paras [][]string
+	var cur []string
+	for _, l := range append(lines, "") {
+		if strings.TrimSpace(l) == "" {
+			if len(cur) > 0 {
+				paras = append(paras, cur)
+				cur = nil
+			}
+			continue
+		}
+		cur = append(cur, strings.TrimSpace(l))
+	}
+	return paras
+}
+
+// usageForms joins a usage paragraph's wrapped lines: a line that names
+// the program again is another form; any other continues the previous one.
+func usageForms(lines []string) []string {
+	var forms []string
+	for _, l := range lines {
+		if len(forms) > 0 && strings.Fields(l)[0] != strings.Fields(forms[0])[0] {
+			forms[len(forms)-1] += " " + l
+			continue
+		}
+		forms = append(forms, l)
+	}
+	return forms
+}
+
+// helpEntry is an item of a list section: a head (flag, command or
+// argument) and its description.
+type helpEntry struct {
+	head string
+	desc string
+}
+
+// entryGapRe separates an entry's head from its description.
+var entryGapRe = regexp.MustCompile(`\s{2,}|\t`)
+
+// helpEntries reads the entries of a list section. Entries start at the
+// section's indentation, or with a flag, which may be indented to line up
+// with long flags; other deeper lines continue a description.
+func helpEntries(lines []string) []helpEntry {
+	indent := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) != "" {
+			if n := indentOf(l); indent < 0 || n < indent {
+				indent = n
+			}
+		}
+	}
+	var entries []helpEntry
+	for _, l := range lines {
+		text := strings.TrimSpace(l)
+		if text == "" {
+			continue
+		}
+		if indentOf(l) > indent && len(entries) > 0 && !strings.HasPrefix(text, "-") {
+			e := &entries[len(entries)-1]
+			e.desc = strings.TrimSpace(e.desc + " " + text)
+			continue
+		}
+		head, desc := text, ""
+		if loc := entryGapRe.FindStringIndex(text); loc != nil {
+			head, desc = text[:loc[0]], strings.TrimSpace(text[loc[1]:])
+		}
+		entries = append(entries, helpEntry{head: head, desc: desc})
+	}
+	return entries
+}
+
+// indentOf measures a line's indentation, counting a tab as eight columns.
+func indentOf(line string) int {
+	n := 0
+	for _, c := range line {
+		switch c {
+		case ' ':
+			n++
+		case '\t':
+			n += 8 - n%8
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+var (
+	// Matches defaults like "[default: json]", `(default: "json")` or
+	// `(default "json")`
+	defaultRe = regexp.MustCompile(`\s*[\[(]default:?\s+"?([^\]);"]*)"?(;[^\]]*)?[\])]`)
+	// Matches choices like "[possible values: a, b]" or `(choices: "a", "b")`
+	choicesRe = regexp.MustCompile(`\s*[\[(](?:possible values|choices):\s*([^\])]*)[\])]`)
+	// Matches yargs value types like "[string]"
+	yargsTypeRe = regexp.MustCompile(`\s*\[(string|boolean|number|array|count)\]`)
+	// Matches aliases like "[aliases: c]"
+	entryAliasRe = regexp.MustCompile(`\s*\[aliases?:[^\]]*\]`)
+	// Matches a repeatable flag's ellipsis, e.g. "--verbose..."
+	repeatableRe = regexp.MustCompile(`(-[\w-]+)\.\.\.`)
+	// Maps yargs' value types to Go's
+	yargsTypes = map[string]string{"string": "string", "boolean": "bool", "number": "float", "array": "[]string", "count": "int"}
+	// Maps click's upper-cased value types to Go's
+	clickTypes = map[string]string{
+		"TEXT": "string", "INTEGER": "int", "INTEGER RANGE": "int", "FLOAT": "float", "FLOAT RANGE": "float",
+		"BOOLEAN": "bool", "PATH": "string", "FILENAME": "string", "FILE": "string", "UUID": "string",
+	}
+	// Matches an argument choice list like "{a,b}" or "[a|b]"
+	choiceArgRe = regexp.MustCompile(`^[{\[]([\w.-]+(?:[,|][\w.-]+)+)[}\]]$`)
+)
+
+// helpFlag parses an option entry.
+func helpFlag(e helpEntry) parsedFlag {
+	head := repeatableRe.ReplaceAllString(e.head, "$1")
+	// click's boolean pairs: --color / --no-color
+	head, _, _ = strings.Cut(head, " / ")
+	f := optionTag(head)
+	f.desc = e.desc
+
+	// a metavar like REGION or <path> names the value, not its type
+	var metavar string
+	if m := choiceArgRe.FindStringSubmatch(f.flagType); m != nil {
+		f.flagType = "string(" + strings.Join(strings.FieldsFunc(m[1], func(r rune) bool { return r == ',' || r == '|' }), "|") + ")"
+	} else if t, ok := clickTypes[f.flagType]; ok {
+		f.flagType = t
+	} else if name, ok := metavarName(f.flagType); ok {
+		metavar, f.flagType = name, "string"
+	}
+
+	var choices []string
+	if m := choicesRe.FindStringSubmatch(f.desc); m != nil {
+		list := m[1]
+		// commander: (choices: "a", "b", default: "a")
+		if before, after, ok := strings.Cut(list, ", default:"); ok {
+			list = before
+			f.defVal = strings.Trim(strings.TrimSpace(after), `"`)
+		}
+		for _, c := range strings.Split(list, ",") {
+			if c = strings.Trim(strings.TrimSpace(c), `"'`); c != "" {
+				choices = append(choices, c)
+			}
+		}
+		f.desc = strings.Replace(f.desc, m[0], "", 1)
+	}
+	if m := defaultRe.FindStringSubmatch(f.desc); m != nil {
+		f.defVal = strings.TrimSpace(m[1])
+		f.desc = strings.Replace(f.desc, m[0], "", 1)
+	}
+	if m := yargsTypeRe.FindStringSubmatch(f.desc); m != nil {
+		if f.flagType == "" {
+			f.flagType = yargsTypes[m[1]]
+		}
+		f.desc = strings.Replace(f.desc, m[0], "", 1)
+	}
+	if len(choices) > 0 {
+		f.flagType = "string(" + strings.Join(choices, "|") + ")"
+	}
+	// a flag without a value is a switch
+	if f.flagType == "" {
+		f.flagType = "bool"
+	}
+	f.desc = strings.TrimSpace(f.desc)
+	if metavar != "" && f.flagType == "string" {
+		f.desc = strings.TrimSpace(f.desc + " (value: " + metavar + ")")
+	}
+	return f
+}
+
+// metavarName returns the name of a flag's value placeholder: a bracketed
+// <name> or an upper-case NAME. Go's flag package names the value's type
+// instead, in lower case.
+func metavarName(arg string) (string, bool) {
+	if strings.HasPrefix(arg, "<") && strings.HasSuffix(arg, ">") {
+		return arg[1 : len(arg)-1], true
+	}
+	if arg != "" && arg == strings.ToUpper(arg) && strings.ToLower(arg) != arg {
+		return arg, true
+	}
+	return "", false
+}
+
+// parseSectionedHelp parses help output made of titled sections, the
+// layout most frameworks other than cobra share.
+func parseSectionedHelp(text string, style sectionedStyle) parsedHelp {
+	var result parsedHelp
+	var usagePrefix []string // words naming the command, e.g. "widgets create"
+
+	addDescription := func(paras [][]string) {
+		if result.description == "" && len(paras) > 0 {
+			result.description = strings.Join(paras[0], " ")
+		}
+	}
+	addUsage := func(lines []string) {
+		result.usage = append(result.usage, usageForms(lines)...)
+		if usagePrefix == nil && len(result.usage) > 0 {
+			for _, w := range strings.Fields(result.usage[0]) {
+				if !isBareWord(w) {
+					break
+				}
+				usagePrefix = append(usagePrefix, w)
+			}
+		}
+	}
+
+	for i, s := range splitHelpSections(text) {
+		switch {
+		case s.title == "":
+			paras := paragraphs(s.lines)
+			if i == 0 && style.usageFirst && len(paras) > 0 {
+				addUsage(paras[0])
+				paras = paras[1:]
+			}
+			addDescription(paras)
+		case s.title == "usage":
+			// the description may follow the usage without a title
+			paras := paragraphs(s.lines)
+			if len(paras) > 0 {
+				addUsage(paras[0])
+				addDescription(paras[1:])
+			}
+		case strings.HasPrefix(s.title, "usage of "):
+			// Go's flag package lists flags under "Usage of <name>:"
+			for _, e := range helpEntries(s.lines) {
+				if strings.HasPrefix(e.head, "-") {
+					result.flags = append(result.flags, helpFlag(e))
+				}
+			}
+		case strings.Contains(s.title, "command"):
+			for _, e := range helpEntries(s.lines) {
+				words := strings.Fields(e.head)
+				// yargs lists commands by full path
+				for _, w := range usagePrefix {
+					if len(words) < 2 || words[0] != w {
+						break
+					}
+					words = words[1:]
+				}
+				if len(words) > 0 && isBareWord(words[0]) {
+					result.subcommands = append(result.subcommands, strings.TrimSuffix(words[0], ","))
+				}
+			}
+		case strings.Contains(s.title, "option") || strings.Contains(s.title, "flag"):
+			for _, e := range helpEntries(s.lines) {
+				if strings.HasPrefix(e.head, "-") || strings.HasPrefix(e.head, "+") {
+					f := helpFlag(e)
+					f.global = strings.Contains(s.title, "global")
+					result.flags = append(result.flags, f)
+				}
+			}
+		case strings.Contains(s.title, "argument") || s.title == "positionals" || s.title == "args":
+			for _, e := range helpEntries(s.lines) {
+				// argparse lists subcommands as a {a,b} positional
+				if m := choiceArgRe.FindStringSubmatch(e.head); m != nil && strings.HasPrefix(e.head, "{") {
+					result.subcommands = append(result.subcommands, strings.Split(m[1], ",")...)
+					continue
+				}
+				if result.argDescs == nil {
+					result.argDescs = make(map[string]string)
+				}
+				name := strings.TrimSuffix(strings.Trim(e.head, "<>[]"), "...")
+				result.argDescs[name] = entryAliasRe.ReplaceAllString(e.desc, "")
+			}
+		case strings.HasPrefix(s.title, "example"):
+			if !style.examplePairs {
+				result.examples = append(result.examples, helpExamples(s.lines)...)
+				continue
+			}
+			for _, e := range helpEntries(s.lines) {
+				result.examples = append(result.examples, commentLine(e.desc)+e.head)
+			}
+		case s.title == "aliases" || s.title == "alias":
+			for _, p := range paragraphs(s.lines) {
+				for _, a := range strings.Split(strings.Join(p, ","), ",") {
+					if a = strings.TrimSpace(a); a != "" {
+						result.aliases = append(result.aliases, a)
+					}
+				}
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,740 @@
+package cli
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Man page support: roff pages using the man or mdoc macro packages are
+// reduced to sections of paragraphs, from which the same command shape as
+// help output is read.
+
+// manPage is a parsed man page.
+type manPage struct {
+	title    string // from .TH / .Dt, e.g. GIT-COMMIT
+	sections []manSection
+}
+
+type manSection struct {
+	title string // upper-cased, e.g. OPTIONS
+	paras []manPara
+}
+
+// manPara is a paragraph. Tagged paragraphs (.TP, .IP, .It) are how pages
+// list options; literal ones (.nf, .EX, .Bd -literal) keep their lines.
+type manPara struct {
+	tag     string
+	text    string
+	literal bool
+}
+
+// isRoff reports whether text is roff source rather than help output.
+func isRoff(text string) bool {
+	for _, line := range strings.SplitN(text, "\n", 50) {
+		for _, macro := range []string{".TH ", ".Dd", ".SH ", ".Sh ", `.\"`, `'\"`} {
+			if strings.HasPrefix(line, macro) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// manParser holds the state of one pass over a page.
+type manParser struct {
+	page      manPage
+	name      string   // the page's command name, for mdoc's bare .Nm
+	lines     []string // lines of the open paragraph
+	tag       string
+	literal   bool // no-fill mode
+	wantTag   bool // the next line is the tag of a .TP paragraph
+	extendTag bool // the next line is another tag of the open paragraph (.TQ)
+}
+
+// parseManPage parses roff source.
+func parseManPage(src string) manPage {
+	p := &manParser{}
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		// continuation lines
+		for strings.HasSuffix(line, `\`) && !strings.HasSuffix(line, `\\`) && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + lines[i]
+		}
+		if line == "" || (line[0] != '.' && line[0] != '\'') {
+			if line == "" && !p.literal {
+				p.flush()
+				continue
+			}
+			p.text(unescape(line))
+			continue
+		}
+		macro, args := splitRequest(line[1:])
+		switch macro {
+		case "de", "de1", "am", "ig":
+			// macro definitions and ignored blocks run to ".."
+			for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != ".." {
+				i++
+			}
+			i++
+			continue
+		}
+		p.request(macro, args)
+	}
+	p.flush()
+	return p.page
+}
+
+// splitRequest splits a request line into the macro name and its
+// arguments, honoring double quotes.
+func splitRequest(line string) (string, []string) {
+	line = strings.TrimLeft(line, " \t")
+	if strings.HasPrefix(line, `\"`) || strings.HasPrefix(line, `\#`) {
+		return `\"`, nil
+	}
+	var args []string
+	for i := 0; i < len(line); {
+		switch {
+		case line[i] == ' ' || line[i] == '\t':
+			i++
+		case line[i] == '"':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(line); j++ {
+				if line[j] == '"' {
+					if j+1 < len(line) && line[j+1] == '"' {
+						b.WriteByte('"')
+						j++
+						continue
+					}
+					break
+				}
+				b.WriteByte(line[j])
+			}
+			args = append(args, b.String())
+			i = j + 1
+		case strings.HasPrefix(line[i:], `\"`):
+			i = len(line)
+		default:
+			j := i
+			for j < len(line) && line[j] != ' ' && line[j] != '\t' {
+				if line[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j > len(line) {
+				j = len(line)
+			}
+			args = append(args, line[i:j])
+			i = j
+		}
+	}
+	if len(args) == 0 {
+		return "", nil
+	}
+	return args[0], args[1:]
+}
+
+func (p *manParser) request(macro string, args []string) {
+	switch macro {
+	case "TH", "Dt":
+		if len(args) > 0 {
+			p.page.title = unescape(args[0])
+		}
+	case "SH", "Sh":
+		p.flush()
+		p.literal = false
+		p.page.sections = append(p.page.sections, manSection{title: strings.ToUpper(unescape(strings.Join(args, " ")))})
+	case "SS", "Ss":
+		p.flush()
+		p.text(unescape(strings.Join(args, " ")))
+		p.flush()
+	case "PP", "LP", "P", "Pp", "Lp", "sp", "HP", "YS":
+		p.flush()
+	case "br":
+		if len(p.lines) > 0 && !p.literal {
+			p.lines[len(p.lines)-1] += "\n"
+		}
+	case "TP":
+		p.flush()
+		p.wantTag = true
+	case "TQ":
+		p.wantTag, p.extendTag = true, true
+	case "IP":
+		p.flush()
+		if len(args) > 0 {
+			if tag := unescape(args[0]); tag != "•" && tag != "*" && tag != "-" {
+				p.tag = tag
+			}
+		}
+	case "It":
+		p.flush()
+		p.tag = p.mdoc(args)
+	case "nf", "EX":
+		p.flush()
+		p.literal = true
+	case "fi", "EE":
+		p.flush()
+		p.literal = false
+	case "Bd":
+		p.flush()
+		for _, a := range args {
+			if a == "-literal" || a == "-unfilled" {
+				p.literal = true
+			}
+		}
+	case "Ed":
+		p.flush()
+		p.literal = false
+	case "Dl":
+		p.flush()
+		p.literal = true
+		p.text(p.mdoc(args))
+		p.flush()
+		p.literal = false
+	case "Bl", "El":
+		p.flush()
+	case "B", "I", "SM", "SB":
+		p.text(unescape(strings.Join(args, " ")))
+	case "BR", "RB", "IR", "RI", "BI", "IB":
+		// alternating fonts, no space between the arguments
+		var b strings.Builder
+		for _, a := range args {
+			b.WriteString(unescape(a))
+		}
+		p.text(b.String())
+	case "SY":
+		p.flush()
+		p.text(unescape(strings.Join(args, " ")))
+	case "OP":
+		p.text("[" + unescape(strings.Join(args, " ")) + "]")
+	case "UR", "MT":
+		if len(args) > 0 {
+			p.text("<" + unescape(args[0]) + ">")
+		}
+	default:
+		if mdocCallable[macro] || macro == "Nd" || macro == "Xo" || macro == "Xc" {
+			if macro == "Nm" && p.name == "" && len(args) > 0 {
+				p.name = unescape(args[0])
+			}
+			if text := p.mdoc(append([]string{macro}, args...)); text != "" {
+				p.text(text)
+			}
+		}
+		// Anything else (conditionals, registers, fonts, spacing) carries
+		// no text worth keeping
+	}
+}
+
+// text adds a line of text to the open paragraph, or sets its tag.
+func (p *manParser) text(line string) {
+	if p.wantTag {
+		if p.extendTag && p.tag != "" {
+			p.tag += ", " + strings.TrimSpace(line)
+		} else {
+			p.tag = strings.TrimSpace(line)
+		}
+		p.wantTag, p.extendTag = false, false
+		return
+	}
+	if !p.literal {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			return
+		}
+	}
+	p.lines = append(p.lines, line)
+}
+
+func (p *manParser) flush() {
+	if len(p.lines) > 0 || p.tag != "" {
+		para := manPara{tag: p.tag, literal: p.literal}
+		if p.literal {
+			para.text = strings.Join(p.lines, "\n")
+		} else {
+			para.text = strings.ReplaceAll(strings.Join(p.lines, " "), "\n ", "\n")
+		}
+		if len(p.page.sections) == 0 {
+			p.page.sections = append(p.page.sections, manSection{})
+		}
+		s := &p.page.sections[len(p.page.sections)-1]
+		s.paras = append(s.paras, para)
+	}
+	p.lines, p.tag = nil, ""
+}
+
+// mdocCallable are the mdoc macros that may appear mid-line.
+var mdocCallable = map[string]bool{
+	"Ad": true, "An": true, "Aq": true, "Ar": true, "Bq": true, "Brq": true, "Cd": true, "Cm": true,
+	"Dq": true, "Dv": true, "Em": true, "Er": true, "Ev": true, "Fa": true, "Fl": true, "Fn": true,
+	"Ft": true, "Ic": true, "Li": true, "Lk": true, "Mt": true, "Nm": true, "No": true, "Ns": true,
+	"Oc": true, "Oo": true, "Op": true, "Pa": true, "Pq": true, "Ql": true, "Qq": true, "Sq": true,
+	"Sy": true, "Tn": true, "Va": true, "Xr": true,
+}
+
+// mdocEnclosures are the mdoc macros that wrap the rest of the line.
+var mdocEnclosures = map[string][2]string{
+	"Op": {"[", "]"}, "Aq": {"<", ">"}, "Bq": {"[", "]"}, "Brq": {"{", "}"},
+	"Dq": {`"`, `"`}, "Qq": {`"`, `"`}, "Pq": {"(", ")"}, "Sq": {"'", "'"}, "Ql": {"'", "'"},
+}
+
+// mdoc renders an mdoc macro line (without its leading dot) as text.
+func (p *manParser) mdoc(args []string) string {
+	var b strings.Builder
+	noSpace := true
+	emit := func(w string) {
+		if w == "" {
+			return
+		}
+		if !noSpace && !strings.Contains(".,;:)]?!", w[:1]) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(w)
+		noSpace = strings.HasSuffix(w, "(") || strings.HasSuffix(w, "[")
+	}
+	for i := 0; i < len(args); i++ {
+		macro := args[i]
+		if !mdocCallable[macro] && macro != "Nd" && macro != "Xo" && macro != "Xc" {
+			emit(unescape(macro))
+			continue
+		}
+		if enc, ok := mdocEnclosures[macro]; ok {
+			inner := p.mdoc(args[i+1:])
+			emit(enc[0] + inner + enc[1])
+			break
+		}
+		j := i + 1
+		for j < len(args) && !mdocCallable[args[j]] {
+			j++
+		}
+		operands := args[i+1 : j]
+		i = j - 1
+		switch macro {
+		case "Fl":
+			if len(operands) == 0 {
+				emit("-")
+				// Fl Fl verbose is --verbose
+				noSpace = j < len(args) && args[j] == "Fl"
+			}
+			for _, o := range operands {
+				if strings.Contains(".,;:)]|", o) {
+					emit(o)
+				} else {
+					emit("-" + unescape(o))
+				}
+			}
+		case "Ar":
+			if len(operands) == 0 {
+				emit("file ...")
+			}
+			for _, o := range operands {
+				emit(unescape(o))
+			}
+		case "Nm":
+			if len(operands) == 0 {
+				emit(p.name)
+			}
+			for _, o := range operands {
+				emit(unescape(o))
+			}
+		case "Nd":
+			emit("- " + unescape(strings.Join(operands, " ")))
+		case "Xr":
+			if len(operands) >= 2 {
+				emit(unescape(operands[0]) + "(" + operands[1] + ")")
+				operands = operands[2:]
+			}
+			for _, o := range operands {
+				emit(unescape(o))
+			}
+		case "Ns":
+			noSpace = true
+			for _, o := range operands {
+				emit(unescape(o))
+			}
+		case "Oo":
+			emit("[")
+			noSpace = true
+			for _, o := range operands {
+				emit(unescape(o))
+			}
+		case "Oc":
+			noSpace = true
+			emit("]")
+			for _, o := range operands {
+				emit(unescape(o))
+			}
+		default:
+			for _, o := range operands {
+				emit(unescape(o))
+			}
+		}
+	}
+	return b.String()
+}
+
+// glyphs are the roff special characters worth rendering, by name.
+var glyphs = map[string]string{
+	"em": "—", "en": "–", "hy": "-", "mi": "-", "aq": "'", "dq": `"`, "lq": `"`, "rq": `"`,
+	"oq": "'", "cq": "'", "bu": "•", "co": "©", "rg": "®", "tm": "™", "mu": "×", "de": "°",
+	"->": "→", "<-": "←", "ga": "`", "ti": "~", "ha": "^", "rs": `\`, "sl": "/", "ba": "|",
+	"or": "|", "lB": "[", "rB": "]", "lC": "{", "rC": "}", "la": "<", "ra": ">", "Fo": "«", "Fc": "»",
+}
+
+// unescape renders roff escapes in text, dropping font and size changes.
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch e := s[i]; e {
+		case 'f', 'n', 'F', 'g', 'k', 'm', 'M', 'V', 'Y':
+			// font, register and other escapes taking a name argument
+			_, n := escapeName(s[i+1:])
+			i += n
+		case '*':
+			name, n := escapeName(s[i+1:])
+			i += n
+			switch name {
+			case "R":
+				b.WriteString("®")
+			case "lq", "rq", "Lq", "Rq":
+				b.WriteByte('"')
+			case "Tm":
+				b.WriteString("™")
+			}
+		case '(', '[':
+			name, n := escapeName(s[i:])
+			i += n - 1
+			b.WriteString(glyphs[name])
+		case 's':
+			// size: \s+1, \s-2, \s0, \s(12, \s[12]
+			j := i + 1
+			if j < len(s) && (s[j] == '+' || s[j] == '-') {
+				j++
+			}
+			if j < len(s) && (s[j] == '(' || s[j] == '[') {
+				_, n := escapeName(s[j:])
+				j += n
+			} else {
+				for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+					j++
+				}
+			}
+			i = j - 1
+		case 'h', 'v', 'w', 'o', 'l', 'L', 'D', 'X', 'N', 'Z', 'x', 'b', 'A', 'B', 'C', 'R', 'S', 'H':
+			// escapes with a delimited argument, e.g. \h'2n'
+			if i+1 < len(s) {
+				delim := s[i+1]
+				if end := strings.IndexByte(s[i+2:], delim); end >= 0 {
+					i += end + 2
+				}
+			}
+		case 'e', '\\':
+			b.WriteByte('\\')
+		case '-':
+			b.WriteByte('-')
+		case ' ', '~', '0':
+			b.WriteByte(' ')
+		case '&', '|', '^', '%', 'c', ')', ':', 'd', 'u', 'p', 'r', 't', 'a', 'z':
+		case '"', '#':
+			// comment to end of line
+			return strings.TrimRight(b.String(), " ")
+		default:
+			b.WriteByte(e)
+		}
+	}
+	return b.String()
+}
+
+// escapeName reads the name of an escape: one character, two after "(",
+// or any number within "[...]". It returns the name and the bytes read.
+func escapeName(s string) (string, int) {
+	switch {
+	case s == "":
+		return "", 0
+	case s[0] == '(' && len(s) >= 3:
+		return s[1:3], 3
+	case s[0] == '[':
+		if end := strings.IndexByte(s, ']'); end >= 0 {
+			return s[1:end], end + 1
+		}
+		return s[1:], len(s)
+	}
+	return s[:1], 1
+}
+
+// section returns the first section whose title has one of the prefixes.
+func (m manPage) section(prefixes ...string) *manSection {
+	for i := range m.sections {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(m.sections[i].title, prefix) {
+				return &m.sections[i]
+			}
+		}
+	}
+	return nil
+}
+
+// nameLine splits the NAME section ("git-commit - Record changes") into
+// the page's command name and summary.
+func (m manPage) nameLine() (name, summary string) {
+	s := m.section("NAME")
+	if s == nil || len(s.paras) == 0 {
+		return strings.ToLower(m.title), ""
+	}
+	text := s.paras[0].text
+	for _, sep := range []string{" - ", " — ", " – "} {
+		if before, after, ok := strings.Cut(text, sep); ok {
+			name, summary = before, strings.TrimSpace(after)
+			break
+		}
+	}
+	if name == "" {
+		name = text
+	}
+	name, _, _ = strings.Cut(name, ",")
+	return strings.TrimSpace(name), summary
+}
+
+// optionTagRe matches a flag at the start of one alias of an option tag,
+// e.g. "--message=<msg>", "-m <msg>", "--color[=when]" or "--[no-]verify".
+var optionTagRe = regexp.MustCompile(`^([-+]{1,2}(?:\[[\w-]+\])?[\w?#@-]*)\s*(?:\[=(.*)\]|=?\s*(.*))$`)
+
+// parsed reduces the page to the shape help output is parsed into.
+func (m manPage) parsed() parsedHelp {
+	var result parsedHelp
+	_, result.description = m.nameLine()
+	if s := m.section("DESCRIPTION"); s != nil {
+		for _, para := range s.paras {
+			if para.tag == "" && !para.literal {
+				result.description = joinParagraphs(result.description, para.text)
+				break
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, s := range m.sections {
+		if strings.HasPrefix(s.title, "EXAMPLE") || strings.HasPrefix(s.title, "SEE ALSO") {
+			continue
+		}
+		for _, para := range s.paras {
+			if !strings.HasPrefix(para.tag, "-") && !strings.HasPrefix(para.tag, "+") {
+				continue
+			}
+			f := optionTag(para.tag)
+			if f.name == "" || seen[f.name] {
+				continue
+			}
+			seen[f.name] = true
+			f.desc = para.text
+			result.flags = append(result.flags, f)
+		}
+	}
+
+	if s := m.section("EXAMPLE"); s != nil {
+		result.examples = manExamples(s.paras)
+	}
+	// each SYNOPSIS paragraph (or line, when broken with .br) is a form
+	if s := m.section("SYNOPSIS"); s != nil {
+		for _, para := range s.paras {
+			for _, line := range strings.Split(para.text, "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					result.usage = append(result.usage, line)
+				}
+			}
+		}
+	}
+	return result
+}
+
+// optionTag parses an option's tag, like "-m <msg>, --message=<msg>", into
+// its long name, shorthand and argument.
+func optionTag(tag string) parsedFlag {
+	var f parsedFlag
+	for _, alias := range splitAliases(tag) {
+		m := optionTagRe.FindStringSubmatch(alias)
+		if m == nil {
+			continue
+		}
+		flag, arg := m[1], strings.TrimSpace(m[2]+m[3])
+		if arg != "" && f.flagType == "" {
+			f.flagType = arg
+		}
+		switch {
+		case strings.HasPrefix(flag, "--") && f.name != "" && !strings.HasPrefix(f.name, "--"):
+			f.shorthand, f.name = f.name, flag
+		case f.name == "":
+			f.name = flag
+		case strings.HasPrefix(flag, "--") || f.shorthand != "":
+			// further long aliases
+		default:
+			f.shorthand = flag
+		}
+	}
+	return f
+}
+
+// splitAliases splits a tag at commas outside brackets.
+func splitAliases(tag string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, c := range tag {
+		switch c {
+		case '[', '<', '{', '(':
+			depth++
+		case ']', '>', '}', ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(tag[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(tag[start:]))
+}
+
+// manExamples turns an EXAMPLES section into examples: each command block
+// (a literal paragraph, or the tag of a tagged one) with the text
+// describing it as a leading comment.
+func manExamples(paras []manPara) []string {
+	var examples []string
+	desc := ""
+	for _, para := range paras {
+		switch {
+		case para.literal:
+			// drop shell prompts
+			lines := strings.Split(strings.Trim(para.text, "\n"), "\n")
+			for i, l := range lines {
+				lines[i] = strings.TrimPrefix(l, "$ ")
+			}
+			examples = append(examples, commentLine(desc)+strings.Join(lines, "\n"))
+			desc = ""
+		case para.tag != "":
+			examples = append(examples, commentLine(para.text)+para.tag)
+			desc = ""
+		default:
+			desc = para.text
+		}
+	}
+	return examples
+}
+
+func commentLine(desc string) string {
+	desc = strings.TrimSuffix(strings.TrimSpace(desc), ":")
+	if desc == "" {
+		return ""
+	}
+	return "# " + strings.ReplaceAll(desc, "\n", "\n# ") + "\n"
+}
+
+func joinParagraphs(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + "\n\n" + b
+}
+
+// text renders the page as plain text, the way man would display it.
+func (m manPage) text() string {
+	var b strings.Builder
+	for _, s := range m.sections {
+		if s.title != "" {
+			fmt.Fprintf(&b, "%s\n", s.title)
+		}
+		for _, para := range s.paras {
+			if para.tag != "" {
+				fmt.Fprintf(&b, "    %s\n", para.tag)
+				fmt.Fprintf(&b, "%s\n", indent(para.text, "        "))
+			} else {
+				fmt.Fprintf(&b, "%s\n", indent(para.text, "    "))
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func indent(text, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = prefix + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// subpages returns the subcommands whose pages a page refers to, going by
+// references to pages named after it: git-commit(1) from git(1) is commit.
+func (m manPage) subpages(name string) []string {
+	re := regexp.MustCompile(`(?:^|[^\w-])` + regexp.QuoteMeta(name) + `-([a-z0-9][\w.-]*)\(\d\w*\)`)
+	var subs []string
+	seen := make(map[string]bool)
+	for _, match := range re.FindAllStringSubmatch(m.text(), -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			subs = append(subs, match[1])
+		}
+	}
+	return subs
+}
+
+// readManFile reads a man page, decompressing .gz and .bz2 files and
+// following a .so include to the page it names.
+func readManFile(path string) (string, error) {
+	for range 3 {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case strings.HasSuffix(path, ".gz"):
+			zr, err := gzip.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return "", fmt.Errorf("%s: %w", path, err)
+			}
+			if data, err = io.ReadAll(zr); err != nil {
+				return "", fmt.Errorf("%s: %w", path, err)
+			}
+		case strings.HasSuffix(path, ".bz2"):
+			if data, err = io.ReadAll(bzip2.NewReader(bytes.NewReader(data))); err != nil {
+				return "", fmt.Errorf("%s: %w", path, err)
+			}
+		}
+		text := string(data)
+		first, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+		if !strings.HasPrefix(first, ".so ") {
+			return text, nil
+		}
+		// .so man1/other.1 is relative to the top of the man tree
+		target := filepath.Join(filepath.Dir(filepath.Dir(path)), strings.TrimSpace(first[4:]))
+		if _, err := os.Stat(target); err != nil {
+			for _, ext := range []string{".gz", ".bz2"} {
+				if _, err := os.Stat(target + ext); err == nil {
+					target += ext
+					break
+				}
+			}
+		}
+		path = target
+	}
+	return "", fmt.Errorf("%s: too many .so redirections", path)
+}
@@ -0,0 +1,22 @@
+# fish completion for tool
+set -l commands create delete config
+
+complete -c tool -f
+complete -c tool -s v -l verbose -d 'Print each request as it is made'
+complete -c tool -s c -l config -r -F -d 'Read configuration from file'
+complete -c tool -l color -x -a 'always never auto' -d 'Colorize output'
+
+complete -c tool -n "not __fish_seen_subcommand_from $commands" -a create -d 'Create a widget'
+complete -c tool -n "not __fish_seen_subcommand_from $commands" -a delete -d 'Delete widgets'
+complete -c tool -n '__fish_use_subcommand' -a config -d 'Manage configuration'
+
+complete -c tool -n '__fish_seen_subcommand_from create' -s f -l file -r -d 'JSON definition to read'
+complete -c tool -n '__fish_seen_subcommand_from create' -l format -xa 'json'
+complete -c tool -n '__fish_seen_subcommand_from create' -l format -xa 'yaml' -d 'Definition format'
+complete -c tool -n '__fish_seen_subcommand_from create delete' -l dry-run -d 'Show what would change'
+complete -c tool -n '__fish_seen_subcommand_from delete' -a '(__tool_widgets)'
+complete -c tool -n '__fish_seen_subcommand_from config; and not __fish_seen_subcommand_from get set' -a 'get set'
+complete -c tool -n '__fish_seen_subcommand_from config; and __fish_seen_subcommand_from set' \
+    -o global -d 'Write the user configuration'
+
+complete -c other -l unrelated
@@ -0,0 +1,258 @@
+package cli
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Usage lines and examples: "Usage: foo create <name> [--region R]
+// [files...]" names a command's positional arguments and the flags it
+// cannot do without.
+
+// parsedArg is a positional argument read from a usage line.
+type parsedArg struct {
+	name     string
+	required bool
+	variadic bool
+	values   []string // the alternatives of a {start|stop} choice
+}
+
+// usageTokenRe matches one element of a usage line: a bracketed group, a
+// <placeholder> or a bare word, each optionally followed by an ellipsis.
+var usageTokenRe = regexp.MustCompile(`^(\[|\{|<[^>]*>|[^\s\[\]{}]+)`)
+
+// genericPlaceholders are usage elements standing for flags or subcommands
+// rather than arguments.
+var genericPlaceholders = map[string]bool{
+	"flags": true, "options": true, "option": true, "opts": true,
+	"command": true, "subcommand": true, "global flags": true, "global options": true,
+}
+
+// parseUsage reads a usage line into its positional arguments and the
+// flags it requires (those outside brackets). pathLen is the number of
+// words naming the command, which the line starts with; known are argument
+// names the help documents, which may be written as bare words.
+func parseUsage(line string, pathLen int, known map[string]string) (args []parsedArg, requiredFlags []string) {
+	elems := splitUsage(line)
+	// Skip the command path, which may name the binary by path or be
+	// shorter when the parent is left out
+	for skipped := 0; len(elems) > 0 && skipped < pathLen && isBareWord(elems[0]); skipped++ {
+		elems = elems[1:]
+	}
+	collectUsage(elems, true, known, &args, &requiredFlags)
+	return args, requiredFlags
+}
+
+// collectUsage walks usage elements, appending arguments and required
+// flags. Everything inside brackets is optional.
+func collectUsage(elems []string, required bool, known map[string]string, args *[]parsedArg, requiredFlags *[]string) {
+	for i := 0; i < len(elems); i++ {
+		e := elems[i]
+		variadic := false
+		if i+1 < len(elems) && elems[i+1] == "..." {
+			variadic = true
+			i++
+		}
+		if strings.HasSuffix(e, "...") && e != "..." {
+			variadic = true
+			e = strings.TrimSuffix(e, "...")
+		}
+
+		switch {
+		case e == "..." || e == "--" || e == "|":
+		case strings.HasPrefix(e, "["):
+			inner := strings.TrimSuffix(strings.TrimPrefix(e, "["), "]")
+			if genericPlaceholders[strings.ToLower(strings.Trim(strings.TrimSuffix(inner, "..."), "<> "))] {
+				continue
+			}
+			n := len(*args)
+			collectUsage(splitUsage(inner), false, known, args, requiredFlags)
+			if variadic && len(*args) > n {
+				(*args)[len(*args)-1].variadic = true
+			}
+		case strings.HasPrefix(e, "{"):
+			inner := strings.TrimSuffix(strings.TrimPrefix(e, "{"), "}")
+			var values []string
+			for _, v := range strings.FieldsFunc(inner, func(r rune) bool { return r == '|' || r == ',' }) {
+				if v = strings.TrimSpace(v); v != "" {
+					values = append(values, v)
+				}
+			}
+			if len(values) > 0 && !strings.HasPrefix(values[0], "-") {
+				*args = append(*args, parsedArg{name: strings.Join(values, "|"), required: required, variadic: variadic, values: values})
+			}
+		case strings.HasPrefix(e, "-"):
+			flag, _, _ := strings.Cut(e, "=")
+			// "-f FILE", "[-o file]" or "[--size {small,large}]": the next
+			// element is the flag's value
+			if i+1 < len(elems) && (isPlaceholder(elems[i+1]) || isBareWord(elems[i+1]) || strings.HasPrefix(elems[i+1], "{")) && !strings.Contains(e, "=") {
+				i++
+				if i+1 < len(elems) && elems[i+1] == "..." {
+					i++
+				}
+			}
+			if required && len(flag) > 1 {
+				*requiredFlags = append(*requiredFlags, flag)
+			}
+		case strings.HasPrefix(e, "<"):
+			name := strings.TrimSuffix(strings.TrimPrefix(e, "<"), ">")
+			if genericPlaceholders[strings.ToLower(name)] {
+				continue
+			}
+			*args = append(*args, parsedArg{name: name, required: required, variadic: variadic})
+		case isPlaceholder(e):
+			if genericPlaceholders[strings.ToLower(e)] {
+				continue
+			}
+			*args = append(*args, parsedArg{name: e, required: required, variadic: variadic})
+		default:
+			// A bare word is a literal keyword (e.g. "tool remote add")
+			// unless it is inside brackets, [name], or a documented argument
+			if _, ok := known[e]; (ok || !required) && !genericPlaceholders[strings.ToLower(e)] {
+				*args = append(*args, parsedArg{name: e, required: required, variadic: variadic})
+			}
+		}
+	}
+}
+
+// splitUsage splits a usage line into elements, keeping bracketed groups
+// whole.
+func splitUsage(line string) []string {
+	var elems []string
+	for line = strings.TrimSpace(line); line != ""; line = strings.TrimSpace(line) {
+		m := usageTokenRe.FindString(line)
+		if m == "" {
+			// a stray closing bracket
+			line = line[1:]
+			continue
+		}
+		end := len(m)
+		if m == "[" || m == "{" {
+			end = matchingBracket(line)
+		}
+		elem := line[:end]
+		line = line[end:]
+		if strings.HasPrefix(line, "...") {
+			elem += "..."
+			line = line[3:]
+		}
+		elems = append(elems, elem)
+	}
+	return elems
+}
+
+// matchingBracket returns the index just past the bracket closing the one
+// line starts with, or len(line) if it is never closed.
+func matchingBracket(line string) int {
+	depth := 0
+	for i, c := range line {
+		switch c {
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(line)
+}
+
+// isBareWord reports whether a usage element is a literal word, like the
+// command's name.
+func isBareWord(e string) bool {
+	return e != "" && !strings.ContainsAny(e[:1], "[{<-") && !isPlaceholder(e) && !strings.HasSuffix(e, "...")
+}
+
+// placeholderRe matches an upper-case argument placeholder like FILE or
+// SRC_DIR.
+var placeholderRe = regexp.MustCompile(`^[A-Z][A-Z0-9_-]*$`)
+
+// isPlaceholder reports whether a usage element stands for a value.
+func isPlaceholder(e string) bool {
+	e = strings.TrimSuffix(e, "...")
+	return strings.HasPrefix(e, "<") || placeholderRe.MatchString(e)
+}
+
+// subcommandUsageRe matches the placeholder a usage line takes a subcommand
+// with, e.g. [command], <COMMAND> or COMMAND.
+var subcommandUsageRe = regexp.MustCompile(`(?i)[\[<](sub)?command[\]>]|\bCOMMAND\b`)
+
+// usageLine picks the usage line describing the command's own arguments:
+// the first that does not invoke a subcommand.
+func usageLine(lines []string) string {
+	for _, l := range lines {
+		if !subcommandUsageRe.MatchString(l) {
+			return l
+		}
+	}
+	return ""
+}
+
+// requiredMarkerRe matches markers like "(required)" in a flag description.
+var requiredMarkerRe = regexp.MustCompile(`(?i)\s*[(\[]required[)\]]`)
+
+// requiredMarker reports whether a flag description marks the flag
+// required, returning the description without the marker.
+func requiredMarker(desc string) (string, bool) {
+	if !requiredMarkerRe.MatchString(desc) {
+		return desc, false
+	}
+	return strings.TrimSpace(requiredMarkerRe.ReplaceAllString(desc, "")), true
+}
+
+// helpExamples splits the lines of an Examples section into examples.
+// Blank lines separate examples; within a block without comments, each
+// command is its own example.
+func helpExamples(lines []string) []string {
+	var examples []string
+	var block []string
+	flush := func() {
+		if len(block) == 0 {
+			return
+		}
+		commented := false
+		for _, l := range block {
+			commented = commented || strings.HasPrefix(l, "#")
+		}
+		if commented {
+			examples = append(examples, strings.Join(block, "\n"))
+		} else {
+			// a trailing backslash continues a command
+			cur := ""
+			for _, l := range block {
+				cur += l
+				if strings.HasSuffix(l, `\`) {
+					cur += "\n"
+					continue
+				}
+				examples = append(examples, cur)
+				cur = ""
+			}
+			if cur != "" {
+				examples = append(examples, strings.TrimSuffix(cur, "\n"))
+			}
+		}
+		block = nil
+	}
+
+	indent := -1
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			flush()
+			continue
+		}
+		// dedent by the section's indentation, keeping deeper indents
+		lead := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || lead < indent {
+			indent = lead
+		}
+		text := strings.TrimRight(line[min(lead, indent):], " \t")
+		text = strings.TrimPrefix(text, "$ ")
+		block = append(block, text)
+	}
+	flush()
+	return examples
+}
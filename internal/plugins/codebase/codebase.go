@@ -47,8 +47,7 @@ func (p *Plugin) Fetch(source instructions.SpecSource) ([]byte, error) {
 		maxFiles = 1000
 	}
 
-	// Load gitignore patterns
-	gitignorePatterns := loadGitignore(root)
+	ignore := newIgnoreMatcher(root)
 
 	// Scan file tree
 	var entries []fileInfo
@@ -58,6 +57,7 @@ func (p *Plugin) Fetch(source instructions.SpecSource) ([]byte, error) {
 		}
 		rel, _ := filepath.Rel(root, path)
 		if rel == "." {
+			ignore.loadDir(root, rel)
 			return nil
 		}
 
@@ -72,13 +72,16 @@ func (p *Plugin) Fetch(source instructions.SpecSource) ([]byte, error) {
 			}
 		}
 
-		// Apply gitignore
-		if matchesAny(rel, gitignorePatterns) {
+		// Apply .gitignore, .scignore and .git/info/exclude
+		if ignore.ignored(rel, info.IsDir()) {
 			if info.IsDir() {
 				return filepath.SkipDir
 			}
 			return nil
 		}
+		if info.IsDir() {
+			ignore.loadDir(root, rel)
+		}
 
 		// Apply include/exclude
 		if len(source.Include) > 0 && !info.IsDir() {
@@ -238,8 +241,12 @@ func (p *Plugin) Parse(raw []byte, source instructions.SpecSource) (*ir.Intermed
 
 	structure.Stack = stack
 
+	ops, groups := extractRoutes(scan.Root, scan.Entries)
+
 	return &ir.IntermediateRepr{
-		Structure: structure,
+		Operations: ops,
+		Groups:     groups,
+		Structure:  structure,
 		Metadata: map[string]string{
 			"type": "codebase",
 			"root": scan.Root,
@@ -377,42 +384,6 @@ func classifyFile(rel string) string {
 	}
 }
 
-func loadGitignore(root string) []string {
-	data, err := os.ReadFile(filepath.Join(root, ".gitignore"))
-	if err != nil {
-		return nil
-	}
-	var patterns []string
-	for _, line := range strings.Split(string(data), "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-		patterns = append(patterns, line)
-	}
-	return patterns
-}
-
-func matchesAny(rel string, patterns []string) bool {
-	for _, pattern := range patterns {
-		pattern = strings.TrimSuffix(pattern, "/")
-		if matched, _ := filepath.Match(pattern, rel); matched {
-			return true
-		}
-		if matched, _ := filepath.Match(pattern, filepath.Base(rel)); matched {
-			return true
-		}
-		// Check if any path component matches
-		parts := strings.Split(rel, string(filepath.Separator))
-		for _, part := range parts {
-			if matched, _ := filepath.Match(pattern, part); matched {
-				return true
-			}
-		}
-	}
-	return false
-}
-
 func prioritizeFiles(entries []fileInfo, maxFiles int) []fileInfo {
 	// Score files by importance
 	type scored struct {
@@ -3,9 +3,11 @@ package codebase
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/roberthamel/skill-compiler/internal/instructions"
+	"github.com/roberthamel/skill-compiler/internal/ir"
 )
 
 func setupTestDir(t *testing.T) string {
@@ -89,6 +91,147 @@ func TestParse_FileTree(t *testing.T) {
 	}
 }
 
+func TestIgnoreRules(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		isDir   bool
+		want    bool
+	}{
+		{"*.log", "app.log", false, true},
+		{"*.log", "logs/deep/app.log", false, true},
+		{"/build", "build", true, true},
+		{"/build", "src/build", true, false},
+		{"docs/*.md", "docs/a.md", false, true},
+		{"docs/*.md", "docs/sub/a.md", false, false},
+		{"docs/*.md", "x/docs/a.md", false, false},
+		{"**/fixtures", "a/b/fixtures", true, true},
+		{"**/fixtures", "fixtures", true, true},
+		{"a/**/z", "a/z", false, true},
+		{"a/**/z", "a/b/c/z", false, true},
+		{"out/**", "out/x/y", false, true},
+		{"tmp/", "tmp", true, true},
+		{"tmp/", "tmp", false, false},
+		{"file?.txt", "file1.txt", false, true},
+		{"file[0-9].txt", "filea.txt", false, false},
+		{"file[!0-9].txt", "filea.txt", false, true},
+		{`\#notes`, "#notes", false, true},
+		{"# comment", "# comment", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			m := &ignoreMatcher{}
+			if rule, ok := parseIgnoreRule(tt.pattern, ""); ok {
+				m.rules = append(m.rules, rule)
+			}
+			if got := m.ignored(tt.path, tt.isDir); got != tt.want {
+				t.Errorf("ignored(%q) with %q = %v, want %v", tt.path, tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetch_IgnoreFiles(t *testing.T) {
+	dir := setupTestDir(t)
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".gitignore", "*.tmp\n!keep.tmp\n/generated\n")
+	write(".scignore", "secrets/\n")
+	write(".git/info/exclude", "local.txt\n")
+	write("keep.tmp", "kept")
+	write("generated/out.go", "package generated\n")
+	write("pkg/generated/types.go", "package generated\n")
+	write("secrets/key.pem", "key")
+	write("local.txt", "mine")
+	write("pkg/.gitignore", "*.pb.go\n")
+	write("pkg/api.pb.go", "package pkg\n")
+	write("pkg/api.go", "package pkg\n")
+	write("other/api.pb.go", "package other\n")
+
+	p := New()
+	source := instructions.SpecSource{Type: "codebase", Path: dir}
+	raw, err := p.Fetch(source)
+	if err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+	result, err := p.Parse(raw, source)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	files := map[string]bool{}
+	for _, f := range result.Structure.FileTree {
+		files[filepath.ToSlash(f.Path)] = true
+	}
+	for _, path := range []string{"keep.tmp", "pkg/generated/types.go", "pkg/api.go", "other/api.pb.go"} {
+		if !files[path] {
+			t.Errorf("%s should be in the file tree", path)
+		}
+	}
+	for _, path := range []string{"test.tmp", "generated/out.go", "secrets/key.pem", "local.txt", "pkg/api.pb.go"} {
+		if files[path] {
+			t.Errorf("%s should be ignored", path)
+		}
+	}
+}
+
+func TestFetch_IgnoreFilesAboveSubdirectory(t *testing.T) {
+	repo := t.TempDir()
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(repo, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".git/info/exclude", "local.txt\n")
+	write(".gitignore", "*.log\n/services/api/generated\n")
+	write("services/.scignore", "fixtures/\n!keep.log\n")
+	write("services/api/main.go", "package main\n")
+	write("services/api/debug.log", "log")
+	write("services/api/keep.log", "kept")
+	write("services/api/local.txt", "mine")
+	write("services/api/generated/types.go", "package generated\n")
+	write("services/api/fixtures/user.json", "{}")
+
+	p := New()
+	source := instructions.SpecSource{Type: "codebase", Path: filepath.Join(repo, "services", "api")}
+	raw, err := p.Fetch(source)
+	if err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+	result, err := p.Parse(raw, source)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	files := map[string]bool{}
+	for _, f := range result.Structure.FileTree {
+		files[filepath.ToSlash(f.Path)] = true
+	}
+	for _, path := range []string{"main.go", "keep.log"} {
+		if !files[path] {
+			t.Errorf("%s should be in the file tree", path)
+		}
+	}
+	for _, path := range []string{"debug.log", "local.txt", "generated/types.go", "fixtures/user.json"} {
+		if files[path] {
+			t.Errorf("%s should be ignored by a rule above the scanned directory", path)
+		}
+	}
+}
+
 func TestParse_GoMod(t *testing.T) {
 	dir := setupTestDir(t)
 	p := New()
@@ -143,3 +286,67 @@ func TestParse_MaxFiles(t *testing.T) {
 		t.Errorf("got %d files, want at most 5 (max-files limit)", len(result.Structure.FileTree))
 	}
 }
+
+func TestParse_Routes(t *testing.T) {
+	p := New()
+	source := instructions.SpecSource{Type: "codebase", Path: filepath.Join("testdata", "routes")}
+	raw, err := p.Fetch(source)
+	if err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+	result, err := p.Parse(raw, source)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	ops := map[string]ir.Operation{}
+	for _, op := range result.Operations {
+		ops[op.Name] = op
+	}
+	tests := []struct {
+		route, framework, handler, source string
+	}{
+		{"GET /health", "gin", "health", "api/server.go:10"},
+		{"GET /v1/users/{id}", "gin", "getUser", "api/server.go:14"},
+		{"GET /v1/files/{path}", "gin", "", "api/server.go:16"},
+		{"GET /orders", "chi", "listOrders", "api/chi.go:12"},
+		{"DELETE /orders/{orderID}", "chi", "deleteOrder", "api/chi.go:13"},
+		{"GET /items/{id}", "net/http", "getItem", "api/mux.go:7"},
+		{"ANY /static/", "net/http", "http.FileServer", "api/mux.go:8"},
+		{"GET /widgets/{id}", "express", "getWidget", "web/app.js:5"},
+		{"POST /widgets", "express", "", "web/app.js:6"},
+		{"PUT /widgets/{id}/parts", "express", "replaceParts", "web/app.js:7"},
+		{"GET /v2/pets/{pet_id}", "fastapi", "read_pet", "py/main.py:7"},
+		{"POST /search", "fastapi", "search", "py/main.py:12"},
+		{"PUT /accounts/{account_id}", "flask", "update_account", "py/views.py:6"},
+		{"GET /accounts", "flask", "list_accounts", "py/views.py:11"},
+		{"GET /invoices", "spring", "list", "src/main/java/com/acme/InvoiceController.java:9"},
+		{"POST /invoices/{id}/void", "spring", "voidInvoice", "src/main/java/com/acme/InvoiceController.java:19"},
+	}
+	for _, tt := range tests {
+		op, ok := ops[tt.route]
+		if !ok {
+			t.Errorf("missing route %s", tt.route)
+			continue
+		}
+		if op.Extensions["x-framework"] != tt.framework || op.Extensions["x-handler"] != tt.handler || op.Extensions["x-source"] != tt.source {
+			t.Errorf("%s extensions = %v, want %s, %q at %s", tt.route, op.Extensions, tt.framework, tt.handler, tt.source)
+		}
+	}
+	if len(result.Operations) != 21 {
+		t.Errorf("got %d routes, want 21", len(result.Operations))
+	}
+	for name := range ops {
+		if strings.Contains(name, "only-in-tests") || strings.Contains(name, "example.com") || strings.Contains(name, "env") {
+			t.Errorf("unexpected route %s", name)
+		}
+	}
+
+	account := ops["PUT /accounts/{account_id}"]
+	if len(account.Parameters) != 1 || account.Parameters[0].In != "path" || account.Parameters[0].Type != "int" {
+		t.Errorf("account parameters = %+v, want an int path parameter", account.Parameters)
+	}
+	if len(result.Groups) != 7 || result.Groups[0].Name != "api/chi.go" {
+		t.Errorf("groups = %+v, want one per file", result.Groups)
+	}
+}
@@ -0,0 +1,222 @@
+package codebase
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ignoreFiles are read in every scanned directory, in order. .scignore
+// excludes files from skills without touching git's configuration, and
+// overrides .gitignore in the same directory.
+var ignoreFiles = []string{".gitignore", ".scignore"}
+
+// ignoreRule is one pattern of an ignore file.
+type ignoreRule struct {
+	base    string // directory of the ignore file, slash-separated and relative to the repository root; "" at the root
+	re      *regexp.Regexp
+	negate  bool // "!pattern" re-includes what an earlier pattern excluded
+	dirOnly bool // "pattern/" matches only directories
+}
+
+// ignoreMatcher applies gitignore rules: .git/info/exclude, the ignore
+// files of the directories between the repository root and the scanned
+// directory, then each directory's ignore files as the scan enters it. The
+// last matching rule wins, so deeper files override their parents.
+type ignoreMatcher struct {
+	prefix string // scanned directory relative to the repository root; "" when they are the same
+	rules  []ignoreRule
+}
+
+// newIgnoreMatcher starts a matcher for a scan of root. When root is inside
+// a git repository, it loads the repository's .git/info/exclude and the
+// ignore files from the repository root down to root's parent.
+func newIgnoreMatcher(root string) *ignoreMatcher {
+	m := &ignoreMatcher{}
+	repo := repoRoot(root)
+	if repo == "" {
+		return m
+	}
+	m.load(filepath.Join(repo, ".git", "info", "exclude"), "")
+	rel, err := filepath.Rel(repo, root)
+	if err != nil || rel == "." {
+		return m
+	}
+	m.prefix = filepath.ToSlash(rel)
+	dir := ""
+	for _, part := range strings.Split(m.prefix, "/") {
+		for _, name := range ignoreFiles {
+			m.load(filepath.Join(repo, filepath.FromSlash(dir), name), dir)
+		}
+		dir = path.Join(dir, part)
+	}
+	return m
+}
+
+// repoRoot returns the closest directory at or above dir that holds a .git
+// directory or file, or "" if there is none.
+func repoRoot(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// loadDir reads the ignore files of dir, relative to root.
+func (m *ignoreMatcher) loadDir(root, dir string) {
+	base := m.repoPath(dir)
+	for _, name := range ignoreFiles {
+		m.load(filepath.Join(root, dir, name), base)
+	}
+}
+
+// repoPath converts a path relative to the scanned directory into a
+// slash-separated path relative to the repository root.
+func (m *ignoreMatcher) repoPath(rel string) string {
+	rel = filepath.ToSlash(rel)
+	if rel == "." {
+		rel = ""
+	}
+	if m.prefix == "" {
+		return rel
+	}
+	return path.Join(m.prefix, rel)
+}
+
+func (m *ignoreMatcher) load(file, base string) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if rule, ok := parseIgnoreRule(line, base); ok {
+			m.rules = append(m.rules, rule)
+		}
+	}
+}
+
+// ignored reports whether rel, relative to the scanned directory, is
+// excluded.
+func (m *ignoreMatcher) ignored(rel string, isDir bool) bool {
+	rel = m.repoPath(rel)
+	ignored := false
+	for _, r := range m.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		name := rel
+		if r.base != "" {
+			if !strings.HasPrefix(rel, r.base+"/") {
+				continue
+			}
+			name = rel[len(r.base)+1:]
+		}
+		if r.re.MatchString(name) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+// parseIgnoreRule parses a line of an ignore file. Blank lines and
+// comments yield no rule.
+func parseIgnoreRule(line, base string) (ignoreRule, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	// trailing spaces are dropped unless escaped
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+	rule := ignoreRule{base: base}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+	// A slash anywhere but the end anchors the pattern to the ignore
+	// file's directory; otherwise it matches at any depth
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	re, err := regexp.Compile(ignorePatternRegexp(line, anchored))
+	if err != nil {
+		return ignoreRule{}, false
+	}
+	rule.re = re
+	return rule, true
+}
+
+// ignorePatternRegexp translates a gitignore glob: * and ? stay within a
+// path segment, ** crosses segments, and [...] is a character class.
+func ignorePatternRegexp(pattern string, anchored bool) string {
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if !strings.HasPrefix(pattern[i:], "**") {
+				b.WriteString("[^/]*")
+				continue
+			}
+			atStart := i == 0 || pattern[i-1] == '/'
+			i++
+			switch {
+			case atStart && i+1 < len(pattern) && pattern[i+1] == '/':
+				// "**/" matches zero or more directories
+				b.WriteString("(?:.*/)?")
+				i++
+			case atStart && i+1 == len(pattern):
+				// a trailing "/**" matches everything inside
+				b.WriteString(".*")
+			default:
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(pattern[i])))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
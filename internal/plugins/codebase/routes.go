@@ -0,0 +1,598 @@
+package codebase
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/roberthamel/skill-compiler/internal/ir"
+)
+
+// Route extraction: HTTP routes registered in source become operations.
+// Each framework's registrations are found with patterns, per file, so a
+// prefix is applied only when it is set in the same file as the route.
+
+// route is an HTTP route registered in a source file.
+type route struct {
+	method     string // upper-case, or ANY
+	path       string // parameters written {name}
+	paramTypes map[string]string
+	handler    string // as written at the registration, if named
+	line       int
+	framework  string
+}
+
+// routeExtractor finds the routes of the frameworks used in one language.
+type routeExtractor struct {
+	exts    []string
+	extract func(src string) []route
+}
+
+var routeExtractors = []routeExtractor{
+	{[]string{".go"}, goRoutes},
+	{[]string{".js", ".mjs", ".cjs", ".ts"}, expressRoutes},
+	{[]string{".py"}, pythonRoutes},
+	{[]string{".java", ".kt"}, springRoutes},
+}
+
+// extractRoutes scans the scanned source files for routes, returning an
+// operation per route and a group per file.
+func extractRoutes(root string, entries []fileInfo) ([]ir.Operation, []ir.Group) {
+	var ops []ir.Operation
+	var groups []ir.Group
+	usedIDs := make(map[string]bool)
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		if e.isDir || isTestFile(e.rel) {
+			continue
+		}
+		ext := strings.ToLower(filepath.Ext(e.rel))
+		i := slices.IndexFunc(routeExtractors, func(x routeExtractor) bool { return slices.Contains(x.exts, ext) })
+		if i < 0 {
+			continue
+		}
+		src := readFileContent(filepath.Join(root, e.rel), 1<<20)
+		if src == "" {
+			continue
+		}
+		file := filepath.ToSlash(e.rel)
+		group := ir.Group{Name: file}
+		for _, r := range routeExtractors[i].extract(src) {
+			key := r.method + " " + r.path
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			op := routeOperation(r, file, usedIDs)
+			ops = append(ops, op)
+			group.Operations = append(group.Operations, op.ID)
+		}
+		if len(group.Operations) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	return ops, groups
+}
+
+func routeOperation(r route, file string, usedIDs map[string]bool) ir.Operation {
+	if r.path == "" {
+		r.path = "/"
+	}
+	op := ir.Operation{
+		ID:     ir.UniqueID(ir.OperationID(r.method, r.path), usedIDs),
+		Name:   r.method + " " + r.path,
+		Method: r.method,
+		Path:   r.path,
+		Extensions: map[string]string{
+			"x-source":    fmt.Sprintf("%s:%d", file, r.line),
+			"x-framework": r.framework,
+		},
+	}
+	if r.handler != "" {
+		op.Extensions["x-handler"] = r.handler
+	}
+	for _, m := range pathParamRe.FindAllStringSubmatch(r.path, -1) {
+		typ := r.paramTypes[m[1]]
+		if typ == "" {
+			typ = "string"
+		}
+		op.Parameters = append(op.Parameters, ir.Parameter{Name: m[1], In: "path", Required: true, Type: typ})
+	}
+	return op
+}
+
+// isTestFile reports whether a source file holds tests or test data, whose
+// routes are fixtures rather than the API.
+func isTestFile(rel string) bool {
+	base := strings.ToLower(filepath.Base(rel))
+	return strings.HasSuffix(base, "_test.go") || strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") ||
+		strings.HasPrefix(base, "test_") || strings.HasSuffix(base, "_test.py") ||
+		strings.Contains("/"+filepath.ToSlash(rel), "/src/test/") || strings.Contains("/"+filepath.ToSlash(rel), "/testdata/")
+}
+
+var (
+	// Matches a path parameter, e.g. "{id}" or "{id:[0-9]+}"
+	pathParamRe = regexp.MustCompile(`\{(\w+)[^}]*\}`)
+	// Matches :id and *path parameters (gin, echo, Express)
+	colonParamRe = regexp.MustCompile(`[:*](\w+)\??`)
+	// Matches {name:regex} and {name...} parameters (chi, gorilla, net/http, Spring)
+	bracedParamRe = regexp.MustCompile(`\{(\w+)(?::[^}]*|\.\.\.)?\}`)
+)
+
+// normalizePath writes a route's parameters as {name}.
+func normalizePath(path string) string {
+	path = bracedParamRe.ReplaceAllString(path, "{$1}")
+	return colonParamRe.ReplaceAllString(path, "{$1}")
+}
+
+// joinRoute joins a prefix and a route path.
+func joinRoute(prefix, path string) string {
+	switch {
+	case prefix == "":
+		return path
+	case path == "" || path == "/":
+		return prefix
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+// lineAt returns the line number of offset in src.
+func lineAt(src string, offset int) int {
+	return strings.Count(src[:offset], "\n") + 1
+}
+
+// handlerName returns a handler as written, or "" for an inline function.
+func handlerName(expr string) string {
+	expr = strings.TrimSpace(expr)
+	if expr == "" || expr == "func" || expr == "function" || expr == "async" || !identRe.MatchString(expr) {
+		return ""
+	}
+	return expr
+}
+
+var identRe = regexp.MustCompile(`^[A-Za-z_$][\w$.]*$`)
+
+// Go: net/http, gorilla/mux, chi, gin and echo.
+
+// goFrameworks are recognized by import path, most specific first.
+var goFrameworks = []struct{ importPath, name string }{
+	{"github.com/go-chi/chi", "chi"},
+	{"github.com/gin-gonic/gin", "gin"},
+	{"github.com/labstack/echo", "echo"},
+	{"github.com/gorilla/mux", "gorilla"},
+	{"net/http", "net/http"},
+}
+
+var (
+	// Matches r.GET("/path", handler) (gin, echo) and r.Get (chi), also
+	// after chi's r.With(middleware)
+	goMethodRe = regexp.MustCompile(`\b(\w+)(?:\.With\([^)]*\))?\.(GET|POST|PUT|PATCH|DELETE|HEAD|OPTIONS|Any|Get|Post|Put|Patch|Delete|Head|Options|Connect|Trace)\(\s*"([^"]*)"\s*(?:,\s*([\w.]+))?`)
+	// Matches mux.HandleFunc("GET /path", handler), r.Handle and chi's
+	// r.Method("GET", "/path", handler)
+	goHandleRe = regexp.MustCompile(`\b(\w+)\.(?:HandleFunc|Handle|Method|MethodFunc)\(\s*(?:"([A-Z]+)"\s*,\s*)?"([^"]*)"\s*(?:,\s*([\w.]+))?`)
+	// Matches gorilla's .Methods("GET", "POST")
+	goMethodsRe = regexp.MustCompile(`^[^\n]*?\.Methods\(([^)]*)\)`)
+	// Matches v1 := r.Group("/v1") (gin, echo)
+	goGroupRe = regexp.MustCompile(`\b(\w+)\s*:?=\s*(\w+)\.Group\(\s*"([^"]*)"`)
+	// Matches chi's r.Route("/users", func(r chi.Router) {
+	goRouteBlockRe = regexp.MustCompile(`\b(\w+)\.Route\(\s*"([^"]*)"\s*,\s*func\(\s*(\w+)[^{]*\{`)
+	// Matches a quoted string
+	quotedRe = regexp.MustCompile(`"([^"]*)"|'([^']*)'`)
+)
+
+// routeScope is a block, like chi's Route callback, inside which a router
+// variable has a prefix.
+type routeScope struct {
+	router     string
+	prefix     string
+	start, end int
+}
+
+// routePrefixes tracks the path prefixes of router variables in a file.
+type routePrefixes struct {
+	vars   map[string]string
+	scopes []routeScope
+}
+
+// at returns the prefix of router at offset: the innermost enclosing
+// scope's, else the variable's.
+func (p *routePrefixes) at(router string, offset int) string {
+	for i := len(p.scopes) - 1; i >= 0; i-- {
+		s := p.scopes[i]
+		if s.router == router && offset >= s.start && offset < s.end {
+			return s.prefix
+		}
+	}
+	return p.vars[router]
+}
+
+func goRoutes(src string) []route {
+	framework := ""
+	for _, f := range goFrameworks {
+		if strings.Contains(src, `"`+f.importPath) {
+			framework = f.name
+			break
+		}
+	}
+	if framework == "" {
+		return nil
+	}
+
+	prefixes := &routePrefixes{vars: make(map[string]string)}
+	for _, m := range goGroupRe.FindAllStringSubmatchIndex(src, -1) {
+		parent := src[m[4]:m[5]]
+		prefixes.vars[src[m[2]:m[3]]] = joinRoute(prefixes.at(parent, m[0]), src[m[6]:m[7]])
+	}
+	for _, m := range goRouteBlockRe.FindAllStringSubmatchIndex(src, -1) {
+		outer := src[m[2]:m[3]]
+		prefixes.scopes = append(prefixes.scopes, routeScope{
+			router: src[m[6]:m[7]],
+			prefix: joinRoute(prefixes.at(outer, m[0]), src[m[4]:m[5]]),
+			start:  m[1],
+			end:    m[1] + blockEnd(src[m[1]:]),
+		})
+	}
+
+	type found struct {
+		offset int
+		route
+	}
+	var routes []found
+	add := func(offset int, router, method, path, handler string) {
+		prefix := prefixes.at(router, offset)
+		if !strings.HasPrefix(path, "/") && prefix == "" {
+			return
+		}
+		method = strings.ToUpper(method)
+		if method == "" {
+			method = "ANY"
+		}
+		routes = append(routes, found{offset, route{
+			method:    method,
+			path:      normalizePath(joinRoute(prefix, path)),
+			handler:   handlerName(handler),
+			line:      lineAt(src, offset),
+			framework: framework,
+		}})
+	}
+
+	if framework != "net/http" && framework != "gorilla" {
+		for _, m := range goMethodRe.FindAllStringSubmatchIndex(src, -1) {
+			router := src[m[2]:m[3]]
+			if router == "http" {
+				// http.Get is a client call
+				continue
+			}
+			add(m[0], router, src[m[4]:m[5]], src[m[6]:m[7]], submatch(src, m, 4))
+		}
+	}
+	for _, m := range goHandleRe.FindAllStringSubmatchIndex(src, -1) {
+		router, method, path := src[m[2]:m[3]], submatch(src, m, 2), src[m[6]:m[7]]
+		// net/http patterns may lead with the method: "GET /users/{id}"
+		if before, after, ok := strings.Cut(path, " "); ok && method == "" {
+			method, path = before, strings.TrimSpace(after)
+		}
+		if mm := goMethodsRe.FindStringSubmatch(src[m[1]:]); mm != nil && method == "" {
+			for _, q := range quotedRe.FindAllStringSubmatch(mm[1], -1) {
+				add(m[0], router, q[1], path, submatch(src, m, 4))
+			}
+			continue
+		}
+		add(m[0], router, method, path, submatch(src, m, 4))
+	}
+
+	slices.SortStableFunc(routes, func(a, b found) int { return a.offset - b.offset })
+	result := make([]route, len(routes))
+	for i, r := range routes {
+		result[i] = r.route
+	}
+	return result
+}
+
+// submatch returns the nth group of an index match, or "" if it did not
+// participate.
+func submatch(src string, m []int, n int) string {
+	if m[2*n] < 0 {
+		return ""
+	}
+	return src[m[2*n]:m[2*n+1]]
+}
+
+// blockEnd returns the offset of the brace closing a block whose body src
+// starts, or len(src) if it is never closed.
+func blockEnd(src string) int {
+	depth := 1
+	for i, c := range src {
+		switch c {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(src)
+}
+
+// JavaScript and TypeScript: Express.
+
+var (
+	// Matches app.get('/path', ..., handler)
+	expressRouteRe = regexp.MustCompile("\\b(\\w+)\\.(get|post|put|patch|delete|head|options|all)\\(\\s*['\"`]([^'\"`]*)['\"`]\\s*,([^\\n]*)")
+	// Matches router.route('/path'), whose methods are chained
+	expressChainRe = regexp.MustCompile("\\b(\\w+)\\.route\\(\\s*['\"`]([^'\"`]*)['\"`]\\s*\\)")
+	// Matches one chained method: .get(handler)
+	expressChainedRe = regexp.MustCompile(`^\s*\.(get|post|put|patch|delete|head|options|all)\(\s*([\w$.]*)`)
+	// Matches app.use('/prefix', router)
+	expressUseRe = regexp.MustCompile("\\b\\w+\\.use\\(\\s*['\"`]([^'\"`]*)['\"`]\\s*,\\s*(\\w+)\\s*\\)")
+	// Matches an import of express
+	expressImportRe = regexp.MustCompile(`require\(\s*['"]express['"]\s*\)|from\s+['"]express['"]`)
+)
+
+func expressRoutes(src string) []route {
+	if !expressImportRe.MatchString(src) {
+		return nil
+	}
+	prefixes := make(map[string]string)
+	for _, m := range expressUseRe.FindAllStringSubmatch(src, -1) {
+		prefixes[m[2]] = m[1]
+	}
+
+	type found struct {
+		offset int
+		route
+	}
+	var routes []found
+	add := func(offset int, router, method, path, handler string) {
+		if method == "all" {
+			method = "any"
+		}
+		routes = append(routes, found{offset, route{
+			method:    strings.ToUpper(method),
+			path:      normalizePath(joinRoute(prefixes[router], path)),
+			handler:   handlerName(handler),
+			line:      lineAt(src, offset),
+			framework: "express",
+		}})
+	}
+
+	for _, m := range expressRouteRe.FindAllStringSubmatchIndex(src, -1) {
+		path := src[m[6]:m[7]]
+		if !strings.HasPrefix(path, "/") && path != "*" {
+			continue
+		}
+		// the handler is the last argument; middleware comes before it
+		args := strings.TrimSpace(src[m[8]:m[9]])
+		args = strings.TrimSuffix(strings.TrimSuffix(args, ";"), ")")
+		parts := strings.Split(args, ",")
+		add(m[0], src[m[2]:m[3]], src[m[4]:m[5]], path, parts[len(parts)-1])
+	}
+	for _, m := range expressChainRe.FindAllStringSubmatchIndex(src, -1) {
+		rest := src[m[1]:]
+		for {
+			c := expressChainedRe.FindStringSubmatchIndex(rest)
+			if c == nil {
+				break
+			}
+			add(m[0], src[m[2]:m[3]], rest[c[2]:c[3]], src[m[4]:m[5]], rest[c[4]:c[5]])
+			// skip to the end of the call's arguments
+			end := c[1] + blockEndParen(rest[c[1]:])
+			if end >= len(rest) {
+				break
+			}
+			rest = rest[end+1:]
+		}
+	}
+
+	slices.SortStableFunc(routes, func(a, b found) int { return a.offset - b.offset })
+	result := make([]route, len(routes))
+	for i, r := range routes {
+		result[i] = r.route
+	}
+	return result
+}
+
+// blockEndParen returns the offset of the parenthesis closing a call whose
+// arguments src starts, or len(src) if it is never closed.
+func blockEndParen(src string) int {
+	depth := 1
+	for i, c := range src {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(src)
+}
+
+// Python: FastAPI and Flask.
+
+var (
+	// Matches @app.get("/path") (FastAPI, Flask 2) and @bp.route("/path")
+	pyDecoratorRe = regexp.MustCompile(`(?m)^[ \t]*@(\w+)\.(get|post|put|patch|delete|head|options|route|api_route)\(\s*(?:path\s*=\s*|rule\s*=\s*)?['"]([^'"]*)['"]`)
+	// Matches the decorated function
+	pyDefRe = regexp.MustCompile(`(?m)^[ \t]*(?:async\s+)?def\s+(\w+)`)
+	// Matches methods=["GET", "POST"]
+	pyMethodsRe = regexp.MustCompile(`methods\s*=\s*[\[(]([^\])]*)[\])]`)
+	// Matches router = APIRouter(prefix="/users") or bp = Blueprint(..., url_prefix="/users")
+	pyRouterRe = regexp.MustCompile(`\b(\w+)\s*=\s*(?:APIRouter|Blueprint)\(([^)]*)\)`)
+	// Matches app.include_router(router, prefix="/v1") and app.register_blueprint(bp, url_prefix="/v1")
+	pyIncludeRe = regexp.MustCompile(`\.(?:include_router|register_blueprint)\(\s*(\w+)\s*,([^)]*)\)`)
+	// Matches a prefix= or url_prefix= argument
+	pyPrefixArgRe = regexp.MustCompile(`\b(?:url_)?prefix\s*=\s*['"]([^'"]*)['"]`)
+	// Matches a Flask parameter like <int:id>
+	flaskParamRe = regexp.MustCompile(`<(?:(\w+):)?(\w+)>`)
+)
+
+// flaskConverters maps Flask's path converters to Go types.
+var flaskConverters = map[string]string{"int": "int", "float": "float"}
+
+func pythonRoutes(src string) []route {
+	framework := ""
+	switch {
+	case strings.Contains(src, "fastapi"):
+		framework = "fastapi"
+	case strings.Contains(src, "flask"):
+		framework = "flask"
+	default:
+		return nil
+	}
+
+	prefixes := make(map[string]string)
+	for _, m := range pyRouterRe.FindAllStringSubmatch(src, -1) {
+		if p := pyPrefixArgRe.FindStringSubmatch(m[2]); p != nil {
+			prefixes[m[1]] = p[1]
+		}
+	}
+	for _, m := range pyIncludeRe.FindAllStringSubmatch(src, -1) {
+		if p := pyPrefixArgRe.FindStringSubmatch(m[2]); p != nil {
+			prefixes[m[1]] = joinRoute(p[1], prefixes[m[1]])
+		}
+	}
+
+	var routes []route
+	for _, m := range pyDecoratorRe.FindAllStringSubmatchIndex(src, -1) {
+		router, kind, path := src[m[2]:m[3]], src[m[4]:m[5]], src[m[6]:m[7]]
+		handler, rest := "", src[m[1]:]
+		if d := pyDefRe.FindStringSubmatchIndex(rest); d != nil {
+			handler = rest[d[2]:d[3]]
+			rest = rest[:d[0]]
+		}
+
+		types := make(map[string]string)
+		path = flaskParamRe.ReplaceAllStringFunc(path, func(p string) string {
+			sm := flaskParamRe.FindStringSubmatch(p)
+			if t, ok := flaskConverters[sm[1]]; ok {
+				types[sm[2]] = t
+			}
+			return "{" + sm[2] + "}"
+		})
+		path = normalizePath(joinRoute(prefixes[router], path))
+
+		methods := []string{strings.ToUpper(kind)}
+		if kind == "route" || kind == "api_route" {
+			methods = []string{"GET"}
+			if mm := pyMethodsRe.FindStringSubmatch(rest); mm != nil {
+				methods = nil
+				for _, q := range quotedRe.FindAllStringSubmatch(mm[1], -1) {
+					methods = append(methods, strings.ToUpper(q[1]+q[2]))
+				}
+			}
+		}
+		for _, method := range methods {
+			routes = append(routes, route{
+				method:     method,
+				path:       path,
+				paramTypes: types,
+				handler:    handler,
+				line:       lineAt(src, m[0]),
+				framework:  framework,
+			})
+		}
+	}
+	return routes
+}
+
+// Java and Kotlin: Spring's mapping annotations.
+
+var (
+	// Matches @GetMapping("/path"), @RequestMapping(value = "/path", method = RequestMethod.POST)
+	springMappingRe = regexp.MustCompile(`^@(Get|Post|Put|Patch|Delete|Request)Mapping\b(?:\((.*)\))?`)
+	// Matches attributes other than the path, whose strings are not paths
+	springOtherAttrRe = regexp.MustCompile(`\b(?:produces|consumes|params|headers|name)\s*=\s*(?:\{[^}]*\}|\[[^\]]*\]|"[^"]*"|[\w.]+)`)
+	// Matches a RequestMethod constant
+	springMethodRe = regexp.MustCompile(`RequestMethod\.(\w+)`)
+	// Matches the annotated method's name
+	springHandlerRe = regexp.MustCompile(`(\w+)\s*\(`)
+)
+
+// springMapping is a mapping annotation waiting for the declaration it
+// annotates.
+type springMapping struct {
+	methods []string
+	paths   []string
+	line    int
+}
+
+func springRoutes(src string) []route {
+	if !strings.Contains(src, "Mapping") {
+		return nil
+	}
+	var routes []route
+	var classPrefixes []string
+	var pending *springMapping
+	for i, line := range strings.Split(src, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "//") || strings.HasPrefix(line, "*") || strings.HasPrefix(line, "/*") {
+			continue
+		}
+		if strings.HasPrefix(line, "@") {
+			if m := springMappingRe.FindStringSubmatch(line); m != nil {
+				pending = parseSpringMapping(m[1], m[2], i+1)
+			}
+			continue
+		}
+		if pending == nil {
+			continue
+		}
+		if strings.Contains(" "+line, " class ") || strings.Contains(" "+line, " interface ") {
+			// a class-level mapping prefixes the class's routes
+			classPrefixes = pending.paths
+			pending = nil
+			continue
+		}
+		handler := ""
+		if m := springHandlerRe.FindStringSubmatch(line); m != nil {
+			handler = m[1]
+		}
+		prefixes := classPrefixes
+		if len(prefixes) == 0 {
+			prefixes = []string{""}
+		}
+		for _, prefix := range prefixes {
+			for _, path := range pending.paths {
+				for _, method := range pending.methods {
+					routes = append(routes, route{
+						method:    method,
+						path:      normalizePath(joinRoute(prefix, path)),
+						handler:   handler,
+						line:      pending.line,
+						framework: "spring",
+					})
+				}
+			}
+		}
+		pending = nil
+	}
+	return routes
+}
+
+func parseSpringMapping(kind, args string, line int) *springMapping {
+	m := &springMapping{line: line}
+	if kind == "Request" {
+		for _, sm := range springMethodRe.FindAllStringSubmatch(args, -1) {
+			m.methods = append(m.methods, strings.ToUpper(sm[1]))
+		}
+		if len(m.methods) == 0 {
+			m.methods = []string{"ANY"}
+		}
+	} else {
+		m.methods = []string{strings.ToUpper(kind)}
+	}
+	for _, q := range quotedRe.FindAllStringSubmatch(springOtherAttrRe.ReplaceAllString(args, ""), -1) {
+		m.paths = append(m.paths, q[1])
+	}
+	if len(m.paths) == 0 {
+		m.paths = []string{""}
+	}
+	return m
+}
@@ -0,0 +1,17 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func Routes() http.Handler {
+	r := chi.NewRouter()
+	r.Route("/orders", func(r chi.Router) {
+		r.Get("/", listOrders)
+		r.With(auth).Delete("/{orderID:[0-9]+}", deleteOrder)
+	})
+	r.Get("/ping", ping)
+	return r
+}
@@ -0,0 +1,10 @@
+package api
+
+import "net/http"
+
+func Mux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /items/{id}", getItem)
+	mux.Handle("/static/", http.FileServer(http.Dir(".")))
+	return mux
+}
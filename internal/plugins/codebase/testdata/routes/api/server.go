@@ -0,0 +1,21 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Register(r *gin.Engine) {
+	r.GET("/health", health)
+
+	v1 := r.Group("/v1")
+	{
+		v1.GET("/users/:id", getUser)
+		v1.POST("/users", createUser)
+		v1.GET("/files/*path", func(c *gin.Context) {})
+	}
+
+	// a client call, not a route
+	_, _ = http.Get("https://example.com/status")
+}
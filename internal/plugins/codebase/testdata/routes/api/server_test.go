@@ -0,0 +1,7 @@
+package api
+
+import "github.com/gin-gonic/gin"
+
+func setup(r *gin.Engine) {
+	r.GET("/only-in-tests", nil)
+}
@@ -0,0 +1,17 @@
+from fastapi import APIRouter, FastAPI
+
+app = FastAPI()
+router = APIRouter(prefix="/pets")
+
+
+@router.get("/{pet_id}")
+async def read_pet(pet_id: int):
+    return {}
+
+
+@app.api_route("/search", methods=["GET", "POST"])
+def search():
+    return []
+
+
+app.include_router(router, prefix="/v2")
@@ -0,0 +1,13 @@
+from flask import Blueprint
+
+bp = Blueprint("accounts", __name__, url_prefix="/accounts")
+
+
+@bp.route("/<int:account_id>", methods=["PUT"])
+def update_account(account_id):
+    return ""
+
+
+@bp.route("/")
+def list_accounts():
+    return ""
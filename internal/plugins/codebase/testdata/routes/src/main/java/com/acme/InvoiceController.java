@@ -0,0 +1,23 @@
+package com.acme;
+
+import org.springframework.web.bind.annotation.*;
+
+@RestController
+@RequestMapping("/invoices")
+public class InvoiceController {
+
+    @GetMapping
+    public List<Invoice> list() {
+        return service.list();
+    }
+
+    @GetMapping(value = "/{id}", produces = "application/json")
+    public Invoice get(@PathVariable Long id) {
+        return service.get(id);
+    }
+
+    @RequestMapping(path = "/{id}/void", method = RequestMethod.POST)
+    public void voidInvoice(@PathVariable Long id) {
+        service.voidInvoice(id);
+    }
+}
@@ -0,0 +1,13 @@
+const express = require('express');
+const app = express();
+const router = express.Router();
+
+router.get('/:id', auth, getWidget);
+router.post('/', (req, res) => res.sendStatus(201));
+router.route('/:id/parts')
+  .get(listParts)
+  .put(replaceParts);
+
+app.use('/widgets', router);
+app.get('env');
+app.listen(3000);
@@ -0,0 +1,515 @@
+package dts
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/roberthamel/skill-compiler/internal/ir"
+)
+
+// converter builds the IR from the declarations of each file in turn.
+type converter struct {
+	result    *ir.IntermediateRepr
+	ops       map[string]int // operation ID -> index, for merging overloads
+	types     map[string]int // type name -> index, for declaration merging
+	groups    map[string]int // group name -> index
+	constants []string       // exported non-function variables, as name: type
+}
+
+func newConverter(result *ir.IntermediateRepr) *converter {
+	return &converter{
+		result: result,
+		ops:    make(map[string]int),
+		types:  make(map[string]int),
+		groups: make(map[string]int),
+	}
+}
+
+func (c *converter) addFile(module, fileDoc string, decls []*decl) {
+	if fileDoc != "" {
+		if g := c.group(module); g.Description == "" {
+			g.Description = fileDoc
+		}
+	}
+	for _, d := range decls {
+		switch d.kind {
+		case "function":
+			c.addOperation(ir.Operation{
+				ID:          d.name,
+				Name:        d.name,
+				Description: d.doc.description(),
+				Method:      "FUNC",
+				Path:        d.name,
+				Deprecated:  d.doc.deprecated,
+				Signature:   d.signature,
+				Parameters:  parameters(d.typeParams, d.params, d.doc),
+				Responses:   returns(d.returns, d.doc),
+			}, d.module)
+		case "variable":
+			typ := d.typ
+			if typ == "" {
+				typ = "any"
+			}
+			c.constants = append(c.constants, d.name+": "+typ)
+		case "class", "interface":
+			c.classOrInterface(d)
+		case "type":
+			c.typeAlias(d)
+		case "enum":
+			c.enum(d)
+		}
+	}
+}
+
+// finish records what only makes sense once every file is converted.
+func (c *converter) finish() {
+	if len(c.constants) > 0 {
+		c.result.Metadata["constants"] = strings.Join(c.constants, "; ")
+	}
+}
+
+func (c *converter) group(name string) *ir.Group {
+	i, ok := c.groups[name]
+	if !ok {
+		i = len(c.result.Groups)
+		c.groups[name] = i
+		c.result.Groups = append(c.result.Groups, ir.Group{Name: name})
+	}
+	return &c.result.Groups[i]
+}
+
+// addOperation adds op to group. Overloads of a function or method merge
+// into one operation listing every signature, with parameters only some
+// overloads take made optional.
+func (c *converter) addOperation(op ir.Operation, group string) {
+	if i, ok := c.ops[op.ID]; ok {
+		existing := &c.result.Operations[i]
+		if existing.Tags[0] == group {
+			existing.Signature += "\n" + op.Signature
+			if existing.Description == "" {
+				existing.Description = op.Description
+			}
+			for _, param := range op.Parameters {
+				if !hasParameter(existing.Parameters, param.Name) {
+					param.Required = false
+					existing.Parameters = append(existing.Parameters, param)
+				}
+			}
+			if len(existing.Responses) == 0 {
+				existing.Responses = op.Responses
+			}
+			return
+		}
+		// Another module exports the same name
+		op.ID = group + "." + op.ID
+	}
+	op.Tags = []string{group}
+	c.ops[op.ID] = len(c.result.Operations)
+	c.result.Operations = append(c.result.Operations, op)
+	g := c.group(group)
+	g.Operations = append(g.Operations, op.ID)
+}
+
+func hasParameter(params []ir.Parameter, name string) bool {
+	for _, p := range params {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// classOrInterface converts a class or interface into a type with its
+// properties as fields, and its constructor and methods into operations
+// grouped under the type. Optional interface methods are usually callbacks
+// the caller supplies, so they stay fields.
+func (c *converter) classOrInterface(d *decl) {
+	td := ir.TypeDef{
+		Name:        d.name,
+		Kind:        d.kind,
+		Description: d.doc.description(),
+	}
+	for _, h := range d.heritage {
+		td.Extends = append(td.Extends, baseName(h))
+	}
+	if d.abstract {
+		td.Description = ir.JoinSentence(td.Description, "Abstract: instantiate a subclass.")
+	}
+	td.Description = ir.JoinSentence(td.Description, typeParamsNote(d.typeParams))
+
+	var fields []member
+	for _, m := range d.members {
+		switch {
+		case m.kind == "constructor":
+			c.addOperation(ir.Operation{
+				ID:          d.name + ".constructor",
+				Name:        "new " + d.name,
+				Description: m.doc.description(),
+				Method:      "CONSTRUCTOR",
+				Path:        "new " + d.name,
+				Deprecated:  m.doc.deprecated,
+				Signature:   "new " + d.name + strings.TrimPrefix(m.signature, "constructor"),
+				Parameters:  parameters(m.typeParams, m.params, m.doc),
+				Responses:   []ir.Response{{StatusCode: "return", Body: &ir.TypeRef{TypeName: d.name}}},
+			}, d.name)
+		case m.kind == "method" && (d.kind == "class" || !m.optional):
+			c.addOperation(ir.Operation{
+				ID:          d.name + "." + m.name,
+				Name:        d.name + "." + m.name,
+				Description: m.doc.description(),
+				Method:      "METHOD",
+				Path:        d.name + "." + m.name,
+				Deprecated:  m.doc.deprecated,
+				Signature:   m.signature,
+				Parameters:  parameters(m.typeParams, m.params, m.doc),
+				Responses:   returns(m.returns, m.doc),
+			}, d.name)
+		case m.kind == "call":
+			td.Description = ir.JoinSentence(td.Description, "Callable as "+functionType(m)+".")
+		case m.kind == "construct":
+			td.Description = ir.JoinSentence(td.Description, "Constructible as new "+functionType(m)+".")
+		default:
+			fields = append(fields, m)
+		}
+	}
+	td.Fields = typeFields(fields)
+	c.addType(td)
+	if i, ok := c.groups[d.name]; ok && c.result.Groups[i].Description == "" {
+		c.result.Groups[i].Description = d.doc.text
+	}
+}
+
+// typeAlias converts a type alias: object literal types list their fields,
+// unions of literals become enums, unions of named types become unions, and
+// anything else is recorded as an alias.
+func (c *converter) typeAlias(d *decl) {
+	td := ir.TypeDef{
+		Name:        d.name,
+		Description: d.doc.description(),
+	}
+	variants := splitTop(strings.TrimPrefix(strings.TrimSpace(d.typ), "|"), '|')
+	switch {
+	case len(d.members) > 0:
+		td.Kind = "object"
+		td.Fields = typeFields(d.members)
+	case len(variants) > 1 && allLiterals(variants):
+		td.Kind = "enum"
+		for _, v := range variants {
+			td.Enum = append(td.Enum, unquote(v))
+		}
+	case len(variants) > 1 && allNamed(variants):
+		td.Kind = "union"
+		td.OneOf = variants
+	default:
+		td.Kind = "alias"
+		if isFunctionType(d.typ) {
+			td.Kind = "func"
+		}
+		td.Description = ir.JoinSentence(td.Description, "Alias for "+d.typ+".")
+	}
+	td.Description = ir.JoinSentence(td.Description, typeParamsNote(d.typeParams))
+	c.addType(td)
+}
+
+// enum converts an enum: its values are referenced as Enum.Member, and each
+// member's value is recorded, counting up from the last numeric one.
+func (c *converter) enum(d *decl) {
+	td := ir.TypeDef{
+		Name:        d.name,
+		Kind:        "enum",
+		Description: d.doc.description(),
+	}
+	next := 0
+	numeric := true
+	for _, m := range d.members {
+		td.Enum = append(td.Enum, d.name+"."+m.name)
+		f := ir.TypeField{Name: m.name, Type: "number", Description: m.doc.description()}
+		switch {
+		case m.typ == "":
+			if numeric {
+				f.Const = strconv.Itoa(next)
+				next++
+			}
+		case isStringLiteral(m.typ):
+			f.Type = "string"
+			f.Const = jsonValue(m.typ)
+			numeric = false
+		default:
+			if n, err := strconv.Atoi(m.typ); err == nil {
+				f.Const = m.typ
+				next, numeric = n+1, true
+			} else {
+				// computed from other members
+				f.Description = ir.JoinSentence(f.Description, "Value: "+m.typ+".")
+				numeric = false
+			}
+		}
+		td.Fields = append(td.Fields, f)
+	}
+	c.addType(td)
+}
+
+// addType adds td, merging it into an earlier declaration of the same name
+// (interfaces may be declared several times).
+func (c *converter) addType(td ir.TypeDef) {
+	i, ok := c.types[td.Name]
+	if !ok {
+		c.types[td.Name] = len(c.result.Types)
+		c.result.Types = append(c.result.Types, td)
+		return
+	}
+	existing := &c.result.Types[i]
+	if existing.Description == "" {
+		existing.Description = td.Description
+	}
+	existing.Extends = append(existing.Extends, td.Extends...)
+	for _, f := range td.Fields {
+		dup := false
+		for _, e := range existing.Fields {
+			dup = dup || e.Name == f.Name
+		}
+		if !dup {
+			existing.Fields = append(existing.Fields, f)
+		}
+	}
+}
+
+// typeFields converts properties, index signatures and optional methods.
+// A get accessor is read-only unless a set accessor accompanies it.
+func typeFields(members []member) []ir.TypeField {
+	var fields []ir.TypeField
+	readonly := make(map[string]bool)
+	index := make(map[string]int)
+	for _, m := range members {
+		if i, ok := index[m.name]; ok {
+			if m.setter {
+				readonly[m.name] = false
+			}
+			if fields[i].Type == "" {
+				fields[i].Type = m.typ
+			}
+			continue
+		}
+		f := ir.TypeField{
+			Name:        m.name,
+			Type:        m.typ,
+			Description: m.doc.description(),
+			Required:    !m.optional,
+			Default:     jsonValue(m.doc.defaultValue),
+		}
+		if m.kind == "method" {
+			f.Type = functionType(m)
+		}
+		if m.kind == "index" {
+			f.Required = false
+		}
+		if m.static {
+			f.Description = ir.JoinSentence("Static.", f.Description)
+		}
+		index[m.name] = len(fields)
+		readonly[m.name] = m.readonly
+		fields = append(fields, f)
+	}
+	for i := range fields {
+		if fields[i].Type == "" {
+			fields[i].Type = "any"
+		}
+		if readonly[fields[i].Name] {
+			fields[i].Description = ir.JoinSentence(fields[i].Description, "Read-only.")
+		}
+	}
+	return fields
+}
+
+func parameters(tps []typeParam, params []param, doc jsDoc) []ir.Parameter {
+	var out []ir.Parameter
+	for _, tp := range tps {
+		out = append(out, ir.Parameter{
+			Name:     tp.name,
+			In:       "type",
+			Required: tp.def == "",
+			Type:     tp.constraint,
+			Default:  tp.def,
+		})
+	}
+	for i, pr := range params {
+		name := pr.name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		typ := pr.typ
+		if typ == "" {
+			typ = "any"
+		}
+		if pr.rest {
+			typ = "..." + typ
+		}
+		out = append(out, ir.Parameter{
+			Name:        name,
+			In:          "argument",
+			Description: doc.params[name],
+			Required:    !pr.optional && !pr.rest,
+			Type:        typ,
+		})
+	}
+	return out
+}
+
+func returns(ret string, doc jsDoc) []ir.Response {
+	if (ret == "" || ret == "void") && doc.returns == "" {
+		return nil
+	}
+	resp := ir.Response{StatusCode: "return", Description: doc.returns}
+	if ret != "" {
+		resp.Body = &ir.TypeRef{TypeName: ret}
+	}
+	return []ir.Response{resp}
+}
+
+// functionType renders a method or call signature as a function type.
+func functionType(m member) string {
+	var params []string
+	for i, pr := range m.params {
+		name := pr.name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		if pr.rest {
+			name = "..." + name
+		}
+		if pr.optional {
+			name += "?"
+		}
+		typ := pr.typ
+		if typ == "" {
+			typ = "any"
+		}
+		params = append(params, name+": "+typ)
+	}
+	ret := m.returns
+	if ret == "" {
+		ret = "any"
+	}
+	var tps string
+	if len(m.typeParams) > 0 {
+		var names []string
+		for _, tp := range m.typeParams {
+			names = append(names, tp.String())
+		}
+		tps = "<" + strings.Join(names, ", ") + ">"
+	}
+	return tps + "(" + strings.Join(params, ", ") + ") => " + ret
+}
+
+func typeParamsNote(tps []typeParam) string {
+	if len(tps) == 0 {
+		return ""
+	}
+	names := make([]string, len(tps))
+	for i, tp := range tps {
+		names[i] = tp.String()
+	}
+	return "Type parameters: <" + strings.Join(names, ", ") + ">."
+}
+
+// baseName strips the type arguments of a heritage clause (Base<T>).
+func baseName(t string) string {
+	if i := strings.Index(t, "<"); i >= 0 {
+		return strings.TrimSpace(t[:i])
+	}
+	return t
+}
+
+// splitTop splits a type expression at sep outside brackets and strings.
+func splitTop(s string, sep byte) []string {
+	var parts []string
+	depth := 0
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == '(' || c == '[' || c == '{' || c == '<':
+			depth++
+		case c == ')' || c == ']' || c == '}' || c == '>':
+			if !(c == '>' && i > 0 && s[i-1] == '=') {
+				depth--
+			}
+		case c == sep && depth == 0:
+			parts = append(parts, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if rest := strings.TrimSpace(s[start:]); rest != "" || len(parts) > 0 {
+		parts = append(parts, rest)
+	}
+	return parts
+}
+
+func isStringLiteral(s string) bool {
+	return len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0]
+}
+
+func allLiterals(variants []string) bool {
+	for _, v := range variants {
+		if _, err := strconv.ParseFloat(v, 64); err != nil && !isStringLiteral(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// allNamed reports whether every variant is a (possibly generic or
+// qualified) type name rather than a primitive or type expression.
+func allNamed(variants []string) bool {
+	for _, v := range variants {
+		name := baseName(v)
+		if name == "" || !isIdentStart(name[0]) || name[0] < 'A' || name[0] > 'Z' {
+			return false
+		}
+		for i := 0; i < len(name); i++ {
+			if !isIdentPart(name[i]) && name[i] != '.' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isFunctionType reports whether a type expression is a function type.
+func isFunctionType(t string) bool {
+	t = strings.TrimSpace(t)
+	if !strings.HasPrefix(t, "(") && !strings.HasPrefix(t, "<") {
+		return false
+	}
+	return len(splitTop(t, '=')) > 1
+}
+
+// jsonValue JSON-encodes a literal written in TypeScript, such as a
+// single-quoted string; text that is no literal is encoded as a string.
+func jsonValue(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ""
+	}
+	if s[0] == '\'' && isStringLiteral(s) {
+		s = unquote(s)
+		b, _ := json.Marshal(s)
+		return string(b)
+	}
+	if json.Valid([]byte(s)) {
+		return s
+	}
+	b, _ := json.Marshal(s)
+	return string(b)
+}
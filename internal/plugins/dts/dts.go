@@ -0,0 +1,222 @@
+package dts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/roberthamel/skill-compiler/internal/fetch"
+	"github.com/roberthamel/skill-compiler/internal/instructions"
+	"github.com/roberthamel/skill-compiler/internal/ir"
+)
+
+// Plugin handles TypeScript declaration files (.d.ts), such as the typings
+// bundled with a JavaScript SDK.
+type Plugin struct{}
+
+func New() *Plugin { return &Plugin{} }
+
+func (p *Plugin) Name() string { return "dts" }
+
+// declarationExts are the file name endings of declaration files.
+var declarationExts = []string{".d.ts", ".d.mts", ".d.cts"}
+
+// declarationExt returns the declaration file ending of name, if any.
+func declarationExt(name string) string {
+	lower := strings.ToLower(name)
+	for _, ext := range declarationExts {
+		if strings.HasSuffix(lower, ext) {
+			return name[len(name)-len(ext):]
+		}
+	}
+	return ""
+}
+
+// Detect accepts explicitly typed sources and untyped paths to a .d.ts
+// file; a directory of declarations needs an explicit type.
+func (p *Plugin) Detect(source instructions.SpecSource) bool {
+	if source.Type == "dts" || source.Type == "typescript" {
+		return true
+	}
+	return source.Type == "" && declarationExt(source.Path) != ""
+}
+
+// bundle is what Fetch produces for a path: the package the declarations
+// ship in and the declaration files, keyed by slash-separated path relative
+// to the source directory.
+type bundle struct {
+	Package string       `json:"package,omitempty"` // name from the nearest package.json
+	Version string       `json:"version,omitempty"`
+	Files   []bundleFile `json:"files"`
+}
+
+type bundleFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+func (p *Plugin) Fetch(source instructions.SpecSource) ([]byte, error) {
+	if source.Path != "" {
+		return fetchPath(source)
+	}
+	return fetch.Source("dts", source)
+}
+
+// fetchPath loads a declaration file, or every declaration file under a
+// directory (node_modules and hidden directories aside), along with the
+// name and version of the package they belong to.
+func fetchPath(source instructions.SpecSource) ([]byte, error) {
+	info, err := os.Stat(source.Path)
+	if err != nil {
+		return nil, err
+	}
+	var b bundle
+	root := source.Path
+	if info.IsDir() {
+		err := filepath.Walk(root, func(p string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, _ := filepath.Rel(root, p)
+			rel = filepath.ToSlash(rel)
+			if fi.IsDir() {
+				name := fi.Name()
+				if rel != "." && (name == "node_modules" || strings.HasPrefix(name, ".") || source.Excludes(rel)) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if declarationExt(fi.Name()) == "" || source.Excludes(rel) {
+				return nil
+			}
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			b.Files = append(b.Files, bundleFile{Path: rel, Content: string(data)})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("walking %s: %w", root, err)
+		}
+		if len(b.Files) == 0 {
+			return nil, fmt.Errorf("no declaration files found in %s", root)
+		}
+	} else {
+		data, err := os.ReadFile(source.Path)
+		if err != nil {
+			return nil, err
+		}
+		b.Files = []bundleFile{{Path: filepath.Base(source.Path), Content: string(data)}}
+		root = filepath.Dir(source.Path)
+	}
+	b.Package, b.Version = findPackage(root)
+	return json.Marshal(b)
+}
+
+// findPackage reads the name and version of the nearest package.json at or
+// above dir.
+func findPackage(dir string) (name, version string) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", ""
+	}
+	for d := dir; ; d = filepath.Dir(d) {
+		data, err := os.ReadFile(filepath.Join(d, "package.json"))
+		if err == nil {
+			var pkg struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			}
+			if json.Unmarshal(data, &pkg) == nil {
+				return pkg.Name, pkg.Version
+			}
+			return "", ""
+		}
+		if filepath.Dir(d) == d {
+			return "", ""
+		}
+	}
+}
+
+func (p *Plugin) Parse(raw []byte, source instructions.SpecSource) (*ir.IntermediateRepr, error) {
+	var b bundle
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '{' {
+		// Declarations never start with a brace; this is a bundle from Fetch
+		if err := json.Unmarshal(trimmed, &b); err != nil {
+			return nil, fmt.Errorf("parsing dts bundle: %w", err)
+		}
+	} else {
+		name := "index.d.ts"
+		if source.URL != "" {
+			name = path.Base(strings.SplitN(source.URL, "?", 2)[0])
+		}
+		b.Files = []bundleFile{{Path: name, Content: string(raw)}}
+	}
+
+	result := &ir.IntermediateRepr{
+		Metadata: make(map[string]string),
+	}
+	if b.Package != "" {
+		result.Metadata["package"] = b.Package
+	}
+	if b.Version != "" {
+		result.Metadata["version"] = b.Version
+	}
+
+	c := newConverter(result)
+	var parsed []string
+	for _, f := range b.Files {
+		decls, fileDoc, err := parseFile(f.Content, moduleName(f.Path, b.Package, len(b.Files)))
+		if err != nil {
+			// One bad file should not sink a package's worth of typings
+			if len(b.Files) == 1 {
+				return nil, fmt.Errorf("parsing %s: %w", f.Path, err)
+			}
+			result.Warnings = append(result.Warnings, ir.Warning{Message: fmt.Sprintf("skipping %s: %v", f.Path, err)})
+			continue
+		}
+		parsed = append(parsed, f.Path)
+		c.addFile(moduleName(f.Path, b.Package, len(b.Files)), fileDoc, decls)
+	}
+	c.finish()
+	if len(result.Operations) == 0 && len(result.Types) == 0 {
+		return nil, fmt.Errorf("no exported declarations found in %s", strings.Join(parsed, ", "))
+	}
+	result.Metadata["files"] = strings.Join(parsed, ", ")
+	return result, nil
+}
+
+// moduleName names the module a declaration file describes: its path
+// without the extension, or the package itself for the package's index or
+// a lone file.
+func moduleName(file, pkg string, files int) string {
+	name := strings.TrimSuffix(file, declarationExt(file))
+	if pkg != "" && (files == 1 || name == "index") {
+		return pkg
+	}
+	return name
+}
+
+func (p *Plugin) Validate(parsed *ir.IntermediateRepr) []ir.Warning {
+	var warnings []ir.Warning
+	for _, op := range parsed.Operations {
+		if op.Description == "" {
+			warnings = append(warnings, ir.Warning{
+				Message: fmt.Sprintf("exported %s %s has no JSDoc comment", strings.ToLower(op.Method), op.Path),
+			})
+		}
+	}
+	for _, td := range parsed.Types {
+		if td.Description == "" {
+			warnings = append(warnings, ir.Warning{
+				Message: fmt.Sprintf("exported type %s has no JSDoc comment", td.Name),
+			})
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,240 @@
+package dts
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/roberthamel/skill-compiler/internal/instructions"
+	"github.com/roberthamel/skill-compiler/internal/ir"
+)
+
+func TestDetect(t *testing.T) {
+	p := New()
+
+	tests := []struct {
+		name   string
+		source instructions.SpecSource
+		want   bool
+	}{
+		{"explicit type", instructions.SpecSource{Type: "dts", Path: "types/"}, true},
+		{"typescript alias", instructions.SpecSource{Type: "typescript", URL: "https://unpkg.com/x/index.d.ts"}, true},
+		{"d.ts path", instructions.SpecSource{Path: "dist/index.d.ts"}, true},
+		{"d.mts path", instructions.SpecSource{Path: "dist/index.d.mts"}, true},
+		{"plain ts source", instructions.SpecSource{Path: "src/index.ts"}, false},
+		{"other type", instructions.SpecSource{Type: "openapi", Path: "index.d.ts"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Detect(tt.source); got != tt.want {
+				t.Errorf("Detect(%+v) = %v, want %v", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func parseSource(t *testing.T, source instructions.SpecSource) (*Plugin, *ir.IntermediateRepr) {
+	t.Helper()
+	p := New()
+	raw, err := p.Fetch(source)
+	if err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+	result, err := p.Parse(raw, source)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	return p, result
+}
+
+func TestParse_Operations(t *testing.T) {
+	p, result := parseSource(t, instructions.SpecSource{Type: "dts", Path: "testdata/sdk/types"})
+
+	if result.Metadata["package"] != "@acme/orders" || result.Metadata["version"] != "2.3.0" {
+		t.Errorf("metadata = %v, want package from package.json", result.Metadata)
+	}
+	if got := result.Metadata["constants"]; got != "VERSION: string" {
+		t.Errorf("constants = %q", got)
+	}
+
+	ops := map[string]ir.Operation{}
+	for _, op := range result.Operations {
+		ops[op.ID] = op
+	}
+	for _, id := range []string{"Client.constructor", "Client.get", "Client.list", "Client.on", "Client.cancel", "Client.fromEnv", "createClient", "formatAmount", "Webhooks.verify", "undocumented"} {
+		if _, ok := ops[id]; !ok {
+			t.Errorf("missing operation %s", id)
+		}
+	}
+	// private/protected members, unexported declarations and optional
+	// interface callbacks are not operations
+	for _, id := range []string{"Client.request", "helper", "ClientOptions.onRetry"} {
+		if _, ok := ops[id]; ok {
+			t.Errorf("unexpected operation %s", id)
+		}
+	}
+
+	get := ops["Client.get"]
+	if get.Method != "METHOD" || get.Signature != "get(id: string): Promise<Order | null>" {
+		t.Errorf("Client.get = %+v", get)
+	}
+	if get.Parameters[0].Description != "The order ID." || get.Responses[0].Description != "The order, or null if it does not exist." {
+		t.Errorf("Client.get JSDoc not applied: %+v", get)
+	}
+	if !strings.Contains(get.Description, "Throws: AcmeError: When the request fails.") || !strings.Contains(get.Description, "```ts\nconst order = await client.get(\"ord_123\");\n```") {
+		t.Errorf("Client.get description = %q", get.Description)
+	}
+
+	// overloads merge, with parameters some overloads lack made optional
+	list := ops["Client.list"]
+	if strings.Count(list.Signature, "\n") != 1 || len(list.Parameters) != 3 || list.Parameters[1].Name != "cursor" || list.Parameters[1].Required {
+		t.Errorf("Client.list = %+v", list)
+	}
+
+	if ctor := ops["Client.constructor"]; ctor.Method != "CONSTRUCTOR" || ctor.Signature != "new Client(options: ClientOptions)" {
+		t.Errorf("constructor = %+v", ctor)
+	}
+	if on := ops["Client.on"]; on.Parameters[0].In != "type" || on.Parameters[0].Type != "ClientEvent" {
+		t.Errorf("Client.on type parameter = %+v", on.Parameters[0])
+	}
+	if !ops["Client.cancel"].Deprecated {
+		t.Error("Client.cancel should be deprecated")
+	}
+	if sig := ops["Client.fromEnv"].Signature; !strings.HasPrefix(sig, "static ") {
+		t.Errorf("static signature = %q", sig)
+	}
+	if fn := ops["formatAmount"]; fn.Method != "FUNC" || len(fn.Parameters) != 2 || fn.Parameters[1].Required {
+		t.Errorf("arrow-typed const = %+v", fn)
+	}
+	if op := ops["Webhooks.verify"]; op.Parameters[1].Description != "The Acme-Signature header." {
+		t.Errorf("namespace function = %+v", op)
+	}
+
+	if len(result.Groups) != 3 || result.Groups[0].Name != "@acme/orders" || result.Groups[0].Description != "Client for the Acme order service." {
+		t.Errorf("groups = %+v", result.Groups)
+	}
+
+	var messages []string
+	for _, w := range p.Validate(result) {
+		messages = append(messages, w.Message)
+	}
+	if got := strings.Join(messages, "\n"); !strings.Contains(got, "exported func undocumented has no JSDoc comment") || !strings.Contains(got, "exported type Item has no JSDoc comment") {
+		t.Errorf("warnings = %v", messages)
+	}
+}
+
+func TestParse_Types(t *testing.T) {
+	_, result := parseSource(t, instructions.SpecSource{Type: "dts", Path: "testdata/sdk/types"})
+
+	types := map[string]ir.TypeDef{}
+	for _, td := range result.Types {
+		types[td.Name] = td
+	}
+	if _, ok := types["Internal"]; ok {
+		t.Error("unexported interface should be skipped")
+	}
+
+	opts := types["ClientOptions"]
+	if len(opts.Fields) != 4 || opts.Fields[1].Default != `"https://api.acme.test"` || opts.Fields[2].Default != "30000" {
+		t.Fatalf("ClientOptions = %+v", opts)
+	}
+	if cb := opts.Fields[3]; cb.Type != "(attempt: number, error: Error) => void" || cb.Required {
+		t.Errorf("optional callback = %+v", cb)
+	}
+
+	client := types["Client"]
+	if client.Kind != "class" || strings.Join(client.Extends, ",") != "EventEmitter" || len(client.Fields) != 2 {
+		t.Fatalf("Client = %+v, want public properties only", client)
+	}
+	if region := client.Fields[0]; region.Type != "Region" || !strings.HasSuffix(region.Description, "Read-only.") {
+		t.Errorf("getter = %+v", region)
+	}
+
+	if s := types["OrderStatus"]; s.Kind != "enum" || strings.Join(s.Enum, ",") != "open,shipped,cancelled" {
+		t.Errorf("OrderStatus = %+v", s)
+	}
+	if e := types["ClientEvent"]; e.Kind != "union" || strings.Join(e.OneOf, ",") != "OrderCreated,OrderShipped" {
+		t.Errorf("ClientEvent = %+v", e)
+	}
+	if o := types["Order"]; o.Kind != "object" || len(o.Fields) != 4 {
+		t.Errorf("Order = %+v", o)
+	}
+	if h := types["Handler"]; h.Kind != "func" || !strings.Contains(h.Description, "Type parameters: <E extends ClientEvent = ClientEvent>.") {
+		t.Errorf("Handler = %+v", h)
+	}
+
+	region := types["Region"]
+	if strings.Join(region.Enum, ",") != "Region.US,Region.EU" || region.Fields[0].Const != `"us"` {
+		t.Errorf("Region = %+v", region)
+	}
+	var consts []string
+	for _, f := range types["Priority"].Fields {
+		consts = append(consts, f.Const)
+	}
+	if got := strings.Join(consts, ","); got != "0,5,6" {
+		t.Errorf("Priority values = %s, want 0,5,6", got)
+	}
+
+	if _, ok := types["Webhooks.Payload"]; !ok {
+		t.Error("missing namespaced type Webhooks.Payload")
+	}
+	page := types["Paginated"]
+	if len(page.Fields) != 3 || page.Fields[2].Name != "[key: string]" || strings.Join(page.Extends, ",") != "AsyncIterable" {
+		t.Errorf("Paginated = %+v", page)
+	}
+}
+
+func TestParse_GlobalScript(t *testing.T) {
+	_, result := parseSource(t, instructions.SpecSource{Path: "testdata/globals.d.ts"})
+
+	// no imports or exports: every declaration is a global
+	if len(result.Operations) != 1 || result.Operations[0].ID != "mountWidget" || result.Operations[0].Responses[0].Body.TypeName != "() => void" {
+		t.Errorf("operations = %+v", result.Operations)
+	}
+	if len(result.Types) != 1 || result.Types[0].Fields[1].Type != `"light" | "dark"` {
+		t.Errorf("types = %+v", result.Types)
+	}
+	if got := result.Metadata["constants"]; got != "AcmeWidget.version: string" {
+		t.Errorf("constants = %q", got)
+	}
+}
+
+func TestParse_Raw(t *testing.T) {
+	p := New()
+	src := `
+export interface Options { retries?: number }
+export { connect };
+declare function connect(url: string, opts?: Options): Promise<void>;
+declare function hidden(): void;
+`
+	result, err := p.Parse([]byte(src), instructions.SpecSource{URL: "https://unpkg.com/acme-db/dist/db.d.ts"})
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if len(result.Operations) != 1 || result.Operations[0].ID != "connect" || result.Groups[0].Name != "db" {
+		t.Errorf("result = %+v, want connect exported via export list", result)
+	}
+
+	if _, err := p.Parse([]byte("export interface Broken {"), instructions.SpecSource{Path: "broken.d.ts"}); err == nil {
+		t.Error("expected error for unterminated interface")
+	}
+}
+
+func TestParseJSDoc(t *testing.T) {
+	doc := parseJSDoc(`/**
+	 * Sends a message. See {@link Channel | the channel docs}.
+	 *
+	 * @param {string} [options.to=all] - Recipient.
+	 * @returns {Promise<void>} Resolves once sent.
+	 * @deprecated Use publish.
+	 * @default ` + "`null`" + `
+	 */`)
+	if doc.text != "Sends a message. See the channel docs." {
+		t.Errorf("text = %q", doc.text)
+	}
+	if doc.params["options.to"] != "Recipient." || doc.returns != "Resolves once sent." {
+		t.Errorf("tags = %+v", doc)
+	}
+	if !doc.deprecated || doc.defaultValue != "null" || doc.description() != "Sends a message. See the channel docs.\n\nDeprecated: Use publish." {
+		t.Errorf("doc = %+v", doc)
+	}
+}
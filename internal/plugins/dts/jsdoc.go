@@ -0,0 +1,124 @@
+package dts
+
+import (
+	"regexp"
+	"strings"
+)
+
+// jsDoc is a parsed /** ... */ comment.
+type jsDoc struct {
+	text         string
+	params       map[string]string // @param descriptions by parameter name
+	returns      string
+	deprecated   bool
+	deprecation  string // text following @deprecated
+	defaultValue string
+	throws       []string
+	examples     []string
+	packageDoc   bool // a @packageDocumentation or @module comment describing the file
+}
+
+// linkRe matches inline {@link Target label} tags.
+var linkRe = regexp.MustCompile(`\{@link(?:code|plain)?\s+([^}|\s]+)(?:\s*\|\s*|\s+)?([^}]*)\}`)
+
+// typeAnnotationRe matches the {type} of a Closure-style @param or @returns.
+var typeAnnotationRe = regexp.MustCompile(`^\{[^}]*\}\s*`)
+
+func parseJSDoc(raw string) jsDoc {
+	var doc jsDoc
+	if raw == "" {
+		return doc
+	}
+	body := strings.TrimSuffix(strings.TrimPrefix(raw, "/**"), "*/")
+	body = linkRe.ReplaceAllStringFunc(body, func(m string) string {
+		sub := linkRe.FindStringSubmatch(m)
+		if strings.TrimSpace(sub[2]) != "" {
+			return strings.TrimSpace(sub[2])
+		}
+		return sub[1]
+	})
+
+	// Split into the leading text and one section per block tag
+	type section struct{ tag, text string }
+	sections := []section{{}}
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimLeft(line, " \t")
+		line = strings.TrimPrefix(line, "*")
+		line = strings.TrimPrefix(line, " ")
+		line = strings.TrimRight(line, " \t\r")
+		if strings.HasPrefix(line, "@") {
+			tag, rest, _ := strings.Cut(line[1:], " ")
+			sections = append(sections, section{tag: tag, text: rest})
+			continue
+		}
+		last := &sections[len(sections)-1]
+		last.text += "\n" + line
+	}
+
+	var text []string
+	for _, s := range sections {
+		value := strings.TrimSpace(s.text)
+		switch s.tag {
+		case "":
+			text = append(text, value)
+		case "remarks":
+			text = append(text, value)
+		case "param", "arg", "argument":
+			value = typeAnnotationRe.ReplaceAllString(value, "")
+			name, desc, _ := strings.Cut(value, " ")
+			// [name=default] marks an optional parameter; options.timeout
+			// documents a property of one
+			name = strings.TrimSuffix(strings.TrimPrefix(name, "["), "]")
+			name, _, _ = strings.Cut(name, "=")
+			desc = strings.TrimPrefix(strings.TrimSpace(desc), "- ")
+			if doc.params == nil {
+				doc.params = make(map[string]string)
+			}
+			doc.params[name] = strings.TrimSpace(desc)
+		case "returns", "return":
+			doc.returns = typeAnnotationRe.ReplaceAllString(value, "")
+		case "deprecated":
+			doc.deprecated = true
+			doc.deprecation = value
+		case "default", "defaultValue":
+			doc.defaultValue = strings.Trim(value, "`")
+		case "throws", "throw", "exception":
+			// {AcmeError} When... names the error thrown
+			if typ := typeAnnotationRe.FindString(value); typ != "" {
+				value = strings.TrimSpace(strings.Trim(strings.TrimSpace(typ), "{}") + ": " + value[len(typ):])
+			}
+			doc.throws = append(doc.throws, value)
+		case "example":
+			doc.examples = append(doc.examples, strings.Trim(s.text, "\n"))
+		case "packageDocumentation", "module", "file", "fileoverview":
+			doc.packageDoc = true
+		}
+	}
+	doc.text = strings.TrimSpace(strings.Join(text, "\n\n"))
+	return doc
+}
+
+// description is the comment's text with its deprecation note, thrown
+// errors and examples appended.
+func (d jsDoc) description() string {
+	parts := []string{d.text}
+	if d.deprecation != "" {
+		parts = append(parts, "Deprecated: "+d.deprecation)
+	}
+	if len(d.throws) > 0 {
+		parts = append(parts, "Throws: "+strings.Join(d.throws, "; "))
+	}
+	for _, ex := range d.examples {
+		if !strings.Contains(ex, "```") {
+			ex = "```ts\n" + ex + "\n```"
+		}
+		parts = append(parts, "Example:\n"+ex)
+	}
+	var nonEmpty []string
+	for _, p := range parts {
+		if p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return strings.Join(nonEmpty, "\n\n")
+}
@@ -0,0 +1,936 @@
+package dts
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Declaration file parsing. Only the declaration subset of TypeScript is
+// understood; statements the IR has no use for (imports, re-exports) are
+// skipped to their end.
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokPunct
+	tokString
+	tokNumber
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	line  int
+	space bool   // preceded by whitespace or a comment
+	doc   string // JSDoc comment immediately preceding the token
+}
+
+// lex splits declaration source into tokens, dropping whitespace and
+// comments. JSDoc comments are kept on the token that follows them.
+func lex(src string) ([]token, error) {
+	src = strings.TrimPrefix(src, "\ufeff")
+	var tokens []token
+	line := 1
+	space := false
+	doc := ""
+	emit := func(kind tokenKind, value string) {
+		tokens = append(tokens, token{kind: kind, value: value, line: line, space: space, doc: doc})
+		space, doc = false, ""
+	}
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			space = true
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			space = true
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			space = true
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			comment := src[i : i+end+4]
+			if strings.HasPrefix(comment, "/**") && comment != "/**/" {
+				doc = comment
+			}
+			line += strings.Count(comment, "\n")
+			space = true
+			i += len(comment)
+		case c == '"' || c == '\'':
+			j := i + 1
+			for ; j < len(src) && src[j] != c; j++ {
+				if src[j] == '\\' {
+					j++
+				} else if src[j] == '\n' {
+					break
+				}
+			}
+			if j >= len(src) || src[j] != c {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			emit(tokString, src[i:j+1])
+			i = j + 1
+		case c == '`':
+			// Template literal types may nest ${...} substitutions
+			j, depth := i+1, 0
+			for ; j < len(src); j++ {
+				if src[j] == '\\' {
+					j++
+					continue
+				}
+				if src[j] == '`' && depth == 0 {
+					break
+				}
+				switch {
+				case strings.HasPrefix(src[j:], "${"):
+					depth++
+					j++
+				case src[j] == '}' && depth > 0:
+					depth--
+				}
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("line %d: unterminated template literal", line)
+			}
+			emit(tokString, src[i:j+1])
+			line += strings.Count(src[i:j+1], "\n")
+			i = j + 1
+		case strings.HasPrefix(src[i:], "=>"), strings.HasPrefix(src[i:], "..."):
+			n := 2
+			if c == '.' {
+				n = 3
+			}
+			emit(tokPunct, src[i:i+n])
+			i += n
+		case c >= '0' && c <= '9':
+			j := i + 1
+			for j < len(src) && (isIdentPart(src[j]) || src[j] == '.') {
+				j++
+			}
+			emit(tokNumber, src[i:j])
+			i = j
+		case isIdentStart(c) || (c == '#' && i+1 < len(src) && isIdentStart(src[i+1])):
+			j := i + 1
+			for j < len(src) && isIdentPart(src[j]) {
+				j++
+			}
+			emit(tokIdent, src[i:j])
+			i = j
+		case strings.IndexByte("{}()[]<>;,:?=|&.*!+-~@/%^", c) >= 0:
+			emit(tokPunct, string(c))
+			i++
+		default:
+			return nil, fmt.Errorf("line %d: unexpected character %q", line, c)
+		}
+	}
+	tokens = append(tokens, token{kind: tokEOF, line: line, space: true})
+	return tokens, nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
+
+// render joins tokens back into source text, with runs of whitespace and
+// comments collapsed to a single space.
+func render(tokens []token) string {
+	var b strings.Builder
+	for i, t := range tokens {
+		if i > 0 && t.space {
+			b.WriteByte(' ')
+		}
+		b.WriteString(t.value)
+	}
+	return b.String()
+}
+
+// decl is one exported declaration of a file.
+type decl struct {
+	kind       string // function, variable, class, interface, type or enum
+	name       string // qualified by any enclosing namespaces
+	module     string // file or ambient module declaring it
+	doc        jsDoc
+	exported   bool   // has an export modifier or sits in an ambient block
+	exportName string // top-level name an export list would use to export it
+	signature  string
+	typeParams []typeParam
+	params     []param
+	returns    string
+	typ        string   // type alias or variable type
+	heritage   []string // extends and implements clauses
+	members    []member
+	abstract   bool
+}
+
+// member is a property, method or signature of a class, interface or object
+// type.
+type member struct {
+	kind       string // property, method, constructor, call or index
+	name       string
+	doc        jsDoc
+	optional   bool
+	static     bool
+	readonly   bool
+	setter     bool // a set accessor, which makes a get accessor writable
+	typ        string
+	typeParams []typeParam
+	params     []param
+	returns    string
+	signature  string
+}
+
+type param struct {
+	name     string // empty for destructuring patterns
+	typ      string
+	optional bool
+	rest     bool
+}
+
+type typeParam struct {
+	name       string
+	constraint string
+	def        string
+}
+
+// String renders the type parameter as declared.
+func (tp typeParam) String() string {
+	s := tp.name
+	if tp.constraint != "" {
+		s += " extends " + tp.constraint
+	}
+	if tp.def != "" {
+		s += " = " + tp.def
+	}
+	return s
+}
+
+// scope is the block a statement appears in.
+type scope struct {
+	module  string // file or ambient module name
+	prefix  string // enclosing namespaces, e.g. "Acme.Util."
+	ambient bool   // inside a namespace or ambient module, where everything is exported
+	nested  bool   // not at the top level of the file
+	export  string // top-level name of the enclosing namespace
+}
+
+type parser struct {
+	tokens      []token
+	pos         int
+	decls       []*decl
+	isModule    bool            // the file has top-level imports or exports
+	exportNames map[string]bool // names exported by export lists and export default/=
+	fileDoc     string          // @packageDocumentation / @module comment
+}
+
+// parseFile parses a declaration file, returning the declarations it
+// exports. Files without top-level imports or exports declare globals, all
+// of which count as exported.
+func parseFile(src, module string) ([]*decl, string, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, "", err
+	}
+	p := &parser{tokens: tokens, exportNames: make(map[string]bool)}
+	if err := p.statements(scope{module: module}); err != nil {
+		return nil, "", err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, "", fmt.Errorf("line %d: unexpected %q", t.line, t.value)
+	}
+	var exported []*decl
+	for _, d := range p.decls {
+		if d.exported || !p.isModule || p.exportNames[d.exportName] {
+			exported = append(exported, d)
+		}
+	}
+	return exported, p.fileDoc, nil
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+// peekAt returns the token n positions ahead.
+func (p *parser) peekAt(n int) token {
+	if p.pos+n >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+n]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// is reports whether the next token is the given punctuator.
+func (p *parser) is(punct string) bool {
+	t := p.peek()
+	return t.kind == tokPunct && t.value == punct
+}
+
+// isIdent reports whether the next token is the given identifier or keyword.
+func (p *parser) isIdent(name string) bool {
+	t := p.peek()
+	return t.kind == tokIdent && t.value == name
+}
+
+// skip consumes the given punctuator if it is next.
+func (p *parser) skip(punct string) bool {
+	if p.is(punct) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.skip(punct) {
+		t := p.peek()
+		return fmt.Errorf("line %d: expected %q, got %q", t.line, punct, t.value)
+	}
+	return nil
+}
+
+func (p *parser) name() (string, error) {
+	t := p.next()
+	if t.kind != tokIdent {
+		return "", fmt.Errorf("line %d: expected a name, got %q", t.line, t.value)
+	}
+	return t.value, nil
+}
+
+// statements parses declarations up to the end of the block or file.
+func (p *parser) statements(s scope) error {
+	for !p.is("}") && p.peek().kind != tokEOF {
+		if p.skip(";") {
+			continue
+		}
+		if err := p.statement(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// declarationKeywords start declarations, and end a preceding statement
+// left without a semicolon.
+var declarationKeywords = map[string]bool{
+	"export": true, "declare": true, "default": true, "abstract": true, "async": true,
+	"function": true, "class": true, "interface": true, "type": true, "enum": true,
+	"const": true, "let": true, "var": true, "namespace": true, "module": true,
+	"global": true, "import": true,
+}
+
+func (p *parser) statement(s scope) error {
+	doc := parseJSDoc(p.peek().doc)
+	if doc.packageDoc {
+		if p.fileDoc == "" {
+			p.fileDoc = doc.text
+		}
+		doc = jsDoc{}
+	}
+	exported := s.ambient
+	abstract := false
+modifiers:
+	for p.peek().kind == tokIdent && p.peekAt(1).kind == tokIdent || p.isIdent("export") {
+		switch p.peek().value {
+		case "export":
+			if !s.nested {
+				p.isModule = true
+			}
+			p.pos++
+			if p.isIdent("type") && (p.peekAt(1).value == "{" || p.peekAt(1).value == "*") {
+				p.pos++
+			}
+			switch {
+			case p.is("{"):
+				p.exportList()
+				return nil
+			case p.is("="):
+				p.pos++
+				if t := p.peek(); t.kind == tokIdent {
+					p.exportNames[t.value] = true
+				}
+				p.skipStatement()
+				return nil
+			case p.is("*"), p.isIdent("as"), p.isIdent("import"):
+				// export * from, export as namespace, export import A = B
+				p.skipStatement()
+				return nil
+			}
+			exported = true
+			continue
+		case "default":
+			p.pos++
+			if t := p.peek(); t.kind == tokIdent && !declarationKeywords[t.value] {
+				// export default Name;
+				p.exportNames[t.value] = true
+				p.skipStatement()
+				return nil
+			}
+			continue
+		case "abstract":
+			abstract = true
+			p.pos++
+			continue
+		case "declare", "async":
+			p.pos++
+			continue
+		}
+		break modifiers
+	}
+
+	exportName := s.export
+	d := &decl{module: s.module, doc: doc, exported: exported, abstract: abstract}
+	start := p.pos
+	keyword := p.next()
+	if keyword.kind != tokIdent {
+		return fmt.Errorf("line %d: unexpected %q", keyword.line, keyword.value)
+	}
+	var err error
+	switch keyword.value {
+	case "function":
+		err = p.function(d)
+	case "class", "interface":
+		d.kind = keyword.value
+		err = p.classOrInterface(d)
+	case "type":
+		d.kind = "type"
+		err = p.typeAlias(d)
+	case "enum":
+		d.kind = "enum"
+		err = p.enum(d)
+	case "const", "let", "var":
+		if p.isIdent("enum") {
+			p.pos++
+			d.kind = "enum"
+			err = p.enum(d)
+			break
+		}
+		d.kind = "variable"
+		err = p.variable(d)
+	case "namespace", "module", "global":
+		return p.namespace(keyword.value, s, exported)
+	default:
+		// import declarations and anything else the IR has no use for
+		if keyword.value == "import" && !s.nested {
+			p.isModule = true
+		}
+		p.skipStatement()
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if d.signature == "" {
+		d.signature = render(p.tokens[start:p.pos])
+	}
+	p.skip(";")
+	if d.name == "" {
+		d.name = "default"
+	}
+	if exportName == "" {
+		exportName = d.name
+	}
+	d.exportName = exportName
+	d.name = s.prefix + d.name
+	p.decls = append(p.decls, d)
+	return nil
+}
+
+// exportList records the names of an export { a, b as c } list. Lists
+// re-exporting from another module add nothing: its file is parsed anyway.
+func (p *parser) exportList() {
+	start := p.pos
+	p.skipStatement()
+	list := p.tokens[start:p.pos]
+	for i := len(list) - 1; i >= 0; i-- {
+		if list[i].kind == tokIdent && list[i].value == "from" {
+			return
+		}
+	}
+	for i, t := range list {
+		if t.kind == tokIdent && t.value != "type" && t.value != "as" && (i == 0 || list[i-1].value != "as") {
+			p.exportNames[t.value] = true
+		}
+	}
+}
+
+// skipStatement consumes tokens up to and including the next semicolon,
+// stopping early at the end of the enclosing block or at a line starting a
+// new declaration.
+func (p *parser) skipStatement() {
+	depth := 0
+	for {
+		t := p.peek()
+		if t.kind == tokEOF {
+			return
+		}
+		if depth == 0 && p.pos > 0 && t.line > p.tokens[p.pos-1].line && t.kind == tokIdent && declarationKeywords[t.value] {
+			return
+		}
+		if t.kind == tokPunct {
+			switch t.value {
+			case ";":
+				if depth == 0 {
+					p.pos++
+					return
+				}
+			case "{", "(", "[":
+				depth++
+			case "}", ")", "]":
+				if depth == 0 {
+					return
+				}
+				depth--
+			}
+		}
+		p.pos++
+	}
+}
+
+func (p *parser) function(d *decl) error {
+	d.kind = "function"
+	if p.peek().kind == tokIdent {
+		d.name = p.next().value
+	}
+	var err error
+	if d.typeParams, err = p.typeParams(); err != nil {
+		return err
+	}
+	if d.params, err = p.params(); err != nil {
+		return err
+	}
+	if p.skip(":") {
+		d.returns = p.typeSpan(";")
+	}
+	return nil
+}
+
+func (p *parser) classOrInterface(d *decl) error {
+	if t := p.peek(); t.kind == tokIdent && t.value != "extends" && t.value != "implements" {
+		d.name = p.next().value
+	}
+	var err error
+	if d.typeParams, err = p.typeParams(); err != nil {
+		return err
+	}
+	for p.isIdent("extends") || p.isIdent("implements") {
+		p.pos++
+		for {
+			if t := p.typeSpan(",", "{", "implements"); t != "" {
+				d.heritage = append(d.heritage, t)
+			}
+			if !p.skip(",") {
+				break
+			}
+		}
+	}
+	d.members, err = p.members()
+	return err
+}
+
+func (p *parser) typeAlias(d *decl) error {
+	var err error
+	if d.name, err = p.name(); err != nil {
+		return err
+	}
+	if d.typeParams, err = p.typeParams(); err != nil {
+		return err
+	}
+	if err := p.expect("="); err != nil {
+		return err
+	}
+	// An object literal type keeps its members; anything longer (an
+	// intersection, say) is kept as written
+	if p.is("{") {
+		start := p.pos
+		members, err := p.members()
+		if err == nil && p.atStatementEnd() {
+			d.members = members
+			d.typ = render(p.tokens[start:p.pos])
+			return nil
+		}
+		p.pos = start
+	}
+	d.typ = p.typeSpan(";")
+	return nil
+}
+
+// atStatementEnd reports whether the previous token ended a statement.
+func (p *parser) atStatementEnd() bool {
+	t := p.peek()
+	return t.kind == tokEOF || p.is(";") || p.is("}") || t.line > p.tokens[p.pos-1].line
+}
+
+func (p *parser) enum(d *decl) error {
+	var err error
+	if d.name, err = p.name(); err != nil {
+		return err
+	}
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for !p.skip("}") {
+		if p.peek().kind == tokEOF {
+			return fmt.Errorf("unterminated enum %s", d.name)
+		}
+		m := member{kind: "property", doc: parseJSDoc(p.peek().doc), name: unquote(p.next().value)}
+		if p.skip("=") {
+			m.typ = p.typeSpan(",", "}")
+		}
+		d.members = append(d.members, m)
+		p.skip(",")
+	}
+	return nil
+}
+
+// variable parses the first declarator of a const/let/var statement. A
+// variable of function type is kept as a function.
+func (p *parser) variable(d *decl) error {
+	var err error
+	if d.name, err = p.name(); err != nil {
+		return err
+	}
+	if p.skip(":") {
+		start := p.pos
+		if p.is("(") || p.is("<") {
+			tps, err1 := p.typeParams()
+			params, err2 := p.params()
+			if err1 == nil && err2 == nil && p.skip("=>") {
+				d.kind = "function"
+				d.typeParams, d.params = tps, params
+				d.returns = p.typeSpan(";", ",", "=")
+			} else {
+				p.pos = start
+			}
+		}
+		if d.kind == "variable" {
+			d.typ = p.typeSpan(";", ",", "=")
+		}
+	}
+	// initializers and further declarators
+	if !p.atStatementEnd() {
+		p.skipStatement()
+	}
+	return nil
+}
+
+// namespace parses a namespace, an ambient module or a global augmentation,
+// whose members are exported if the block is.
+func (p *parser) namespace(keyword string, s scope, exported bool) error {
+	inner := scope{module: s.module, prefix: s.prefix, ambient: true, nested: true, export: s.export}
+	namespace := false
+	if keyword != "global" {
+		t := p.next()
+		switch t.kind {
+		case tokString:
+			// declare module "name" { ... }; a body-less shorthand
+			// declaration says nothing about the module
+			if !p.is("{") {
+				p.skipStatement()
+				return nil
+			}
+			inner.module, inner.prefix = unquote(t.value), ""
+		case tokIdent:
+			namespace = true
+			name := t.value
+			for p.skip(".") {
+				part, err := p.name()
+				if err != nil {
+					return err
+				}
+				name += "." + part
+			}
+			inner.prefix += name + "."
+			if inner.export == "" {
+				inner.export = strings.SplitN(name, ".", 2)[0]
+			}
+		default:
+			return fmt.Errorf("line %d: expected a namespace name, got %q", t.line, t.value)
+		}
+	}
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	// Members of an unexported top-level namespace are only exported if an
+	// export list names the namespace
+	start := len(p.decls)
+	if err := p.statements(inner); err != nil {
+		return err
+	}
+	if err := p.expect("}"); err != nil {
+		return err
+	}
+	if namespace && !exported && !s.nested {
+		for _, d := range p.decls[start:] {
+			d.exported = false
+		}
+	}
+	return nil
+}
+
+// members parses the body of a class, interface or object type.
+func (p *parser) members() ([]member, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var members []member
+	for !p.skip("}") {
+		if p.peek().kind == tokEOF {
+			return nil, fmt.Errorf("line %d: unterminated member list", p.peek().line)
+		}
+		if p.skip(";") || p.skip(",") {
+			continue
+		}
+		m, visible, err := p.member()
+		if err != nil {
+			return nil, err
+		}
+		if visible {
+			members = append(members, m)
+		}
+		if !p.skip(";") {
+			p.skip(",")
+		}
+	}
+	return members, nil
+}
+
+// memberModifiers precede a member name. A modifier keyword directly
+// followed by punctuation is the member's own name instead (e.g. get(): T).
+var memberModifiers = map[string]bool{
+	"public": true, "private": true, "protected": true, "static": true,
+	"readonly": true, "abstract": true, "declare": true, "override": true,
+	"accessor": true, "async": true, "get": true, "set": true,
+}
+
+func (p *parser) member() (member, bool, error) {
+	m := member{kind: "property", doc: parseJSDoc(p.peek().doc)}
+	start := p.pos
+	visible := true
+	accessor := ""
+	for t := p.peek(); t.kind == tokIdent && memberModifiers[t.value]; t = p.peek() {
+		if next := p.peekAt(1); next.kind == tokPunct && next.value != "[" {
+			break
+		}
+		switch t.value {
+		case "private", "protected":
+			visible = false
+		case "static":
+			m.static = true
+		case "readonly":
+			m.readonly = true
+		case "get", "set":
+			accessor = t.value
+		}
+		p.pos++
+	}
+
+	t := p.peek()
+	switch {
+	case t.kind == tokPunct && (t.value == "(" || t.value == "<"):
+		m.kind = "call"
+	case t.kind == tokIdent && t.value == "new" && (p.peekAt(1).value == "(" || p.peekAt(1).value == "<"):
+		p.pos++
+		m.kind = "construct"
+	case t.kind == tokPunct && t.value == "[":
+		// index signature or computed name
+		p.pos++
+		inner := p.typeSpan("]")
+		if err := p.expect("]"); err != nil {
+			return m, false, err
+		}
+		m.name = "[" + inner + "]"
+		if strings.Contains(inner, ":") {
+			m.kind = "index"
+		}
+	case t.kind == tokIdent || t.kind == tokString || t.kind == tokNumber:
+		p.pos++
+		m.name = unquote(t.value)
+		if strings.HasPrefix(m.name, "#") {
+			visible = false
+		}
+	default:
+		return m, false, fmt.Errorf("line %d: unexpected %q in member list", t.line, t.value)
+	}
+	if p.skip("?") {
+		m.optional = true
+	}
+	p.skip("!")
+
+	if p.is("(") || p.is("<") {
+		var err error
+		if m.typeParams, err = p.typeParams(); err != nil {
+			return m, false, err
+		}
+		if m.params, err = p.params(); err != nil {
+			return m, false, err
+		}
+		if p.skip(":") {
+			m.returns = p.typeSpan(";", ",", "}")
+		}
+		switch {
+		case m.kind == "call" || m.kind == "construct":
+		case accessor == "get":
+			m.kind, m.typ, m.readonly = "property", m.returns, true
+		case accessor == "set":
+			m.kind, m.setter = "property", true
+			if len(m.params) > 0 {
+				m.typ = m.params[0].typ
+			}
+		case m.name == "constructor":
+			m.kind = "constructor"
+		default:
+			m.kind = "method"
+		}
+	} else if p.skip(":") {
+		m.typ = p.typeSpan(";", ",", "}", "=")
+	}
+	if p.skip("=") {
+		p.typeSpan(";", ",", "}")
+	}
+	m.signature = render(p.tokens[start:p.pos])
+	return m, visible, nil
+}
+
+func (p *parser) typeParams() ([]typeParam, error) {
+	if !p.skip("<") {
+		return nil, nil
+	}
+	var tps []typeParam
+	for !p.skip(">") {
+		if p.peek().kind == tokEOF {
+			return nil, fmt.Errorf("unterminated type parameter list")
+		}
+		// variance and const modifiers
+		for (p.isIdent("in") || p.isIdent("out") || p.isIdent("const")) && p.peekAt(1).kind == tokIdent {
+			p.pos++
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		tp := typeParam{name: name}
+		if p.isIdent("extends") {
+			p.pos++
+			tp.constraint = p.typeSpan(",", ">", "=")
+		}
+		if p.skip("=") {
+			tp.def = p.typeSpan(",", ">")
+		}
+		tps = append(tps, tp)
+		p.skip(",")
+	}
+	return tps, nil
+}
+
+// paramModifiers may precede constructor parameters declaring properties.
+var paramModifiers = map[string]bool{
+	"public": true, "private": true, "protected": true, "readonly": true, "override": true,
+}
+
+func (p *parser) params() ([]param, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var params []param
+	for !p.skip(")") {
+		if p.peek().kind == tokEOF {
+			return nil, fmt.Errorf("unterminated parameter list")
+		}
+		for paramModifiers[p.peek().value] && p.peekAt(1).kind == tokIdent {
+			p.pos++
+		}
+		var pr param
+		pr.rest = p.skip("...")
+		switch t := p.peek(); {
+		case t.kind == tokIdent:
+			pr.name = p.next().value
+		case p.is("{") || p.is("["):
+			// destructuring pattern, left unnamed
+			p.typeSpan(":", ",", ")", "?")
+		default:
+			return nil, fmt.Errorf("line %d: unexpected %q in parameter list", t.line, t.value)
+		}
+		pr.optional = p.skip("?")
+		if p.skip(":") {
+			pr.typ = p.typeSpan(",", ")", "=")
+		}
+		if p.skip("=") {
+			p.typeSpan(",", ")")
+			pr.optional = true
+		}
+		p.skip(",")
+		if pr.name != "this" {
+			params = append(params, pr)
+		}
+	}
+	return params, nil
+}
+
+// continuesType are tokens that, ending one line or starting the next,
+// show a type expression carries on past the line break.
+var continuesType = []string{"|", "&", "=>", ",", ":", "?", ".", "<", "(", "[", "{", "=", "extends", "keyof", "typeof", "infer", "is", "readonly", "unique", "new"}
+
+// typeSpan consumes a type expression up to one of the stop tokens at
+// nesting depth zero and renders it. Without a semicolon, a line break ends
+// the type unless the line plainly continues.
+func (p *parser) typeSpan(stops ...string) string {
+	start := p.pos
+	depth := 0
+loop:
+	for {
+		t := p.peek()
+		if t.kind == tokEOF {
+			break
+		}
+		if depth == 0 {
+			if t.kind != tokString && slices.Contains(stops, t.value) {
+				break
+			}
+			if p.pos > start && t.line > p.tokens[p.pos-1].line &&
+				!slices.Contains(continuesType, p.tokens[p.pos-1].value) && !slices.Contains(continuesType, t.value) {
+				break
+			}
+		}
+		if t.kind == tokPunct {
+			switch t.value {
+			case "(", "[", "{", "<":
+				depth++
+			case ")", "]", "}", ">":
+				if depth == 0 {
+					break loop
+				}
+				depth--
+			}
+		}
+		p.pos++
+	}
+	return render(p.tokens[start:p.pos])
+}
+
+// unquote strips the quotes of a string literal name.
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,15 @@
+// Typings for the embeddable widget, loaded from a script tag.
+
+/** Widget settings. */
+interface WidgetConfig {
+  container: string | HTMLElement
+  theme?: "light" | "dark"
+}
+
+/** Mounts the widget. */
+declare function mountWidget(config: WidgetConfig): () => void
+
+declare namespace AcmeWidget {
+  /** Widget version. */
+  const version: string
+}
@@ -0,0 +1,133 @@
+/**
+ * Client for the Acme order service.
+ *
+ * @packageDocumentation
+ */
+import { EventEmitter } from "events";
+import type { Paginated } from "./pagination";
+
+/** Options for {@link Client}. */
+export interface ClientOptions {
+  /** API key, from the dashboard. */
+  apiKey: string;
+  /**
+   * Service endpoint.
+   * @default "https://api.acme.test"
+   */
+  baseUrl?: string;
+  /** @default 30000 */
+  timeout?: number;
+  /** Called before each retry. */
+  onRetry?(attempt: number, error: Error): void;
+}
+
+/** Lifecycle of an order. */
+export type OrderStatus = "open" | "shipped" | "cancelled";
+
+/** An order. */
+export type Order = {
+  id: string;
+  status: OrderStatus;
+  /** Line items, in cart order. */
+  items: Item[];
+  readonly createdAt: Date;
+};
+
+export interface Item {
+  sku: string;
+  quantity: number;
+}
+
+/** Events emitted by {@link Client}. */
+export type ClientEvent = OrderCreated | OrderShipped;
+
+export interface OrderCreated { type: "created"; order: Order }
+export interface OrderShipped { type: "shipped"; order: Order; trackingId: string }
+
+/** Handles a client event. */
+export type Handler<E extends ClientEvent = ClientEvent> = (event: E) => void | Promise<void>;
+
+/** Data center an account lives in. */
+export declare enum Region {
+  /** North America. */
+  US = "us",
+  EU = "eu",
+}
+
+export declare const enum Priority {
+  Low,
+  Normal = 5,
+  High,
+}
+
+/** The order service client. */
+export declare class Client extends EventEmitter {
+  /** Creates a client; prefer {@link createClient}. */
+  constructor(options: ClientOptions);
+  /** Region the client talks to. */
+  get region(): Region;
+  readonly options: ClientOptions;
+  private secret;
+  #state: unknown;
+  /**
+   * Fetches an order.
+   *
+   * @param id - The order ID.
+   * @returns The order, or null if it does not exist.
+   * @throws {AcmeError} When the request fails.
+   * @example
+   * const order = await client.get("ord_123");
+   */
+  get(id: string): Promise<Order | null>;
+  /**
+   * Lists orders, a page at a time.
+   * @param filter Only orders in this status.
+   */
+  list(filter?: { status?: OrderStatus }): Promise<Paginated<Order>>;
+  list(cursor: string, limit?: number): Promise<Paginated<Order>>;
+  /** Subscribes to client events. */
+  on<E extends ClientEvent>(type: E["type"], handler: Handler<E>): this;
+  /**
+   * Cancels an order.
+   * @deprecated Use {@link Client.list} and update the status instead.
+   */
+  cancel(id: string): Promise<void>;
+  /** Creates a client from ACME_API_KEY. */
+  static fromEnv(env?: Record<string, string | undefined>): Client;
+  protected request<T>(path: string): Promise<T>;
+}
+
+/**
+ * Creates a client.
+ * @param options Client options.
+ */
+export declare function createClient(options: ClientOptions): Client;
+
+/** Version of the SDK. */
+export declare const VERSION: string;
+
+/** Formats an amount in cents as a currency string. */
+export declare const formatAmount: (cents: number, currency?: string) => string;
+
+/** Webhook helpers. */
+export declare namespace Webhooks {
+  /**
+   * Verifies a webhook signature.
+   * @param payload Raw request body.
+   * @param signature The Acme-Signature header.
+   * @param secret Endpoint secret.
+   */
+  function verify(payload: string, signature: string, secret: string): boolean;
+  interface Payload {
+    event: ClientEvent;
+    sentAt: string
+  }
+}
+
+interface Internal {
+  token: string;
+}
+
+declare function helper(): void;
+
+export default Client;
@@ -0,0 +1,9 @@
+/** A page of results. */
+export interface Paginated<T> extends AsyncIterable<T> {
+  data: T[];
+  /** Cursor of the next page, if there is one. */
+  nextCursor?: string;
+  [key: string]: unknown;
+}
+
+export function undocumented(value: unknown): void
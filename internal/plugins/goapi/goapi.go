@@ -0,0 +1,218 @@
+package goapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/build"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/roberthamel/skill-compiler/internal/instructions"
+	"github.com/roberthamel/skill-compiler/internal/ir"
+)
+
+// Plugin handles the exported API of a Go module's packages.
+type Plugin struct{}
+
+func New() *Plugin { return &Plugin{} }
+
+func (p *Plugin) Name() string { return "goapi" }
+
+// Detect accepts explicitly typed sources and untyped paths to a go.mod
+// file; a bare directory is too ambiguous to claim.
+func (p *Plugin) Detect(source instructions.SpecSource) bool {
+	if source.Type == "goapi" {
+		return true
+	}
+	return source.Type == "" && filepath.Base(source.Path) == "go.mod"
+}
+
+// bundle is what Fetch produces: the module's identity and the Go files of
+// its importable packages, keyed by slash-separated path relative to the
+// module root.
+type bundle struct {
+	Module    string       `json:"module"`
+	GoVersion string       `json:"goVersion,omitempty"`
+	Files     []bundleFile `json:"files"`
+}
+
+type bundleFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+func (p *Plugin) Fetch(source instructions.SpecSource) ([]byte, error) {
+	if source.Path == "" {
+		return nil, fmt.Errorf("goapi plugin: no path in spec source (only local modules are supported)")
+	}
+	dir := source.Path
+	if filepath.Base(dir) == "go.mod" {
+		dir = filepath.Dir(dir)
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	root, err := findModuleRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+	modData, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+	b := bundle{}
+	b.Module, b.GoVersion = parseGoMod(string(modData))
+	if b.Module == "" {
+		return nil, fmt.Errorf("%s: no module directive", filepath.Join(root, "go.mod"))
+	}
+
+	err = filepath.Walk(dir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, p)
+		rel = filepath.ToSlash(rel)
+		if fi.IsDir() {
+			if p != dir && (skipDir(p, fi.Name()) || source.Excludes(rel)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(fi.Name(), ".go") || strings.HasSuffix(fi.Name(), "_test.go") || source.Excludes(rel) {
+			return nil
+		}
+		// Honor build constraints and _GOOS/_GOARCH suffixes for the host
+		// platform, so each package has one consistent set of files
+		if ok, err := build.Default.MatchFile(filepath.Dir(p), fi.Name()); err != nil || !ok {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		b.Files = append(b.Files, bundleFile{Path: rel, Content: string(data)})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walking %s: %w", dir, err)
+	}
+	return json.Marshal(b)
+}
+
+// findModuleRoot returns the nearest directory at or above dir holding a
+// go.mod file.
+func findModuleRoot(dir string) (string, error) {
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+			return d, nil
+		}
+		if filepath.Dir(d) == d {
+			return "", fmt.Errorf("no go.mod found at or above %s", dir)
+		}
+	}
+}
+
+// parseGoMod reads the module path and go version from a go.mod file.
+func parseGoMod(data string) (module, goVersion string) {
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(strings.SplitN(line, "//", 2)[0])
+		if len(fields) != 2 {
+			continue
+		}
+		switch fields[0] {
+		case "module":
+			module = strings.Trim(fields[1], `"`)
+		case "go":
+			goVersion = fields[1]
+		}
+	}
+	return module, goVersion
+}
+
+// skipDir reports whether a directory holds no packages importable by the
+// module's users: internal and testdata trees, vendored code, nested
+// modules, and hidden and _-prefixed directories.
+func skipDir(p, name string) bool {
+	switch {
+	case name == "internal", name == "testdata", name == "vendor":
+		return true
+	case strings.HasPrefix(name, "."), strings.HasPrefix(name, "_"):
+		return true
+	}
+	_, err := os.Stat(filepath.Join(p, "go.mod"))
+	return err == nil
+}
+
+func (p *Plugin) Parse(raw []byte, source instructions.SpecSource) (*ir.IntermediateRepr, error) {
+	var b bundle
+	if err := json.Unmarshal(raw, &b); err != nil {
+		return nil, fmt.Errorf("parsing goapi bundle: %w", err)
+	}
+
+	byDir := make(map[string][]bundleFile)
+	for _, f := range b.Files {
+		dir := path.Dir(f.Path)
+		byDir[dir] = append(byDir[dir], f)
+	}
+	dirs := make([]string, 0, len(byDir))
+	for dir := range byDir {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	result := &ir.IntermediateRepr{
+		Metadata: map[string]string{"module": b.Module},
+	}
+	if b.GoVersion != "" {
+		result.Metadata["goVersion"] = b.GoVersion
+	}
+
+	usedIDs := make(map[string]bool)
+	usedNames := make(map[string]bool)
+	var packages []string
+	for _, dir := range dirs {
+		importPath := b.Module
+		if dir != "." {
+			importPath += "/" + dir
+		}
+		pkg, warnings := loadPackage(importPath, byDir[dir])
+		result.Warnings = append(result.Warnings, warnings...)
+		if pkg == nil {
+			continue
+		}
+		if usedNames[pkg.qualifier] {
+			pkg.qualifier = importPath
+		}
+		usedNames[pkg.qualifier] = true
+		packages = append(packages, importPath)
+		addPackage(result, pkg, usedIDs)
+	}
+	if len(packages) == 0 {
+		return nil, fmt.Errorf("no importable Go packages found in module %s", b.Module)
+	}
+	result.Metadata["packages"] = strings.Join(packages, ", ")
+	return result, nil
+}
+
+func (p *Plugin) Validate(parsed *ir.IntermediateRepr) []ir.Warning {
+	var warnings []ir.Warning
+	for _, op := range parsed.Operations {
+		if op.Description == "" {
+			warnings = append(warnings, ir.Warning{
+				Message: fmt.Sprintf("exported %s %s has no doc comment", strings.ToLower(op.Method), op.Path),
+			})
+		}
+	}
+	for _, td := range parsed.Types {
+		if td.Description == "" {
+			warnings = append(warnings, ir.Warning{
+				Message: fmt.Sprintf("exported type %s has no doc comment", td.Name),
+			})
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,185 @@
+package goapi
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/roberthamel/skill-compiler/internal/instructions"
+	"github.com/roberthamel/skill-compiler/internal/ir"
+)
+
+func TestDetect(t *testing.T) {
+	p := New()
+
+	tests := []struct {
+		name   string
+		source instructions.SpecSource
+		want   bool
+	}{
+		{"explicit type", instructions.SpecSource{Type: "goapi", Path: "."}, true},
+		{"go.mod path", instructions.SpecSource{Path: "lib/go.mod"}, true},
+		{"directory without type", instructions.SpecSource{Path: "lib/"}, false},
+		{"codebase type", instructions.SpecSource{Type: "codebase", Path: "go.mod"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Detect(tt.source); got != tt.want {
+				t.Errorf("Detect(%+v) = %v, want %v", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func parseModule(t *testing.T, source instructions.SpecSource) (*Plugin, *ir.IntermediateRepr) {
+	t.Helper()
+	p := New()
+	raw, err := p.Fetch(source)
+	if err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+	result, err := p.Parse(raw, source)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	return p, result
+}
+
+func TestParse_Module(t *testing.T) {
+	p, result := parseModule(t, instructions.SpecSource{Type: "goapi", Path: "testdata/mod"})
+
+	// internal/, cmd/ (package main), _test.go and ignored files are left out
+	if got := result.Metadata["packages"]; got != "example.com/orders, example.com/orders/money" {
+		t.Errorf("packages = %q", got)
+	}
+	if result.Metadata["module"] != "example.com/orders" || result.Metadata["goVersion"] != "1.22" {
+		t.Errorf("metadata = %v", result.Metadata)
+	}
+	if len(result.Groups) != 2 || result.Groups[0].Description != "Package orders is a client for the Acme order service." {
+		t.Errorf("groups = %+v", result.Groups)
+	}
+
+	ops := map[string]ir.Operation{}
+	for _, op := range result.Operations {
+		ops[op.ID] = op
+	}
+	for _, id := range []string{"orders.New", "orders.Client.Get", "orders.Client.List", "orders.Client.Fetch", "orders.WithTimeout", "orders.Cancel", "money.Add"} {
+		if _, ok := ops[id]; !ok {
+			t.Errorf("missing operation %s", id)
+		}
+	}
+	if len(ops) != 7 {
+		t.Errorf("got %d operations, want 7 (unexported methods skipped)", len(ops))
+	}
+
+	get := ops["orders.Client.Get"]
+	if get.Method != "METHOD" || get.Path != "example.com/orders.Client.Get" || get.Description != "Get fetches an order by ID." {
+		t.Errorf("Get = %+v", get)
+	}
+	if get.Signature != "func (c *Client) Get(ctx context.Context, id string) (*Order, error)" {
+		t.Errorf("Get signature = %q", get.Signature)
+	}
+	if len(get.Parameters) != 2 || get.Parameters[0].Type != "context.Context" || get.Parameters[1].Name != "id" {
+		t.Errorf("Get parameters = %+v", get.Parameters)
+	}
+	if resp := get.Responses[0]; resp.Body.TypeName != "(*orders.Order, error)" {
+		t.Errorf("Get returns %q, want package-qualified types", resp.Body.TypeName)
+	}
+
+	newOp := ops["orders.New"]
+	if opts := newOp.Parameters[1]; opts.Type != "...orders.Option" || opts.Required {
+		t.Errorf("New variadic parameter = %+v", opts)
+	}
+	if !ops["orders.Client.Fetch"].Deprecated {
+		t.Error("Fetch should be deprecated")
+	}
+
+	warnings := p.Validate(result)
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Message, "orders.Cancel") {
+		t.Errorf("warnings = %v, want undocumented Cancel", warnings)
+	}
+}
+
+func TestParse_Types(t *testing.T) {
+	_, result := parseModule(t, instructions.SpecSource{Path: "testdata/mod/go.mod"})
+
+	types := map[string]ir.TypeDef{}
+	for _, td := range result.Types {
+		types[td.Name] = td
+	}
+
+	cfg := types["orders.Config"]
+	if cfg.Kind != "struct" || len(cfg.Fields) != 2 {
+		t.Fatalf("Config = %+v, want two exported fields", cfg)
+	}
+	if got := cfg.Fields[0].Description; got != "BaseURL is the service endpoint. Struct tag: `json:\"baseUrl\"`." {
+		t.Errorf("BaseURL description = %q", got)
+	}
+	if got := cfg.Fields[1].Description; got != "defaults to 30s" {
+		t.Errorf("Timeout description = %q, want line comment", got)
+	}
+	if got := types["orders.Order"].Fields[2].Type; got != "money.Amount" {
+		t.Errorf("Order.Total type = %q", got)
+	}
+
+	status := types["orders.Status"]
+	if status.Kind != "enum" || strings.Join(status.Enum, ",") != "orders.StatusOpen,orders.StatusShipped,orders.StatusCancelled" {
+		t.Errorf("Status = %+v", status)
+	}
+	store := types["orders.Store"]
+	if store.Kind != "interface" || strings.Join(store.Extends, ",") != "io.Closer" || store.Fields[0].Type != "func(o *orders.Order) error" {
+		t.Errorf("Store = %+v", store)
+	}
+	if option := types["orders.Option"]; option.Kind != "func" {
+		t.Errorf("Option = %+v", option)
+	}
+}
+
+func TestParse_SameNamedPackages(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":        "module example.com/shop\n",
+		"v1/api/api.go": "package api\n\n// Order is a v1 order.\ntype Order struct{ Item Item }\n\n// Item is a line item.\ntype Item struct{}\n",
+		"v2/api/api.go": "package api\n\n// Order is a v2 order.\ntype Order struct{ Item Item }\n\n// Item is a line item.\ntype Item struct{}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, result := parseModule(t, instructions.SpecSource{Type: "goapi", Path: dir})
+
+	types := map[string]ir.TypeDef{}
+	for _, td := range result.Types {
+		types[td.Name] = td
+	}
+	if len(types) != 4 {
+		t.Fatalf("got types %v, want four distinct names", types)
+	}
+	if got := types["api.Order"].Fields[0].Type; got != "api.Item" {
+		t.Errorf("v1 Order.Item type = %q", got)
+	}
+	if got := types["example.com/shop/v2/api.Order"].Fields[0].Type; got != "example.com/shop/v2/api.Item" {
+		t.Errorf("v2 Order.Item type = %q, want import-path qualified", got)
+	}
+}
+
+func TestFetch_Subdirectory(t *testing.T) {
+	_, result := parseModule(t, instructions.SpecSource{Type: "goapi", Path: "testdata/mod/money"})
+
+	if got := result.Metadata["packages"]; got != "example.com/orders/money" {
+		t.Errorf("packages = %q, want import path from the enclosing module", got)
+	}
+}
+
+func TestFetch_Errors(t *testing.T) {
+	p := New()
+	if _, err := p.Fetch(instructions.SpecSource{Type: "goapi", URL: "https://example.com/mod"}); err == nil {
+		t.Error("expected error for URL source")
+	}
+}
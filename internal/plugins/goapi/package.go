@@ -0,0 +1,314 @@
+package goapi
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"regexp"
+	"strings"
+
+	"github.com/roberthamel/skill-compiler/internal/ir"
+)
+
+// goPackage is a parsed package and the file set its positions refer to.
+type goPackage struct {
+	fset  *token.FileSet
+	doc   *doc.Package
+	types map[string]bool // exported type names declared in the package
+
+	// qualifier prefixes the package's type names: its name, or its import
+	// path when an earlier package has the same name
+	qualifier string
+}
+
+// loadPackage parses the files of one directory. Commands (package main)
+// yield nil, as do directories whose files all fail to parse.
+func loadPackage(importPath string, files []bundleFile) (*goPackage, []ir.Warning) {
+	var warnings []ir.Warning
+	fset := token.NewFileSet()
+	byName := make(map[string][]*ast.File)
+	for _, f := range files {
+		parsed, err := parser.ParseFile(fset, f.Path, f.Content, parser.ParseComments)
+		if err != nil {
+			warnings = append(warnings, ir.Warning{Message: fmt.Sprintf("skipping %s: %v", f.Path, err)})
+			continue
+		}
+		byName[parsed.Name.Name] = append(byName[parsed.Name.Name], parsed)
+	}
+
+	// A directory holds one package; stray files (e.g. package documentation
+	// generators with their own name) lose to the majority
+	var name string
+	for n, fs := range byName {
+		if len(fs) > len(byName[name]) || (len(fs) == len(byName[name]) && n < name) {
+			name = n
+		}
+	}
+	if name == "" || name == "main" {
+		return nil, warnings
+	}
+
+	pkg, err := doc.NewFromFiles(fset, byName[name], importPath)
+	if err != nil {
+		warnings = append(warnings, ir.Warning{Message: fmt.Sprintf("skipping package %s: %v", importPath, err)})
+		return nil, warnings
+	}
+	gp := &goPackage{fset: fset, doc: pkg, types: make(map[string]bool), qualifier: pkg.Name}
+	for _, t := range pkg.Types {
+		gp.types[t.Name] = true
+	}
+	return gp, warnings
+}
+
+// addPackage adds a package's exported functions, methods and types to
+// result, with the package as the group of its functions and methods.
+func addPackage(result *ir.IntermediateRepr, gp *goPackage, usedIDs map[string]bool) {
+	pkg := gp.doc
+	group := ir.Group{Name: pkg.ImportPath, Description: strings.TrimSpace(pkg.Doc)}
+	add := func(f *doc.Func) {
+		op := gp.operation(f, usedIDs)
+		result.Operations = append(result.Operations, op)
+		group.Operations = append(group.Operations, op.ID)
+	}
+
+	for _, f := range pkg.Funcs {
+		add(f)
+	}
+	for _, t := range pkg.Types {
+		// constructors, then methods, after the type they belong to
+		for _, f := range t.Funcs {
+			add(f)
+		}
+		for _, f := range t.Methods {
+			add(f)
+		}
+		if td, ok := gp.typeDef(t); ok {
+			result.Types = append(result.Types, td)
+		}
+	}
+	if len(group.Operations) > 0 {
+		result.Groups = append(result.Groups, group)
+	}
+}
+
+// operation converts a function or method. IDs are the package-qualified
+// symbol as callers write it (orders.New, orders.Client.Get), falling back
+// to the import path when two packages share a name.
+func (gp *goPackage) operation(f *doc.Func, usedIDs map[string]bool) ir.Operation {
+	pkg := gp.doc
+	symbol := f.Name
+	method := "FUNC"
+	if f.Recv != "" {
+		recv := strings.TrimPrefix(f.Recv, "*")
+		if i := strings.Index(recv, "["); i >= 0 {
+			recv = recv[:i]
+		}
+		symbol = recv + "." + f.Name
+		method = "METHOD"
+	}
+	id := pkg.Name + "." + symbol
+	if usedIDs[id] {
+		id = pkg.ImportPath + "." + symbol
+	}
+	usedIDs[id] = true
+
+	op := ir.Operation{
+		ID:          id,
+		Name:        symbol,
+		Description: strings.TrimSpace(f.Doc),
+		Method:      method,
+		Path:        pkg.ImportPath + "." + symbol,
+		Tags:        []string{pkg.ImportPath},
+		Deprecated:  deprecated(f.Doc),
+		Signature:   gp.signature(f.Decl),
+	}
+
+	ft := f.Decl.Type
+	if ft.TypeParams != nil {
+		for _, field := range ft.TypeParams.List {
+			for _, name := range field.Names {
+				op.Parameters = append(op.Parameters, ir.Parameter{
+					Name:     name.Name,
+					In:       "type",
+					Required: true,
+					Type:     gp.typeString(field.Type),
+				})
+			}
+		}
+	}
+	argIndex := 0
+	for _, field := range ft.Params.List {
+		typ := gp.typeString(field.Type)
+		_, variadic := field.Type.(*ast.Ellipsis)
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{{Name: fmt.Sprintf("arg%d", argIndex)}}
+		}
+		for _, name := range names {
+			op.Parameters = append(op.Parameters, ir.Parameter{
+				Name:     name.Name,
+				In:       "argument",
+				Required: !variadic,
+				Type:     typ,
+			})
+			argIndex++
+		}
+	}
+
+	if ft.Results != nil && len(ft.Results.List) > 0 {
+		var results []string
+		for _, field := range ft.Results.List {
+			n := len(field.Names)
+			if n == 0 {
+				n = 1
+			}
+			for i := 0; i < n; i++ {
+				results = append(results, gp.typeString(field.Type))
+			}
+		}
+		resp := ir.Response{StatusCode: "return", Body: &ir.TypeRef{TypeName: strings.Join(results, ", ")}}
+		if len(results) > 1 {
+			resp.Body.TypeName = "(" + resp.Body.TypeName + ")"
+		}
+		if results[len(results)-1] == "error" {
+			resp.Description = "A non-nil error reports failure."
+		}
+		op.Responses = []ir.Response{resp}
+	}
+	return op
+}
+
+// typeDef converts an exported type declaration.
+func (gp *goPackage) typeDef(t *doc.Type) (ir.TypeDef, bool) {
+	var spec *ast.TypeSpec
+	for _, s := range t.Decl.Specs {
+		if ts, ok := s.(*ast.TypeSpec); ok && ts.Name.Name == t.Name {
+			spec = ts
+		}
+	}
+	if spec == nil {
+		return ir.TypeDef{}, false
+	}
+
+	td := ir.TypeDef{
+		Name:        gp.qualifier + "." + t.Name,
+		Description: strings.TrimSpace(t.Doc),
+	}
+	switch typ := spec.Type.(type) {
+	case *ast.StructType:
+		td.Kind = "struct"
+		for _, field := range typ.Fields.List {
+			if len(field.Names) == 0 {
+				td.Extends = append(td.Extends, strings.TrimPrefix(gp.typeString(field.Type), "*"))
+				continue
+			}
+			for _, name := range field.Names {
+				td.Fields = append(td.Fields, ir.TypeField{
+					Name:        name.Name,
+					Type:        gp.typeString(field.Type),
+					Description: fieldDoc(field),
+				})
+			}
+		}
+	case *ast.InterfaceType:
+		td.Kind = "interface"
+		for _, field := range typ.Methods.List {
+			if len(field.Names) == 0 {
+				td.Extends = append(td.Extends, gp.typeString(field.Type))
+				continue
+			}
+			for _, name := range field.Names {
+				td.Fields = append(td.Fields, ir.TypeField{
+					Name:        name.Name,
+					Type:        gp.typeString(field.Type),
+					Description: fieldDoc(field),
+					Required:    true,
+				})
+			}
+		}
+	default:
+		underlying := gp.typeString(spec.Type)
+		switch {
+		case spec.Assign.IsValid():
+			td.Kind = "alias"
+			td.Description = ir.JoinSentence(td.Description, "Alias for "+underlying+".")
+		case len(t.Consts) > 0:
+			td.Kind = "enum"
+			for _, v := range t.Consts {
+				for _, name := range v.Names {
+					if ast.IsExported(name) {
+						td.Enum = append(td.Enum, gp.qualifier+"."+name)
+					}
+				}
+			}
+			td.Description = ir.JoinSentence(td.Description, "Underlying type: "+underlying+".")
+		default:
+			td.Kind = "type"
+			if _, ok := spec.Type.(*ast.FuncType); ok {
+				td.Kind = "func"
+			}
+			td.Description = ir.JoinSentence(td.Description, "Underlying type: "+underlying+".")
+		}
+	}
+	return td, true
+}
+
+// signature prints a function declaration without its doc comment or body.
+func (gp *goPackage) signature(decl *ast.FuncDecl) string {
+	bare := *decl
+	bare.Doc = nil
+	bare.Body = nil
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, gp.fset, &bare); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+// identRe matches exported identifiers not already qualified by a package
+// name ("..." of a variadic parameter is not a qualifier).
+var identRe = regexp.MustCompile(`(^|\.\.\.|[^.\w])([A-Z]\w*)`)
+
+// typeString renders a type expression the way callers outside the package
+// write it, with the package's own exported types qualified (Order becomes
+// orders.Order).
+func (gp *goPackage) typeString(expr ast.Expr) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, gp.fset, expr); err != nil {
+		return ""
+	}
+	return identRe.ReplaceAllStringFunc(buf.String(), func(m string) string {
+		sub := identRe.FindStringSubmatch(m)
+		if !gp.types[sub[2]] {
+			return m
+		}
+		return sub[1] + gp.qualifier + "." + sub[2]
+	})
+}
+
+// fieldDoc returns a struct field's or interface method's documentation:
+// its doc comment, else its line comment, with any struct tag appended.
+func fieldDoc(field *ast.Field) string {
+	text := strings.TrimSpace(field.Doc.Text())
+	if text == "" {
+		text = strings.TrimSpace(field.Comment.Text())
+	}
+	if field.Tag != nil {
+		text = ir.JoinSentence(text, "Struct tag: "+field.Tag.Value+".")
+	}
+	return text
+}
+
+// deprecated reports whether a doc comment has a "Deprecated:" paragraph.
+func deprecated(text string) bool {
+	for _, para := range strings.Split(text, "\n\n") {
+		if strings.HasPrefix(strings.TrimSpace(para), "Deprecated:") {
+			return true
+		}
+	}
+	return false
+}
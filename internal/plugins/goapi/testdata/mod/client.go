@@ -0,0 +1,59 @@
+package orders
+
+import (
+	"context"
+	"time"
+
+	"example.com/orders/money"
+)
+
+// Config configures a Client.
+type Config struct {
+	// BaseURL is the service endpoint.
+	BaseURL string        `json:"baseUrl"`
+	Timeout time.Duration // defaults to 30s
+	token   string
+}
+
+// Client calls the order service. It is safe for concurrent use.
+type Client struct {
+	cfg Config
+}
+
+// Order is a placed order.
+type Order struct {
+	ID     string
+	Status Status
+	Total  money.Amount
+	Items  []Item
+}
+
+// Item is an order line.
+type Item struct {
+	SKU      string
+	Quantity int
+}
+
+// New returns a Client for cfg.
+func New(cfg Config, opts ...Option) (*Client, error) {
+	return &Client{cfg: cfg}, nil
+}
+
+// Get fetches an order by ID.
+func (c *Client) Get(ctx context.Context, id string) (*Order, error) {
+	return nil, nil
+}
+
+// List returns orders with the given status.
+func (c *Client) List(ctx context.Context, status Status, limit int) ([]Order, error) {
+	return nil, nil
+}
+
+// Fetch fetches an order.
+//
+// Deprecated: use Get.
+func (c *Client) Fetch(id string) *Order { return nil }
+
+func (c *Client) refresh() {}
+
+func Cancel(ctx context.Context, c *Client, id string) error { return nil }
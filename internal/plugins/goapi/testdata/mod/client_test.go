@@ -0,0 +1,3 @@
+package orders
+
+func TestNothing() {}
@@ -0,0 +1,2 @@
+// Package orders is a client for the Acme order service.
+package orders
@@ -0,0 +1,5 @@
+module example.com/orders
+
+go 1.22
+
+require example.com/other v1.0.0 // indirect
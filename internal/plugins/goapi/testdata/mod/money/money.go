@@ -0,0 +1,11 @@
+// Package money represents currency amounts.
+package money
+
+// Amount is a value in minor units of a currency.
+type Amount struct {
+	Minor    int64
+	Currency string // ISO 4217
+}
+
+// Add returns the sum of a and b, which must share a currency.
+func Add(a, b Amount) (Amount, error) { return a, nil }
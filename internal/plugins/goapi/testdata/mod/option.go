@@ -0,0 +1,21 @@
+package orders
+
+import (
+	"io"
+	"time"
+)
+
+// Option customizes a Client.
+type Option func(*Client)
+
+// WithTimeout sets the request timeout.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) { c.cfg.Timeout = d }
+}
+
+// Store persists orders.
+type Store interface {
+	io.Closer
+	// Save stores an order.
+	Save(o *Order) error
+}
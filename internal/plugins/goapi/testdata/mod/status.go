@@ -0,0 +1,11 @@
+package orders
+
+// Status is an order's lifecycle state.
+type Status string
+
+const (
+	StatusOpen      Status = "open"
+	StatusShipped   Status = "shipped"
+	StatusCancelled Status = "cancelled"
+	statusUnknown   Status = ""
+)
@@ -0,0 +1,224 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/roberthamel/skill-compiler/internal/fetch"
+	"github.com/roberthamel/skill-compiler/internal/instructions"
+	"github.com/roberthamel/skill-compiler/internal/ir"
+)
+
+// Plugin handles GraphQL schemas, given as SDL or an introspection result.
+type Plugin struct{}
+
+func New() *Plugin { return &Plugin{} }
+
+func (p *Plugin) Name() string { return "graphql" }
+
+func (p *Plugin) Detect(source instructions.SpecSource) bool {
+	if source.Type == "graphql" {
+		return true
+	}
+	if source.Type == "" && source.Path != "" {
+		return isSDLFile(source.Path)
+	}
+	// Introspection JSON, URL and command sources need explicit type
+	return false
+}
+
+func isSDLFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".graphql", ".graphqls", ".gql":
+		return true
+	}
+	return false
+}
+
+func (p *Plugin) Fetch(source instructions.SpecSource) ([]byte, error) {
+	if source.URL != "" {
+		// Schema files are downloaded; anything else is treated as a live
+		// endpoint and sent the introspection query.
+		ext := strings.ToLower(path.Ext(strings.SplitN(source.URL, "?", 2)[0]))
+		if isSDLFile(ext) || ext == ".json" {
+			return fetch.URL(source.URL)
+		}
+		return introspect(source.URL)
+	}
+	return fetch.Source("graphql", source)
+}
+
+// introspect posts the standard introspection query to a GraphQL endpoint.
+func introspect(endpoint string) ([]byte, error) {
+	body, _ := json.Marshal(map[string]string{"query": introspectionQuery})
+	resp, err := http.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("introspecting %s: %w", endpoint, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("introspecting %s: HTTP %d", endpoint, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (p *Plugin) Parse(raw []byte, source instructions.SpecSource) (*ir.IntermediateRepr, error) {
+	var schema *gqlSchema
+	var err error
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '{' {
+		schema, err = parseIntrospection(trimmed)
+	} else {
+		schema, err = parseSDL(string(raw))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing GraphQL schema: %w", err)
+	}
+
+	result := &ir.IntermediateRepr{
+		Metadata: make(map[string]string),
+	}
+	if schema.description != "" {
+		result.Metadata["description"] = schema.description
+	}
+	if source.URL != "" && !isSDLFile(source.URL) && !strings.HasSuffix(strings.ToLower(source.URL), ".json") {
+		result.Metadata["baseUrl"] = source.URL
+	}
+
+	roots := []struct {
+		name, method string
+	}{
+		{schema.queryType, "QUERY"},
+		{schema.mutationType, "MUTATION"},
+		{schema.subscriptionType, "SUBSCRIPTION"},
+	}
+	isRoot := make(map[string]bool)
+	seenIDs := make(map[string]bool)
+	for _, root := range roots {
+		t := schema.types[root.name]
+		if root.name == "" || t == nil {
+			continue
+		}
+		isRoot[root.name] = true
+		group := ir.Group{Name: root.name}
+		for _, f := range t.fields {
+			op := ir.Operation{
+				ID:          f.name,
+				Name:        f.name,
+				Description: f.description,
+				Method:      root.method,
+				Path:        root.name + "." + f.name,
+				Tags:        []string{root.name},
+				Deprecated:  f.deprecated,
+				Responses: []ir.Response{{
+					StatusCode: "data",
+					Body:       &ir.TypeRef{TypeName: f.typ},
+				}},
+			}
+			if f.deprecationReason != "" {
+				op.Description = strings.TrimSpace(op.Description + "\n\nDeprecated: " + f.deprecationReason)
+			}
+			for _, arg := range f.args {
+				op.Parameters = append(op.Parameters, ir.Parameter{
+					Name:        arg.name,
+					In:          "argument",
+					Description: arg.description,
+					Required:    strings.HasSuffix(arg.typ, "!") && arg.defaultValue == "",
+					Type:        arg.typ,
+					Default:     arg.defaultValue,
+				})
+			}
+			// Root types may share field names (e.g. a query and a
+			// subscription both called "order")
+			if seenIDs[op.ID] {
+				op.ID = strings.ToLower(root.method) + "_" + op.ID
+			}
+			seenIDs[op.ID] = true
+			result.Operations = append(result.Operations, op)
+			group.Operations = append(group.Operations, op.ID)
+		}
+		group.Description = t.description
+		result.Groups = append(result.Groups, group)
+	}
+
+	for _, name := range schema.order {
+		t := schema.types[name]
+		if isRoot[name] || strings.HasPrefix(name, "__") || (t.kind == kindScalar && builtinScalars[name]) {
+			continue
+		}
+		result.Types = append(result.Types, typeDef(t))
+	}
+
+	return result, nil
+}
+
+// typeDef converts a named GraphQL type into a TypeDef. Interfaces an object
+// implements become Extends; union members become OneOf variants.
+func typeDef(t *gqlType) ir.TypeDef {
+	td := ir.TypeDef{
+		Name:        t.name,
+		Kind:        irKinds[t.kind],
+		Description: t.description,
+		Extends:     t.interfaces,
+	}
+	switch t.kind {
+	case kindEnum:
+		for _, v := range t.enumValues {
+			td.Enum = append(td.Enum, v.name)
+		}
+	case kindUnion:
+		td.OneOf = t.possibleTypes
+	}
+	for _, f := range t.fields {
+		field := ir.TypeField{
+			Name:        f.name,
+			Type:        f.typ,
+			Description: f.description,
+			Required:    strings.HasSuffix(f.typ, "!"),
+		}
+		if len(f.args) > 0 {
+			args := make([]string, len(f.args))
+			for i, arg := range f.args {
+				args[i] = arg.name + ": " + arg.typ
+			}
+			field.Description = strings.TrimSpace(field.Description + " (arguments: " + strings.Join(args, ", ") + ")")
+		}
+		td.Fields = append(td.Fields, field)
+	}
+	for _, f := range t.inputFields {
+		td.Fields = append(td.Fields, ir.TypeField{
+			Name:        f.name,
+			Type:        f.typ,
+			Description: f.description,
+			Required:    strings.HasSuffix(f.typ, "!") && f.defaultValue == "",
+		})
+	}
+	return td
+}
+
+func (p *Plugin) Validate(parsed *ir.IntermediateRepr) []ir.Warning {
+	var warnings []ir.Warning
+	if len(parsed.Operations) == 0 {
+		warnings = append(warnings, ir.Warning{Message: "schema defines no query, mutation or subscription fields"})
+	}
+	for _, op := range parsed.Operations {
+		if op.Description == "" {
+			warnings = append(warnings, ir.Warning{
+				Message: fmt.Sprintf("field %s has no description", op.Path),
+			})
+		}
+		for _, param := range op.Parameters {
+			if param.Description == "" {
+				warnings = append(warnings, ir.Warning{
+					Message: fmt.Sprintf("argument %s of %s has no description", param.Name, op.Path),
+				})
+			}
+		}
+	}
+	return warnings
+}
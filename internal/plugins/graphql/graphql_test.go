@@ -0,0 +1,215 @@
+package graphql
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/roberthamel/skill-compiler/internal/instructions"
+	"github.com/roberthamel/skill-compiler/internal/ir"
+)
+
+func readTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("reading testdata/%s: %v", name, err)
+	}
+	return data
+}
+
+func TestDetect(t *testing.T) {
+	p := New()
+
+	tests := []struct {
+		name   string
+		source instructions.SpecSource
+		want   bool
+	}{
+		{"graphql file", instructions.SpecSource{Path: "schema.graphql"}, true},
+		{"graphqls file", instructions.SpecSource{Path: "schema.graphqls"}, true},
+		{"gql file", instructions.SpecSource{Path: "schema.gql"}, true},
+		{"explicit type", instructions.SpecSource{Type: "graphql", Path: "introspection.json"}, true},
+		{"endpoint", instructions.SpecSource{Type: "graphql", URL: "https://example.com/graphql"}, true},
+		{"json file without type", instructions.SpecSource{Path: "schema.json"}, false},
+		{"openapi type", instructions.SpecSource{Type: "openapi", Path: "api.yaml"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Detect(tt.source); got != tt.want {
+				t.Errorf("Detect(%+v) = %v, want %v", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_SDL(t *testing.T) {
+	p := New()
+	result, err := p.Parse(readTestdata(t, "schema.graphql"), instructions.SpecSource{Path: "testdata/schema.graphql"})
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	if got := result.Metadata["description"]; got != "Storefront API for browsing products and placing orders." {
+		t.Errorf("description = %q", got)
+	}
+
+	ops := map[string]ir.Operation{}
+	var ids []string
+	for _, op := range result.Operations {
+		ops[op.ID] = op
+		ids = append(ids, op.ID)
+	}
+	if strings.Join(ids, ",") != "product,search,legacyProducts,order,placeOrder" {
+		t.Fatalf("operations = %v, want queries (including the extension) then mutations", ids)
+	}
+
+	search := ops["search"]
+	if search.Method != "QUERY" || search.Path != "Query.search" {
+		t.Errorf("search method/path = %s %s", search.Method, search.Path)
+	}
+	if search.Description != "Full-text search across products and reviews." {
+		t.Errorf("search description = %q", search.Description)
+	}
+	if len(search.Parameters) != 2 {
+		t.Fatalf("search parameters = %+v, want term and limit", search.Parameters)
+	}
+	term, limit := search.Parameters[0], search.Parameters[1]
+	if term.Type != "String!" || !term.Required || term.In != "argument" || term.Description != "Search terms" {
+		t.Errorf("term = %+v", term)
+	}
+	if limit.Required || limit.Default != "20" {
+		t.Errorf("limit = %+v, want optional with default 20", limit)
+	}
+	if body := search.Responses[0].Body; body == nil || body.TypeName != "[SearchResult!]!" {
+		t.Errorf("search returns %+v, want [SearchResult!]!", search.Responses[0].Body)
+	}
+
+	legacy := ops["legacyProducts"]
+	if !legacy.Deprecated || !strings.Contains(legacy.Description, "Use search") {
+		t.Errorf("legacyProducts = %+v, want deprecated with reason", legacy)
+	}
+	if place := ops["placeOrder"]; place.Method != "MUTATION" || place.Parameters[0].Type != "OrderInput!" {
+		t.Errorf("placeOrder = %+v", place)
+	}
+
+	if len(result.Groups) != 2 || result.Groups[0].Name != "Query" || len(result.Groups[0].Operations) != 4 || result.Groups[1].Name != "Mutation" {
+		t.Errorf("groups = %+v, want Query (4) and Mutation", result.Groups)
+	}
+
+	types := map[string]ir.TypeDef{}
+	var names []string
+	for _, td := range result.Types {
+		types[td.Name] = td
+		names = append(names, td.Name)
+	}
+	if strings.Join(names, ",") != "DateTime,Role,Node,Product,Priced,Review,SearchResult,OrderInput,Order" {
+		t.Errorf("types = %v, want declared types without roots or built-in scalars", names)
+	}
+	if types["DateTime"].Kind != "scalar" || types["DateTime"].Description != "An ISO-8601 timestamp" {
+		t.Errorf("DateTime = %+v", types["DateTime"])
+	}
+	if role := types["Role"]; role.Kind != "enum" || len(role.Enum) != 3 {
+		t.Errorf("Role = %+v", role)
+	}
+	product := types["Product"]
+	if product.Kind != "object" || strings.Join(product.Extends, ",") != "Node,Priced" || product.Description != "A product in the catalog." {
+		t.Errorf("Product = %+v", product)
+	}
+	if len(product.Fields) != 4 || product.Fields[2].Description != "Price in cents" || !product.Fields[2].Required {
+		t.Errorf("Product fields = %+v", product.Fields)
+	}
+	if !strings.Contains(product.Fields[3].Description, "first: Int") {
+		t.Errorf("reviews description = %q, want arguments listed", product.Fields[3].Description)
+	}
+	if union := types["SearchResult"]; union.Kind != "union" || strings.Join(union.OneOf, ",") != "Product,Review" {
+		t.Errorf("SearchResult = %+v", union)
+	}
+	input := types["OrderInput"]
+	if input.Kind != "input" || len(input.Fields) != 3 || input.Fields[1].Required {
+		t.Errorf("OrderInput = %+v, want quantity optional because of its default", input)
+	}
+}
+
+func TestParse_FederationSDL(t *testing.T) {
+	p := New()
+	result, err := p.Parse(readTestdata(t, "federation.graphql"), instructions.SpecSource{Path: "testdata/federation.graphql"})
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	var ids []string
+	for _, op := range result.Operations {
+		ids = append(ids, op.Method+" "+op.ID)
+	}
+	if strings.Join(ids, ",") != "QUERY product,QUERY topProducts,MUTATION addProduct" {
+		t.Errorf("operations = %v, want the default Query and Mutation roots despite extend schema", ids)
+	}
+	for _, td := range result.Types {
+		if td.Name == "Query" || td.Name == "Mutation" {
+			t.Errorf("root type %s emitted as a plain type", td.Name)
+		}
+	}
+}
+
+func TestParse_Introspection(t *testing.T) {
+	p := New()
+	source := instructions.SpecSource{Type: "graphql", Path: "testdata/introspection.json"}
+	result, err := p.Parse(readTestdata(t, "introspection.json"), source)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	if len(result.Operations) != 2 {
+		t.Fatalf("got %d operations, want 2", len(result.Operations))
+	}
+	users := result.Operations[0]
+	if users.Path != "Root.users" || users.Responses[0].Body.TypeName != "[User!]!" {
+		t.Errorf("users = %+v", users)
+	}
+	if first := users.Parameters[1]; first.Type != "Int!" || first.Required || first.Default != "25" {
+		t.Errorf("first = %+v, want Int! with default 25", first)
+	}
+	if sub := result.Operations[1]; sub.Method != "SUBSCRIPTION" || sub.Path != "Events.userCreated" {
+		t.Errorf("subscription = %+v", sub)
+	}
+
+	var names []string
+	for _, td := range result.Types {
+		names = append(names, td.Name)
+	}
+	if strings.Join(names, ",") != "User,Role" {
+		t.Errorf("types = %v, want User and Role only", names)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	p := New()
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{"executable document", "query { users { id } }"},
+		{"unterminated type", "type Query { users: [User"},
+		{"introspection errors", `{"errors": [{"message": "introspection disabled"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := p.Parse([]byte(tt.raw), instructions.SpecSource{Type: "graphql"}); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	p := New()
+	result, err := p.Parse([]byte("type Query { ping(msg: String): String }"), instructions.SpecSource{Type: "graphql"})
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if warnings := p.Validate(result); len(warnings) != 2 {
+		t.Errorf("got %d warnings, want 2 (field and argument descriptions)", len(warnings))
+	}
+}
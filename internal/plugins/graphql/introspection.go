@@ -0,0 +1,185 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// introspectionQuery is the standard query for a schema's full type system.
+const introspectionQuery = `query IntrospectionQuery {
+  __schema {
+    description
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+  }
+}
+fragment FullType on __Type {
+  kind name description
+  fields(includeDeprecated: true) {
+    name description
+    args { ...InputValue }
+    type { ...TypeRef }
+    isDeprecated deprecationReason
+  }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) { name description isDeprecated deprecationReason }
+  possibleTypes { ...TypeRef }
+}
+fragment InputValue on __InputValue {
+  name description
+  type { ...TypeRef }
+  defaultValue
+}
+fragment TypeRef on __Type {
+  kind name
+  ofType { kind name ofType { kind name ofType { kind name ofType { kind name
+    ofType { kind name ofType { kind name ofType { kind name } } } } } } }
+}`
+
+// introspectionResult accepts both a full response ({"data": {"__schema": …}})
+// and a bare {"__schema": …} object.
+type introspectionResult struct {
+	Data   *introspectionResult `json:"data"`
+	Schema *introspectionSchema `json:"__schema"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+type introspectionSchema struct {
+	Description      string              `json:"description"`
+	QueryType        *introspectionName  `json:"queryType"`
+	MutationType     *introspectionName  `json:"mutationType"`
+	SubscriptionType *introspectionName  `json:"subscriptionType"`
+	Types            []introspectionType `json:"types"`
+}
+
+type introspectionName struct {
+	Name string `json:"name"`
+}
+
+type introspectionType struct {
+	Kind          string               `json:"kind"`
+	Name          string               `json:"name"`
+	Description   string               `json:"description"`
+	Fields        []introspectionField `json:"fields"`
+	InputFields   []introspectionInput `json:"inputFields"`
+	Interfaces    []introspectionRef   `json:"interfaces"`
+	PossibleTypes []introspectionRef   `json:"possibleTypes"`
+	EnumValues    []struct {
+		Name         string `json:"name"`
+		Description  string `json:"description"`
+		IsDeprecated bool   `json:"isDeprecated"`
+	} `json:"enumValues"`
+}
+
+type introspectionField struct {
+	Name              string               `json:"name"`
+	Description       string               `json:"description"`
+	Args              []introspectionInput `json:"args"`
+	Type              introspectionRef     `json:"type"`
+	IsDeprecated      bool                 `json:"isDeprecated"`
+	DeprecationReason string               `json:"deprecationReason"`
+}
+
+type introspectionInput struct {
+	Name         string           `json:"name"`
+	Description  string           `json:"description"`
+	Type         introspectionRef `json:"type"`
+	DefaultValue *string          `json:"defaultValue"`
+}
+
+type introspectionRef struct {
+	Kind   string            `json:"kind"`
+	Name   string            `json:"name"`
+	OfType *introspectionRef `json:"ofType"`
+}
+
+// String renders the reference in GraphQL notation, e.g. [User!]!.
+func (r introspectionRef) String() string {
+	switch r.Kind {
+	case "NON_NULL":
+		if r.OfType != nil {
+			return r.OfType.String() + "!"
+		}
+	case "LIST":
+		if r.OfType != nil {
+			return "[" + r.OfType.String() + "]"
+		}
+	}
+	return r.Name
+}
+
+// parseIntrospection decodes an introspection query result.
+func parseIntrospection(data []byte) (*gqlSchema, error) {
+	var result introspectionResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	if result.Data != nil {
+		result.Schema = result.Data.Schema
+	}
+	if result.Schema == nil {
+		if len(result.Errors) > 0 {
+			return nil, fmt.Errorf("introspection failed: %s", result.Errors[0].Message)
+		}
+		return nil, fmt.Errorf("no __schema in introspection result")
+	}
+
+	in := result.Schema
+	schema := newSchema()
+	schema.description = in.Description
+	if in.QueryType != nil {
+		schema.queryType = in.QueryType.Name
+	}
+	if in.MutationType != nil {
+		schema.mutationType = in.MutationType.Name
+	}
+	if in.SubscriptionType != nil {
+		schema.subscriptionType = in.SubscriptionType.Name
+	}
+
+	for _, it := range in.Types {
+		t := &gqlType{kind: it.Kind, name: it.Name, description: it.Description}
+		for _, f := range it.Fields {
+			field := gqlField{
+				name:              f.Name,
+				description:       f.Description,
+				typ:               f.Type.String(),
+				deprecated:        f.IsDeprecated,
+				deprecationReason: f.DeprecationReason,
+			}
+			for _, arg := range f.Args {
+				field.args = append(field.args, inputValue(arg))
+			}
+			t.fields = append(t.fields, field)
+		}
+		for _, f := range it.InputFields {
+			t.inputFields = append(t.inputFields, inputValue(f))
+		}
+		for _, ref := range it.Interfaces {
+			t.interfaces = append(t.interfaces, ref.Name)
+		}
+		for _, ref := range it.PossibleTypes {
+			if it.Kind == kindUnion {
+				t.possibleTypes = append(t.possibleTypes, ref.Name)
+			}
+		}
+		for _, v := range it.EnumValues {
+			t.enumValues = append(t.enumValues, gqlEnumValue{name: v.Name, description: v.Description, deprecated: v.IsDeprecated})
+		}
+		schema.add(t)
+	}
+	return schema, nil
+}
+
+func inputValue(in introspectionInput) gqlInputValue {
+	v := gqlInputValue{name: in.Name, description: in.Description, typ: in.Type.String()}
+	if in.DefaultValue != nil {
+		v.defaultValue = *in.DefaultValue
+	}
+	return v
+}
@@ -0,0 +1,106 @@
+package graphql
+
+// gqlSchema is the schema model both the SDL parser and the introspection
+// decoder produce. Types are kept in declaration order.
+type gqlSchema struct {
+	description      string
+	queryType        string
+	mutationType     string
+	subscriptionType string
+	types            map[string]*gqlType
+	order            []string
+}
+
+// Type kinds, named as in the introspection schema.
+const (
+	kindScalar      = "SCALAR"
+	kindObject      = "OBJECT"
+	kindInterface   = "INTERFACE"
+	kindUnion       = "UNION"
+	kindEnum        = "ENUM"
+	kindInputObject = "INPUT_OBJECT"
+)
+
+// irKinds maps GraphQL kinds to ir.TypeDef kinds.
+var irKinds = map[string]string{
+	kindScalar:      "scalar",
+	kindObject:      "object",
+	kindInterface:   "interface",
+	kindUnion:       "union",
+	kindEnum:        "enum",
+	kindInputObject: "input",
+}
+
+var builtinScalars = map[string]bool{
+	"String": true, "Int": true, "Float": true, "Boolean": true, "ID": true,
+}
+
+type gqlType struct {
+	kind          string
+	name          string
+	description   string
+	fields        []gqlField      // objects and interfaces
+	inputFields   []gqlInputValue // input objects
+	interfaces    []string
+	possibleTypes []string // union members
+	enumValues    []gqlEnumValue
+}
+
+type gqlField struct {
+	name              string
+	description       string
+	args              []gqlInputValue
+	typ               string // in GraphQL notation, e.g. [User!]!
+	deprecated        bool
+	deprecationReason string
+}
+
+type gqlInputValue struct {
+	name         string
+	description  string
+	typ          string
+	defaultValue string // GraphQL literal, empty if none
+}
+
+type gqlEnumValue struct {
+	name        string
+	description string
+	deprecated  bool
+}
+
+func newSchema() *gqlSchema {
+	return &gqlSchema{types: make(map[string]*gqlType)}
+}
+
+// add registers t, or merges its members into an existing type of the same
+// name (for type extensions).
+func (s *gqlSchema) add(t *gqlType) {
+	existing, ok := s.types[t.name]
+	if !ok {
+		s.types[t.name] = t
+		s.order = append(s.order, t.name)
+		return
+	}
+	if existing.description == "" {
+		existing.description = t.description
+	}
+	existing.fields = append(existing.fields, t.fields...)
+	existing.inputFields = append(existing.inputFields, t.inputFields...)
+	existing.interfaces = append(existing.interfaces, t.interfaces...)
+	existing.possibleTypes = append(existing.possibleTypes, t.possibleTypes...)
+	existing.enumValues = append(existing.enumValues, t.enumValues...)
+}
+
+// setDefaultRoots applies the conventional root type names when the schema
+// doesn't declare them.
+func (s *gqlSchema) setDefaultRoots() {
+	if s.queryType == "" && s.types["Query"] != nil {
+		s.queryType = "Query"
+	}
+	if s.mutationType == "" && s.types["Mutation"] != nil {
+		s.mutationType = "Mutation"
+	}
+	if s.subscriptionType == "" && s.types["Subscription"] != nil {
+		s.subscriptionType = "Subscription"
+	}
+}
@@ -0,0 +1,599 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SDL parsing. Only type system definitions are understood; executable
+// documents (queries, fragments) are rejected.
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokName
+	tokPunct
+	tokString
+	tokNumber
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	line  int
+}
+
+// lex splits SDL source into tokens, dropping whitespace, commas and comments.
+func lex(src string) ([]token, error) {
+	src = strings.TrimPrefix(src, "\ufeff")
+	var tokens []token
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "..."):
+			tokens = append(tokens, token{tokPunct, "...", line})
+			i += 3
+		case strings.ContainsRune("!$&()/:=@[]{}|", rune(c)):
+			tokens = append(tokens, token{tokPunct, string(c), line})
+			i++
+		case strings.HasPrefix(src[i:], `"""`):
+			j := i + 3
+			for j < len(src) && !strings.HasPrefix(src[j:], `"""`) {
+				if strings.HasPrefix(src[j:], `\"""`) {
+					j += 4
+					continue
+				}
+				j++
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("line %d: unterminated block string", line)
+			}
+			raw := src[i+3 : j]
+			tokens = append(tokens, token{tokString, blockString(strings.ReplaceAll(raw, `\"""`, `"""`)), line})
+			line += strings.Count(raw, "\n")
+			i = j + 3
+		case c == '"':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(src) && src[j] != '"'; j++ {
+				if src[j] == '\n' {
+					return nil, fmt.Errorf("line %d: unterminated string", line)
+				}
+				if src[j] == '\\' && j+1 < len(src) {
+					j++
+					switch src[j] {
+					case 'n':
+						b.WriteByte('\n')
+					case 't':
+						b.WriteByte('\t')
+					case 'u':
+						if j+4 < len(src) {
+							var r rune
+							if _, err := fmt.Sscanf(src[j+1:j+5], "%04x", &r); err == nil {
+								b.WriteRune(r)
+								j += 4
+								continue
+							}
+						}
+						b.WriteByte('u')
+					default:
+						b.WriteByte(src[j])
+					}
+					continue
+				}
+				b.WriteByte(src[j])
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			tokens = append(tokens, token{tokString, b.String(), line})
+			i = j + 1
+		case c == '-' || (c >= '0' && c <= '9'):
+			j := i + 1
+			for j < len(src) && (strings.ContainsRune("0123456789.eE+-", rune(src[j]))) {
+				j++
+			}
+			tokens = append(tokens, token{tokNumber, src[i:j], line})
+			i = j
+		case isNameStart(c):
+			j := i + 1
+			for j < len(src) && (isNameStart(src[j]) || (src[j] >= '0' && src[j] <= '9')) {
+				j++
+			}
+			tokens = append(tokens, token{tokName, src[i:j], line})
+			i = j
+		default:
+			return nil, fmt.Errorf("line %d: unexpected character %q", line, c)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, line: line}), nil
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// blockString applies the block string indentation rules: common indentation
+// and leading/trailing blank lines are removed.
+func blockString(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	indent := -1
+	for _, l := range lines[1:] {
+		trimmed := strings.TrimLeft(l, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(l) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+type sdlParser struct {
+	tokens []token
+	pos    int
+}
+
+// parseSDL parses GraphQL schema definition language into a schema.
+func parseSDL(src string) (*gqlSchema, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &sdlParser{tokens: tokens}
+	schema := newSchema()
+	hasSchemaDef := false
+	for p.peek().kind != tokEOF {
+		description := p.description()
+		keyword := p.next()
+		if keyword.kind == tokPunct && keyword.value == "{" {
+			return nil, fmt.Errorf("line %d: executable documents are not supported, expected a schema", keyword.line)
+		}
+		if keyword.kind != tokName {
+			return nil, fmt.Errorf("line %d: unexpected %q", keyword.line, keyword.value)
+		}
+		extend := false
+		if keyword.value == "extend" {
+			extend = true
+			keyword = p.next()
+		}
+		switch keyword.value {
+		case "schema":
+			// only a schema definition replaces the default root names;
+			// an extension (e.g. Apollo Federation's @link) does not
+			if !extend {
+				hasSchemaDef = true
+				if description != "" {
+					schema.description = description
+				}
+			}
+			if err := p.schemaDef(schema); err != nil {
+				return nil, err
+			}
+		case "scalar", "type", "interface", "union", "enum", "input":
+			t, err := p.typeDef(keyword.value)
+			if err != nil {
+				return nil, err
+			}
+			t.description = description
+			schema.add(t)
+		case "directive":
+			if err := p.skipDirectiveDef(); err != nil {
+				return nil, err
+			}
+		case "query", "mutation", "subscription", "fragment":
+			return nil, fmt.Errorf("line %d: executable documents are not supported, expected a schema", keyword.line)
+		default:
+			return nil, fmt.Errorf("line %d: unexpected %q", keyword.line, keyword.value)
+		}
+	}
+	if !hasSchemaDef {
+		schema.setDefaultRoots()
+	}
+	return schema, nil
+}
+
+func (p *sdlParser) peek() token { return p.tokens[p.pos] }
+
+func (p *sdlParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// is reports whether the next token is the given punctuator.
+func (p *sdlParser) is(punct string) bool {
+	t := p.peek()
+	return t.kind == tokPunct && t.value == punct
+}
+
+// skip consumes the given punctuator if it is next.
+func (p *sdlParser) skip(punct string) bool {
+	if p.is(punct) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *sdlParser) expect(punct string) error {
+	if !p.skip(punct) {
+		t := p.peek()
+		return fmt.Errorf("line %d: expected %q, got %q", t.line, punct, t.value)
+	}
+	return nil
+}
+
+func (p *sdlParser) name() (string, error) {
+	t := p.next()
+	if t.kind != tokName {
+		return "", fmt.Errorf("line %d: expected a name, got %q", t.line, t.value)
+	}
+	return t.value, nil
+}
+
+// description consumes an optional description string.
+func (p *sdlParser) description() string {
+	if p.peek().kind == tokString {
+		return p.next().value
+	}
+	return ""
+}
+
+func (p *sdlParser) schemaDef(schema *gqlSchema) error {
+	if _, err := p.directives(); err != nil {
+		return err
+	}
+	if !p.skip("{") {
+		return nil
+	}
+	for !p.skip("}") {
+		op, err := p.name()
+		if err != nil {
+			return err
+		}
+		if err := p.expect(":"); err != nil {
+			return err
+		}
+		typeName, err := p.name()
+		if err != nil {
+			return err
+		}
+		switch op {
+		case "query":
+			schema.queryType = typeName
+		case "mutation":
+			schema.mutationType = typeName
+		case "subscription":
+			schema.subscriptionType = typeName
+		}
+	}
+	return nil
+}
+
+func (p *sdlParser) typeDef(keyword string) (*gqlType, error) {
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	t := &gqlType{name: name}
+	switch keyword {
+	case "scalar":
+		t.kind = kindScalar
+		_, err = p.directives()
+	case "type", "interface":
+		t.kind = kindObject
+		if keyword == "interface" {
+			t.kind = kindInterface
+		}
+		if t.interfaces, err = p.implements(); err != nil {
+			return nil, err
+		}
+		if _, err = p.directives(); err != nil {
+			return nil, err
+		}
+		t.fields, err = p.fieldsDef()
+	case "union":
+		t.kind = kindUnion
+		if _, err = p.directives(); err != nil {
+			return nil, err
+		}
+		if p.skip("=") {
+			p.skip("|")
+			for {
+				member, err := p.name()
+				if err != nil {
+					return nil, err
+				}
+				t.possibleTypes = append(t.possibleTypes, member)
+				if !p.skip("|") {
+					break
+				}
+			}
+		}
+	case "enum":
+		t.kind = kindEnum
+		if _, err = p.directives(); err != nil {
+			return nil, err
+		}
+		if p.skip("{") {
+			for !p.skip("}") {
+				desc := p.description()
+				value, err := p.name()
+				if err != nil {
+					return nil, err
+				}
+				dirs, err := p.directives()
+				if err != nil {
+					return nil, err
+				}
+				_, deprecated := dirs["deprecated"]
+				t.enumValues = append(t.enumValues, gqlEnumValue{name: value, description: desc, deprecated: deprecated})
+			}
+		}
+	case "input":
+		t.kind = kindInputObject
+		if _, err = p.directives(); err != nil {
+			return nil, err
+		}
+		if p.skip("{") {
+			for !p.skip("}") {
+				v, err := p.inputValue()
+				if err != nil {
+					return nil, err
+				}
+				t.inputFields = append(t.inputFields, v)
+			}
+		}
+	}
+	return t, err
+}
+
+func (p *sdlParser) implements() ([]string, error) {
+	if t := p.peek(); t.kind != tokName || t.value != "implements" {
+		return nil, nil
+	}
+	p.next()
+	p.skip("&")
+	var names []string
+	for {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		if !p.skip("&") {
+			return names, nil
+		}
+	}
+}
+
+func (p *sdlParser) fieldsDef() ([]gqlField, error) {
+	if !p.skip("{") {
+		return nil, nil
+	}
+	var fields []gqlField
+	for !p.skip("}") {
+		if p.peek().kind == tokEOF {
+			return nil, fmt.Errorf("line %d: unterminated field list", p.peek().line)
+		}
+		f := gqlField{description: p.description()}
+		var err error
+		if f.name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if p.skip("(") {
+			for !p.skip(")") {
+				arg, err := p.inputValue()
+				if err != nil {
+					return nil, err
+				}
+				f.args = append(f.args, arg)
+			}
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if f.typ, err = p.typeRef(); err != nil {
+			return nil, err
+		}
+		dirs, err := p.directives()
+		if err != nil {
+			return nil, err
+		}
+		if reason, ok := dirs["deprecated"]; ok {
+			f.deprecated = true
+			f.deprecationReason = reason["reason"]
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+func (p *sdlParser) inputValue() (gqlInputValue, error) {
+	v := gqlInputValue{description: p.description()}
+	var err error
+	if v.name, err = p.name(); err != nil {
+		return v, err
+	}
+	if err := p.expect(":"); err != nil {
+		return v, err
+	}
+	if v.typ, err = p.typeRef(); err != nil {
+		return v, err
+	}
+	if p.skip("=") {
+		if v.defaultValue, err = p.value(); err != nil {
+			return v, err
+		}
+	}
+	_, err = p.directives()
+	return v, err
+}
+
+// typeRef parses a type reference and renders it in GraphQL notation.
+func (p *sdlParser) typeRef() (string, error) {
+	var typ string
+	if p.skip("[") {
+		inner, err := p.typeRef()
+		if err != nil {
+			return "", err
+		}
+		if err := p.expect("]"); err != nil {
+			return "", err
+		}
+		typ = "[" + inner + "]"
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return "", err
+		}
+		typ = name
+	}
+	if p.skip("!") {
+		typ += "!"
+	}
+	return typ, nil
+}
+
+// directives parses any directives, returning their arguments by directive
+// name, with argument values rendered as strings.
+func (p *sdlParser) directives() (map[string]map[string]string, error) {
+	var dirs map[string]map[string]string
+	for p.skip("@") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		args := make(map[string]string)
+		if p.skip("(") {
+			for !p.skip(")") {
+				argName, err := p.name()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expect(":"); err != nil {
+					return nil, err
+				}
+				if p.peek().kind == tokString {
+					args[argName] = p.next().value
+					continue
+				}
+				val, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				args[argName] = val
+			}
+		}
+		if dirs == nil {
+			dirs = make(map[string]map[string]string)
+		}
+		dirs[name] = args
+	}
+	return dirs, nil
+}
+
+// value parses a constant value and renders it as a GraphQL literal.
+func (p *sdlParser) value() (string, error) {
+	t := p.next()
+	switch {
+	case t.kind == tokString:
+		return fmt.Sprintf("%q", t.value), nil
+	case t.kind == tokNumber || t.kind == tokName:
+		return t.value, nil
+	case t.kind == tokPunct && t.value == "[":
+		var items []string
+		for !p.skip("]") {
+			if p.peek().kind == tokEOF {
+				return "", fmt.Errorf("line %d: unterminated list", t.line)
+			}
+			item, err := p.value()
+			if err != nil {
+				return "", err
+			}
+			items = append(items, item)
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case t.kind == tokPunct && t.value == "{":
+		var fields []string
+		for !p.skip("}") {
+			name, err := p.name()
+			if err != nil {
+				return "", err
+			}
+			if err := p.expect(":"); err != nil {
+				return "", err
+			}
+			val, err := p.value()
+			if err != nil {
+				return "", err
+			}
+			fields = append(fields, name+": "+val)
+		}
+		return "{" + strings.Join(fields, ", ") + "}", nil
+	}
+	return "", fmt.Errorf("line %d: expected a value, got %q", t.line, t.value)
+}
+
+// skipDirectiveDef consumes a directive definition, which carries nothing
+// the IR needs.
+func (p *sdlParser) skipDirectiveDef() error {
+	if err := p.expect("@"); err != nil {
+		return err
+	}
+	if _, err := p.name(); err != nil {
+		return err
+	}
+	if p.skip("(") {
+		for !p.skip(")") {
+			if _, err := p.inputValue(); err != nil {
+				return err
+			}
+		}
+	}
+	if t := p.peek(); t.kind == tokName && t.value == "repeatable" {
+		p.next()
+	}
+	if t := p.next(); t.kind != tokName || t.value != "on" {
+		return fmt.Errorf("line %d: expected \"on\" in directive definition", t.line)
+	}
+	p.skip("|")
+	for {
+		if _, err := p.name(); err != nil {
+			return err
+		}
+		if !p.skip("|") {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package jsonschema
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/roberthamel/skill-compiler/internal/fetch"
+	"github.com/roberthamel/skill-compiler/internal/instructions"
+	"github.com/roberthamel/skill-compiler/internal/ir"
+	"github.com/roberthamel/skill-compiler/internal/schema"
+)
+
+// Plugin handles standalone JSON Schema documents, such as schemas for
+// config files. The IR it produces has types but no operations.
+type Plugin struct{}
+
+func New() *Plugin { return &Plugin{} }
+
+func (p *Plugin) Name() string { return "jsonschema" }
+
+// dialectRe finds a top-level $schema key naming a JSON Schema dialect.
+var dialectRe = regexp.MustCompile(`(?m)^\s*\{?\s*"?\$schema"?\s*:\s*["']?https?://json-schema\.org/`)
+
+// schemaSuffixes are the conventional file name endings of schemas.
+var schemaSuffixes = []string{".schema.json", ".schema.yaml", ".schema.yml"}
+
+// Detect accepts explicitly typed sources, *.schema.json files and untyped
+// YAML/JSON files declaring a json-schema.org $schema. It must be
+// registered before the openapi plugin, which claims every untyped
+// YAML/JSON file.
+func (p *Plugin) Detect(source instructions.SpecSource) bool {
+	if source.Type == "jsonschema" || source.Type == "json-schema" {
+		return true
+	}
+	if source.Type != "" || source.Path == "" {
+		return false
+	}
+	lower := strings.ToLower(source.Path)
+	for _, suffix := range schemaSuffixes {
+		if strings.HasSuffix(lower, suffix) {
+			return true
+		}
+	}
+	switch filepath.Ext(lower) {
+	case ".json", ".yaml", ".yml":
+	default:
+		return false
+	}
+	return fetch.HeadMatches(source.Path, dialectRe)
+}
+
+func (p *Plugin) Fetch(source instructions.SpecSource) ([]byte, error) {
+	return fetch.Source("jsonschema", source)
+}
+
+// Parse turns the root schema and its $defs (or draft-07 definitions) into
+// types. An object root becomes a type named after its title or the file;
+// any other root is described by the "root" metadata entry alone.
+func (p *Plugin) Parse(raw []byte, source instructions.SpecSource) (*ir.IntermediateRepr, error) {
+	doc, warnings, err := schema.Load(raw, source)
+	if err != nil {
+		return nil, fmt.Errorf("parsing JSON Schema: %w", err)
+	}
+
+	result := &ir.IntermediateRepr{
+		Metadata: make(map[string]string),
+		Warnings: warnings,
+	}
+	for key, metaKey := range map[string]string{
+		"title":       "title",
+		"description": "description",
+		"$schema":     "schema",
+		"$id":         "id",
+	} {
+		if v, ok := doc[key].(string); ok && v != "" {
+			result.Metadata[metaKey] = v
+		}
+	}
+
+	named := make(map[string]*schema.Schema)
+	root := make(map[string]interface{}, len(doc))
+	for k, v := range doc {
+		switch k {
+		case "$defs", "definitions":
+			defs, _ := v.(map[string]interface{})
+			for name, def := range defs {
+				if s := schema.Decode(def); s != nil {
+					named[name] = s
+				}
+			}
+		case "$schema", "$id":
+		default:
+			root[k] = v
+		}
+	}
+
+	rootName := schema.PascalCase(result.Metadata["title"])
+	if rootName == "" {
+		rootName = schema.PascalCase(baseName(source))
+	}
+	if rootName == "" {
+		rootName = "Root"
+	}
+	_, isObject := root["properties"]
+	for _, k := range []string{"allOf", "oneOf", "anyOf"} {
+		if _, ok := root[k]; ok {
+			isObject = true
+		}
+	}
+	if isObject {
+		if _, taken := named[rootName]; taken {
+			rootName += "Document"
+		}
+		named[rootName] = schema.Decode(root)
+	}
+
+	schemas := schema.NewSet(named)
+	if isObject {
+		result.Metadata["root"] = rootName
+	} else if t := schemas.TypeName(schema.Decode(root), rootName); t != "" {
+		result.Metadata["root"] = t
+	}
+	result.Types = schemas.Types()
+	if len(result.Types) == 0 && result.Metadata["root"] == "" {
+		return nil, fmt.Errorf("schema defines no types")
+	}
+	return result, nil
+}
+
+// baseName derives a name from the schema's file or URL, without the
+// .schema.json style suffix.
+func baseName(source instructions.SpecSource) string {
+	loc := source.Path
+	if loc == "" {
+		loc = source.URL
+	}
+	if loc == "" {
+		return ""
+	}
+	base := path.Base(filepath.ToSlash(loc))
+	lower := strings.ToLower(base)
+	for _, suffix := range append(schemaSuffixes, ".json", ".yaml", ".yml") {
+		if strings.HasSuffix(lower, suffix) {
+			return base[:len(base)-len(suffix)]
+		}
+	}
+	return base
+}
+
+func (p *Plugin) Validate(parsed *ir.IntermediateRepr) []ir.Warning {
+	var warnings []ir.Warning
+	for _, td := range parsed.Types {
+		if td.Description == "" {
+			warnings = append(warnings, ir.Warning{
+				Message: fmt.Sprintf("type %s has no description", td.Name),
+			})
+		}
+		for _, f := range td.Fields {
+			if f.Description == "" && f.InheritedFrom == "" {
+				warnings = append(warnings, ir.Warning{
+					Message: fmt.Sprintf("field %s.%s has no description", td.Name, f.Name),
+				})
+			}
+		}
+	}
+	return warnings
+}
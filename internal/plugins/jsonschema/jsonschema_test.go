@@ -0,0 +1,167 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/roberthamel/skill-compiler/internal/instructions"
+	"github.com/roberthamel/skill-compiler/internal/ir"
+)
+
+func TestDetect(t *testing.T) {
+	p := New()
+
+	tests := []struct {
+		name   string
+		source instructions.SpecSource
+		want   bool
+	}{
+		{"explicit type", instructions.SpecSource{Type: "jsonschema", URL: "https://example.com/schema"}, true},
+		{"schema suffix", instructions.SpecSource{Path: "schemas/deploy.schema.json"}, true},
+		{"yaml declaring a dialect", instructions.SpecSource{Path: "testdata/pipeline.yaml"}, true},
+		{"plain json file", instructions.SpecSource{Path: "testdata/missing.json"}, false},
+		{"typed for another plugin", instructions.SpecSource{Type: "openapi", Path: "testdata/deploy.schema.json"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Detect(tt.source); got != tt.want {
+				t.Errorf("Detect(%+v) = %v, want %v", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func parseFile(t *testing.T, path string) *ir.IntermediateRepr {
+	t.Helper()
+	p := New()
+	source := instructions.SpecSource{Path: path}
+	raw, err := p.Fetch(source)
+	if err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+	result, err := p.Parse(raw, source)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	return result
+}
+
+func TestParse_ObjectRoot(t *testing.T) {
+	result := parseFile(t, "testdata/deploy.schema.json")
+
+	if len(result.Operations) != 0 {
+		t.Errorf("got %d operations, want none", len(result.Operations))
+	}
+	if result.Metadata["root"] != "DeployManifest" || result.Metadata["id"] != "https://acme.example/schemas/deploy.json" {
+		t.Errorf("metadata = %v", result.Metadata)
+	}
+
+	types := map[string]ir.TypeDef{}
+	for _, td := range result.Types {
+		types[td.Name] = td
+	}
+	if len(types) != 3 {
+		t.Fatalf("types = %v, want root plus two $defs", result.Types)
+	}
+	root := types["DeployManifest"]
+	if root.Constraints["additionalProperties"] != "false" {
+		t.Errorf("root constraints = %v", root.Constraints)
+	}
+	fields := map[string]ir.TypeField{}
+	for _, f := range root.Fields {
+		fields[f.Name] = f
+	}
+	if name := fields["name"]; !name.Required || name.Constraints["pattern"] != "^[a-z][a-z0-9-]*$" || name.Constraints["maxLength"] != "63" {
+		t.Errorf("name = %+v", name)
+	}
+	if replicas := fields["replicas"]; replicas.Default != "2" || replicas.Constraints["minimum"] != "1" {
+		t.Errorf("replicas = %+v", replicas)
+	}
+	if strategy := fields["strategy"]; strategy.Type != "string" || len(strategy.Enum) != 3 || strategy.Default != `"rolling"` {
+		t.Errorf("strategy = %+v", strategy)
+	}
+	if got := fields["ports"].Type; got != "[]Port" {
+		t.Errorf("ports type = %q", got)
+	}
+	if got := fields["env"].Type; got != "map[string]string" {
+		t.Errorf("env type = %q", got)
+	}
+	if port := types["Port"]; port.Fields[0].Constraints["maximum"] != "65535" {
+		t.Errorf("Port = %+v", port)
+	}
+
+	warnings := New().Validate(result)
+	if len(warnings) != 1 || warnings[0].Message != "field HealthCheck.intervalSeconds has no description" {
+		t.Errorf("warnings = %v", warnings)
+	}
+}
+
+func TestParse_NestedObjects(t *testing.T) {
+	result := parseFile(t, "testdata/nested.schema.json")
+
+	types := map[string]ir.TypeDef{}
+	for _, td := range result.Types {
+		types[td.Name] = td
+	}
+	field := func(typeName, fieldName string) ir.TypeField {
+		t.Helper()
+		for _, f := range types[typeName].Fields {
+			if f.Name == fieldName {
+				return f
+			}
+		}
+		t.Fatalf("%s.%s not found in %+v", typeName, fieldName, result.Types)
+		return ir.TypeField{}
+	}
+
+	if spec := field("DeployManifest", "spec"); spec.Type != "DeployManifestSpec" || !spec.Required {
+		t.Errorf("spec = %+v", spec)
+	}
+	if types["DeployManifestSpec"].Description != "Desired state of the deployment." {
+		t.Errorf("DeployManifestSpec = %+v", types["DeployManifestSpec"])
+	}
+	containers := field("DeployManifestSpec", "containers")
+	if containers.Type != "[]DeployManifestSpecContainersItem" || !containers.Required || containers.Constraints["minItems"] != "1" {
+		t.Errorf("containers = %+v", containers)
+	}
+	if image := field("DeployManifestSpecContainersItem", "image"); image.Type != "string" || !image.Required {
+		t.Errorf("image = %+v", image)
+	}
+	if got := field("DeployManifestSpecContainersItem", "resources").Type; got != "DeployManifestSpecContainersItemResources" {
+		t.Errorf("resources type = %q", got)
+	}
+	if cpu := field("DeployManifestSpecContainersItemResources", "cpu"); cpu.Constraints["pattern"] != "^[0-9]+m?$" {
+		t.Errorf("cpu = %+v", cpu)
+	}
+	if got := field("DeployManifestSpec", "selector").Type; got != "map[string]DeployManifestSpecSelectorValue" {
+		t.Errorf("selector type = %q", got)
+	}
+	if operator := field("DeployManifestSpecSelectorValue", "operator"); len(operator.Enum) != 2 {
+		t.Errorf("operator = %+v", operator)
+	}
+	if len(result.Types) != 5 {
+		t.Errorf("got %d types, want the root and four nested objects", len(result.Types))
+	}
+	if warnings := New().Validate(result); len(warnings) != 0 {
+		t.Errorf("warnings = %v", warnings)
+	}
+}
+
+func TestParse_ArrayRoot(t *testing.T) {
+	result := parseFile(t, "testdata/pipeline.yaml")
+
+	if got := result.Metadata["root"]; got != "[]Step" {
+		t.Errorf("root = %q, want []Step", got)
+	}
+	if len(result.Types) != 1 || result.Types[0].Name != "Step" {
+		t.Errorf("types = %+v, want draft-07 definitions", result.Types)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	p := New()
+	for _, src := range []string{`{"type": "string"`, `{}`} {
+		if _, err := p.Parse([]byte(src), instructions.SpecSource{Type: "jsonschema"}); err == nil {
+			t.Errorf("Parse(%q): expected error", src)
+		}
+	}
+}
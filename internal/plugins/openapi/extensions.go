@@ -0,0 +1,19 @@
+package openapi
+
+// Vendor extensions with first-class meaning to the compiler.
+const (
+	extExclude = "x-sc-exclude" // true hides the operation from generated skills
+	extHint    = "x-sc-hint"    // guidance for agents, surfaced in SKILL.md
+)
+
+// excluded reports whether x-sc-exclude is set to true on an operation or
+// path item.
+func excluded(fields map[string]interface{}) bool {
+	switch v := fields[extExclude].(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
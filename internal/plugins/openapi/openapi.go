@@ -1,22 +1,20 @@
 package openapi
 
 import (
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
-	"os"
-	"os/exec"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 
+	"github.com/roberthamel/skill-compiler/internal/fetch"
 	"github.com/roberthamel/skill-compiler/internal/instructions"
 	"github.com/roberthamel/skill-compiler/internal/ir"
+	"github.com/roberthamel/skill-compiler/internal/schema"
 	"gopkg.in/yaml.v3"
 )
 
-// Plugin handles OpenAPI 3.x spec sources.
+// Plugin handles OpenAPI 3.x and Swagger 2.0 spec sources.
 type Plugin struct{}
 
 func New() *Plugin { return &Plugin{} }
@@ -24,59 +22,117 @@ func New() *Plugin { return &Plugin{} }
 func (p *Plugin) Name() string { return "openapi" }
 
 func (p *Plugin) Detect(source instructions.SpecSource) bool {
-	if source.Type == "openapi" {
+	if source.Type == "openapi" || source.Type == "swagger" {
 		return true
 	}
+	if source.Type != "" {
+		// Explicitly typed for another plugin (e.g. a GraphQL introspection .json)
+		return false
+	}
 	if source.Path != "" {
 		ext := strings.ToLower(filepath.Ext(source.Path))
 		return ext == ".yaml" || ext == ".yml" || ext == ".json"
 	}
-	if source.URL != "" || source.Command != "" {
-		// URL and command sources need explicit type
-		return source.Type == "openapi"
-	}
+	// URL and command sources need explicit type
 	return false
 }
 
 func (p *Plugin) Fetch(source instructions.SpecSource) ([]byte, error) {
-	if source.Path != "" {
-		return os.ReadFile(source.Path)
-	}
-	if source.URL != "" {
-		resp, err := http.Get(source.URL)
-		if err != nil {
-			return nil, fmt.Errorf("fetching URL %s: %w", source.URL, err)
-		}
-		defer func() { _ = resp.Body.Close() }()
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("fetching URL %s: HTTP %d", source.URL, resp.StatusCode)
-		}
-		return io.ReadAll(resp.Body)
-	}
-	if source.Command != "" {
-		parts := strings.Fields(source.Command)
-		cmd := exec.Command(parts[0], parts[1:]...)
-		out, err := cmd.Output()
-		if err != nil {
-			return nil, fmt.Errorf("running command %q: %w", source.Command, err)
-		}
-		return out, nil
-	}
-	return nil, fmt.Errorf("openapi plugin: no path, url, or command in spec source")
+	return fetch.Source("openapi", source)
 }
 
 // openAPIDoc is a minimal representation for parsing.
 type openAPIDoc struct {
-	OpenAPI    string                          `yaml:"openapi" json:"openapi"`
-	Info       openAPIInfo                     `yaml:"info" json:"info"`
-	Paths      map[string]map[string]openAPIOp `yaml:"paths" json:"paths"`
-	Components *openAPIComponents              `yaml:"components" json:"components"`
+	OpenAPI    string                     `yaml:"openapi" json:"openapi"`
+	Info       openAPIInfo                `yaml:"info" json:"info"`
+	Servers    []openAPIServer            `yaml:"servers" json:"servers"`
+	Paths      map[string]openAPIPathItem `yaml:"paths" json:"paths"`
+	Webhooks   map[string]openAPIPathItem `yaml:"webhooks" json:"webhooks"` // 3.1
+	Components *openAPIComponents         `yaml:"components" json:"components"`
+	Security   []map[string][]string      `yaml:"security" json:"security"` // default for operations without their own
+	Extra      map[string]interface{}     `yaml:",inline" json:"-"`
+}
+
+type openAPIServer struct {
+	URL         string                           `yaml:"url" json:"url"`
+	Description string                           `yaml:"description" json:"description"`
+	Variables   map[string]openAPIServerVariable `yaml:"variables" json:"variables"`
+}
+
+type openAPIServerVariable struct {
+	Default     string   `yaml:"default" json:"default"`
+	Enum        []string `yaml:"enum" json:"enum"`
+	Description string   `yaml:"description" json:"description"`
+}
+
+// openAPIPathItem holds the operations of a path along with the fields shared
+// by all of them.
+type openAPIPathItem struct {
+	Summary     string          `yaml:"summary" json:"summary"`
+	Description string          `yaml:"description" json:"description"`
+	Parameters  []openAPIParam  `yaml:"parameters" json:"parameters"`
+	Servers     []openAPIServer `yaml:"servers" json:"servers"`
+	Get         *openAPIOp      `yaml:"get" json:"get"`
+	Put         *openAPIOp      `yaml:"put" json:"put"`
+	Post        *openAPIOp      `yaml:"post" json:"post"`
+	Delete      *openAPIOp      `yaml:"delete" json:"delete"`
+	Options     *openAPIOp      `yaml:"options" json:"options"`
+	Head        *openAPIOp      `yaml:"head" json:"head"`
+	Patch       *openAPIOp      `yaml:"patch" json:"patch"`
+	Trace       *openAPIOp      `yaml:"trace" json:"trace"`
+
+	Extra map[string]interface{} `yaml:",inline" json:"-"`
+}
+
+// operations returns the path item's operations keyed by lowercase method,
+// with path-level parameters and servers merged in. Operation-level
+// parameters override path-level ones with the same name and location, and
+// operation-level servers replace path-level ones. Operations without a
+// summary or description inherit the path's.
+func (item openAPIPathItem) operations() map[string]openAPIOp {
+	ops := make(map[string]openAPIOp)
+	for method, op := range map[string]*openAPIOp{
+		"get": item.Get, "put": item.Put, "post": item.Post, "delete": item.Delete,
+		"options": item.Options, "head": item.Head, "patch": item.Patch, "trace": item.Trace,
+	} {
+		if op == nil {
+			continue
+		}
+		merged := *op
+		merged.Parameters = nil
+		for _, param := range item.Parameters {
+			overridden := false
+			for _, opParam := range op.Parameters {
+				if opParam.Name == param.Name && opParam.In == param.In {
+					overridden = true
+					break
+				}
+			}
+			if !overridden {
+				merged.Parameters = append(merged.Parameters, param)
+			}
+		}
+		merged.Parameters = append(merged.Parameters, op.Parameters...)
+		if merged.Summary == "" && merged.Description == "" {
+			merged.Description = item.Description
+		}
+		if merged.Summary == "" {
+			merged.Summary = item.Summary
+		}
+		if len(merged.Servers) == 0 {
+			merged.Servers = item.Servers
+		}
+		ops[method] = merged
+	}
+	return ops
 }
 
 type openAPIInfo struct {
 	Title       string `yaml:"title" json:"title"`
 	Description string `yaml:"description" json:"description"`
 	Version     string `yaml:"version" json:"version"`
+
+	Extra map[string]interface{} `yaml:",inline" json:"-"`
 }
 
 type openAPIOp struct {
@@ -86,9 +142,12 @@ type openAPIOp struct {
 	Tags        []string               `yaml:"tags" json:"tags"`
 	Deprecated  bool                   `yaml:"deprecated" json:"deprecated"`
 	Security    []map[string][]string  `yaml:"security" json:"security"`
+	Servers     []openAPIServer        `yaml:"servers" json:"servers"`
 	Parameters  []openAPIParam         `yaml:"parameters" json:"parameters"`
 	RequestBody *openAPIReqBody        `yaml:"requestBody" json:"requestBody"`
 	Responses   map[string]openAPIResp `yaml:"responses" json:"responses"`
+
+	Extra map[string]interface{} `yaml:",inline" json:"-"` // unknown keys, including x-* extensions
 }
 
 type openAPIParam struct {
@@ -96,8 +155,10 @@ type openAPIParam struct {
 	In          string         `yaml:"in" json:"in"`
 	Description string         `yaml:"description" json:"description"`
 	Required    bool           `yaml:"required" json:"required"`
-	Schema      *openAPISchema `yaml:"schema" json:"schema"`
+	Schema      *schema.Schema `yaml:"schema" json:"schema"`
 	Ref         string         `yaml:"$ref" json:"$ref"`
+
+	Extra map[string]interface{} `yaml:",inline" json:"-"`
 }
 
 type openAPIReqBody struct {
@@ -107,49 +168,79 @@ type openAPIReqBody struct {
 }
 
 type openAPIMediaType struct {
-	Schema *openAPISchema `yaml:"schema" json:"schema"`
+	Schema   *schema.Schema            `yaml:"schema" json:"schema"`
+	Example  interface{}               `yaml:"example" json:"example"`
+	Examples map[string]openAPIExample `yaml:"examples" json:"examples"`
+}
+
+type openAPIExample struct {
+	Summary       string      `yaml:"summary" json:"summary"`
+	Description   string      `yaml:"description" json:"description"`
+	Value         interface{} `yaml:"value" json:"value"`
+	ExternalValue string      `yaml:"externalValue" json:"externalValue"`
 }
 
 type openAPIResp struct {
 	Description string                      `yaml:"description" json:"description"`
 	Content     map[string]openAPIMediaType `yaml:"content" json:"content"`
+	Headers     map[string]openAPIHeader    `yaml:"headers" json:"headers"`
+	Links       map[string]openAPILink      `yaml:"links" json:"links"`
 }
 
-type openAPISchema struct {
-	Ref         string                    `yaml:"$ref" json:"$ref"`
-	Type        string                    `yaml:"type" json:"type"`
-	Format      string                    `yaml:"format" json:"format"`
-	Description string                    `yaml:"description" json:"description"`
-	Properties  map[string]*openAPISchema `yaml:"properties" json:"properties"`
-	Items       *openAPISchema            `yaml:"items" json:"items"`
-	Required    []string                  `yaml:"required" json:"required"`
-	Enum        []string                  `yaml:"enum" json:"enum"`
+type openAPIHeader struct {
+	Description string         `yaml:"description" json:"description"`
+	Required    bool           `yaml:"required" json:"required"`
+	Schema      *schema.Schema `yaml:"schema" json:"schema"`
+}
+
+type openAPILink struct {
+	OperationID  string                 `yaml:"operationId" json:"operationId"`
+	OperationRef string                 `yaml:"operationRef" json:"operationRef"`
+	Parameters   map[string]interface{} `yaml:"parameters" json:"parameters"`
+	RequestBody  interface{}            `yaml:"requestBody" json:"requestBody"`
+	Description  string                 `yaml:"description" json:"description"`
 }
 
 type openAPIComponents struct {
-	Schemas         map[string]*openAPISchema         `yaml:"schemas" json:"schemas"`
+	Schemas         map[string]*schema.Schema         `yaml:"schemas" json:"schemas"`
 	SecuritySchemes map[string]*openAPISecurityScheme `yaml:"securitySchemes" json:"securitySchemes"`
 	Parameters      map[string]*openAPIParam          `yaml:"parameters" json:"parameters"`
 }
 
 type openAPISecurityScheme struct {
-	Type        string `yaml:"type" json:"type"`
-	Name        string `yaml:"name" json:"name"`
-	In          string `yaml:"in" json:"in"`
-	Scheme      string `yaml:"scheme" json:"scheme"`
-	Description string `yaml:"description" json:"description"`
+	Type             string                      `yaml:"type" json:"type"`
+	Name             string                      `yaml:"name" json:"name"`
+	In               string                      `yaml:"in" json:"in"`
+	Scheme           string                      `yaml:"scheme" json:"scheme"`
+	BearerFormat     string                      `yaml:"bearerFormat" json:"bearerFormat"`
+	Description      string                      `yaml:"description" json:"description"`
+	Flows            map[string]openAPIOAuthFlow `yaml:"flows" json:"flows"`
+	OpenIDConnectURL string                      `yaml:"openIdConnectUrl" json:"openIdConnectUrl"`
+}
+
+type openAPIOAuthFlow struct {
+	AuthorizationURL string            `yaml:"authorizationUrl" json:"authorizationUrl"`
+	TokenURL         string            `yaml:"tokenUrl" json:"tokenUrl"`
+	RefreshURL       string            `yaml:"refreshUrl" json:"refreshUrl"`
+	Scopes           map[string]string `yaml:"scopes" json:"scopes"`
 }
 
 func (p *Plugin) Parse(raw []byte, source instructions.SpecSource) (*ir.IntermediateRepr, error) {
 	// Try to resolve $ref references by building a raw document map first
-	var rawDoc map[string]interface{}
-	if err := yaml.Unmarshal(raw, &rawDoc); err != nil {
-		// Try JSON
-		if err2 := json.Unmarshal(raw, &rawDoc); err2 != nil {
-			return nil, fmt.Errorf("parsing OpenAPI document: %w", err)
+	rawDoc, err := schema.Unmarshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parsing OpenAPI document: %w", err)
+	}
+	var swaggerMeta map[string]string
+	if isSwagger2(rawDoc) {
+		swaggerMeta = map[string]string{
+			"swagger":  stringValue(rawDoc["swagger"]),
+			"host":     stringValue(rawDoc["host"]),
+			"basePath": stringValue(rawDoc["basePath"]),
 		}
+		rawDoc = convertSwagger2(rawDoc)
 	}
-	resolveRefs(rawDoc, rawDoc)
+	rawDoc, warnings := schema.Resolve(rawDoc, source)
 
 	// Re-marshal and unmarshal into typed struct
 	resolved, err := yaml.Marshal(rawDoc)
@@ -163,7 +254,7 @@ func (p *Plugin) Parse(raw []byte, source instructions.SpecSource) (*ir.Intermed
 	}
 
 	if !strings.HasPrefix(doc.OpenAPI, "3.") {
-		return nil, fmt.Errorf("unsupported OpenAPI version: %q (only 3.x supported)", doc.OpenAPI)
+		return nil, fmt.Errorf("unsupported OpenAPI version: %q (only Swagger 2.0 and OpenAPI 3.x supported)", doc.OpenAPI)
 	}
 
 	result := &ir.IntermediateRepr{
@@ -172,8 +263,30 @@ func (p *Plugin) Parse(raw []byte, source instructions.SpecSource) (*ir.Intermed
 			"description": doc.Info.Description,
 			"version":     doc.Info.Version,
 		},
+		Warnings: warnings,
+	}
+	// Document-level extensions (on the root or info object) become metadata
+	for _, extra := range []map[string]interface{}{doc.Extra, doc.Info.Extra} {
+		for k, v := range schema.Extensions(extra) {
+			result.Metadata[k] = v
+		}
+	}
+	for k, v := range swaggerMeta {
+		if v != "" {
+			result.Metadata[k] = v
+		}
+	}
+	if len(doc.Servers) > 0 {
+		result.Metadata["baseUrl"] = serverURL(doc.Servers[0])
+		result.Metadata["servers"] = describeServers(doc.Servers)
 	}
 
+	var named map[string]*schema.Schema
+	if doc.Components != nil {
+		named = doc.Components.Schemas
+	}
+	schemas := schema.NewSet(named)
+
 	// Parse operations from paths (sorted for deterministic output)
 	groupOps := make(map[string][]string)
 	sortedPaths := make([]string, 0, len(doc.Paths))
@@ -182,7 +295,10 @@ func (p *Plugin) Parse(raw []byte, source instructions.SpecSource) (*ir.Intermed
 	}
 	sort.Strings(sortedPaths)
 	for _, path := range sortedPaths {
-		methods := doc.Paths[path]
+		if excluded(doc.Paths[path].Extra) {
+			continue
+		}
+		methods := doc.Paths[path].operations()
 		sortedMethods := make([]string, 0, len(methods))
 		for method := range methods {
 			sortedMethods = append(sortedMethods, method)
@@ -190,137 +306,62 @@ func (p *Plugin) Parse(raw []byte, source instructions.SpecSource) (*ir.Intermed
 		sort.Strings(sortedMethods)
 		for _, method := range sortedMethods {
 			op := methods[method]
-			opID := op.OperationID
-			if opID == "" {
-				opID = strings.ToLower(method) + "_" + strings.ReplaceAll(strings.Trim(path, "/"), "/", "_")
-			}
-
-			desc := op.Description
-			if desc == "" {
-				desc = op.Summary
+			if excluded(op.Extra) {
+				continue
 			}
-
-			irOp := ir.Operation{
-				ID:          opID,
-				Name:        op.Summary,
-				Description: desc,
-				Method:      strings.ToUpper(method),
-				Path:        path,
-				Tags:        op.Tags,
-				Deprecated:  op.Deprecated,
-			}
-
-			// Parameters
-			for _, param := range op.Parameters {
-				irOp.Parameters = append(irOp.Parameters, ir.Parameter{
-					Name:        param.Name,
-					In:          param.In,
-					Description: param.Description,
-					Required:    param.Required,
-					Type:        schemaType(param.Schema),
-				})
+			if op.Security == nil {
+				op.Security = doc.Security
 			}
-
-			// Request body
-			if op.RequestBody != nil {
-				for ct, mt := range op.RequestBody.Content {
-					typeName := ""
-					if mt.Schema != nil && mt.Schema.Ref != "" {
-						typeName = refName(mt.Schema.Ref)
-					}
-					irOp.RequestBody = &ir.TypeRef{
-						TypeName:    typeName,
-						Description: op.RequestBody.Description,
-						ContentType: ct,
-					}
-					break // take first content type
-				}
-			}
-
-			// Responses
-			codes := make([]string, 0, len(op.Responses))
-			for code := range op.Responses {
-				codes = append(codes, code)
-			}
-			sort.Strings(codes)
-			for _, code := range codes {
-				resp := op.Responses[code]
-				irResp := ir.Response{
-					StatusCode:  code,
-					Description: resp.Description,
-				}
-				for ct, mt := range resp.Content {
-					typeName := ""
-					if mt.Schema != nil && mt.Schema.Ref != "" {
-						typeName = refName(mt.Schema.Ref)
-					}
-					irResp.Body = &ir.TypeRef{
-						TypeName:    typeName,
-						ContentType: ct,
-					}
-					break
-				}
-				irOp.Responses = append(irOp.Responses, irResp)
-			}
-
-			// Auth references (sorted for deterministic output)
-			for _, sec := range op.Security {
-				secNames := make([]string, 0, len(sec))
-				for name := range sec {
-					secNames = append(secNames, name)
-				}
-				sort.Strings(secNames)
-				irOp.Auth = append(irOp.Auth, secNames...)
-			}
-
+			irOp := parseOperation(path, method, op, schemas)
 			result.Operations = append(result.Operations, irOp)
 
 			// Group by tags
 			for _, tag := range op.Tags {
-				groupOps[tag] = append(groupOps[tag], opID)
+				groupOps[tag] = append(groupOps[tag], irOp.ID)
 			}
 		}
 	}
 
-	// Parse types from components/schemas (sorted for deterministic output)
-	if doc.Components != nil {
-		sortedSchemas := make([]string, 0, len(doc.Components.Schemas))
-		for name := range doc.Components.Schemas {
-			sortedSchemas = append(sortedSchemas, name)
-		}
-		sort.Strings(sortedSchemas)
-		for _, name := range sortedSchemas {
-			schema := doc.Components.Schemas[name]
-			td := ir.TypeDef{
-				Name:        name,
-				Description: schema.Description,
-				Enum:        schema.Enum,
+	// Parse webhooks (OpenAPI 3.1); the webhook name stands in for the path
+	sortedWebhooks := make([]string, 0, len(doc.Webhooks))
+	for name := range doc.Webhooks {
+		sortedWebhooks = append(sortedWebhooks, name)
+	}
+	sort.Strings(sortedWebhooks)
+	for _, name := range sortedWebhooks {
+		if excluded(doc.Webhooks[name].Extra) {
+			continue
+		}
+		methods := doc.Webhooks[name].operations()
+		sortedMethods := make([]string, 0, len(methods))
+		for method := range methods {
+			sortedMethods = append(sortedMethods, method)
+		}
+		sort.Strings(sortedMethods)
+		for _, method := range sortedMethods {
+			op := methods[method]
+			if excluded(op.Extra) {
+				continue
 			}
-			sortedFields := make([]string, 0, len(schema.Properties))
-			for fieldName := range schema.Properties {
-				sortedFields = append(sortedFields, fieldName)
+			if op.Security == nil {
+				op.Security = doc.Security
 			}
-			sort.Strings(sortedFields)
-			for _, fieldName := range sortedFields {
-				fieldSchema := schema.Properties[fieldName]
-				required := false
-				for _, req := range schema.Required {
-					if req == fieldName {
-						required = true
-						break
-					}
-				}
-				td.Fields = append(td.Fields, ir.TypeField{
-					Name:        fieldName,
-					Type:        schemaType(fieldSchema),
-					Description: fieldSchema.Description,
-					Required:    required,
-				})
+			irOp := parseOperation(name, method, op, schemas)
+			if op.OperationID == "" {
+				irOp.ID = "webhook_" + irOp.ID
 			}
-			result.Types = append(result.Types, td)
+			irOp.Webhook = true
+			result.Operations = append(result.Operations, irOp)
+			groupOps["webhooks"] = append(groupOps["webhooks"], irOp.ID)
 		}
+	}
 
-		// Parse auth schemes (sorted for deterministic output)
+	// Component schemas, sorted by name, then the types synthesized for
+	// request/response bodies
+	result.Types = schemas.Types()
+
+	// Parse auth schemes (sorted for deterministic output)
+	if doc.Components != nil {
 		sortedSecSchemes := make([]string, 0, len(doc.Components.SecuritySchemes))
 		for name := range doc.Components.SecuritySchemes {
 			sortedSecSchemes = append(sortedSecSchemes, name)
@@ -328,14 +369,32 @@ func (p *Plugin) Parse(raw []byte, source instructions.SpecSource) (*ir.Intermed
 		sort.Strings(sortedSecSchemes)
 		for _, name := range sortedSecSchemes {
 			scheme := doc.Components.SecuritySchemes[name]
-			result.Auth = append(result.Auth, ir.AuthScheme{
-				ID:          name,
-				Type:        scheme.Type,
-				Name:        scheme.Name,
-				In:          scheme.In,
-				Scheme:      scheme.Scheme,
-				Description: scheme.Description,
-			})
+			auth := ir.AuthScheme{
+				ID:               name,
+				Type:             scheme.Type,
+				Name:             scheme.Name,
+				In:               scheme.In,
+				Scheme:           scheme.Scheme,
+				Description:      scheme.Description,
+				BearerFormat:     scheme.BearerFormat,
+				OpenIDConnectURL: scheme.OpenIDConnectURL,
+			}
+			flowTypes := make([]string, 0, len(scheme.Flows))
+			for flowType := range scheme.Flows {
+				flowTypes = append(flowTypes, flowType)
+			}
+			sort.Strings(flowTypes)
+			for _, flowType := range flowTypes {
+				flow := scheme.Flows[flowType]
+				auth.Flows = append(auth.Flows, ir.OAuthFlow{
+					Type:             flowType,
+					AuthorizationURL: flow.AuthorizationURL,
+					TokenURL:         flow.TokenURL,
+					RefreshURL:       flow.RefreshURL,
+					Scopes:           flow.Scopes,
+				})
+			}
+			result.Auth = append(result.Auth, auth)
 		}
 	}
 
@@ -375,63 +434,265 @@ func (p *Plugin) Validate(parsed *ir.IntermediateRepr) []ir.Warning {
 	return warnings
 }
 
-// resolveRefs recursively resolves $ref pointers within the document.
-func resolveRefs(node interface{}, root map[string]interface{}) {
-	switch v := node.(type) {
-	case map[string]interface{}:
-		if ref, ok := v["$ref"].(string); ok {
-			resolved := lookupRef(ref, root)
-			if resolved != nil {
-				// Copy resolved fields into this map (in-place resolution)
-				if rm, ok := resolved.(map[string]interface{}); ok {
-					delete(v, "$ref")
-					for k, val := range rm {
-						v[k] = val
-					}
+// parseOperation converts a single path-item operation into the IR. Body
+// types that are not component schemas are registered with schemas.
+func parseOperation(path, method string, op openAPIOp, schemas *schema.Set) ir.Operation {
+	opID := op.OperationID
+	if opID == "" {
+		opID = ir.OperationID(method, path)
+	}
+
+	desc := op.Description
+	if desc == "" {
+		desc = op.Summary
+	}
+
+	irOp := ir.Operation{
+		ID:          opID,
+		Name:        op.Summary,
+		Description: desc,
+		Method:      strings.ToUpper(method),
+		Path:        path,
+		Tags:        op.Tags,
+		Deprecated:  op.Deprecated,
+		Extensions:  schema.Extensions(op.Extra),
+	}
+	if hint, ok := op.Extra[extHint]; ok {
+		irOp.Hint = schema.FormatValue(hint)
+	}
+	if len(op.Servers) > 0 {
+		irOp.BaseURL = serverURL(op.Servers[0])
+	}
+
+	// Parameters
+	for _, param := range op.Parameters {
+		irOp.Parameters = append(irOp.Parameters, ir.Parameter{
+			Name:        param.Name,
+			In:          param.In,
+			Description: param.Description,
+			Required:    param.Required,
+			Type:        schema.TypeOf(param.Schema),
+			Extensions:  schema.Extensions(param.Extra),
+		})
+	}
+
+	// Request body
+	if op.RequestBody != nil {
+		irOp.RequestBody = parseContent(op.RequestBody.Content, schema.PascalCase(opID)+"Request", schemas)
+		if irOp.RequestBody != nil {
+			irOp.RequestBody.Description = op.RequestBody.Description
+		}
+	}
+
+	// Responses
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		resp := op.Responses[code]
+		irResp := ir.Response{
+			StatusCode:  code,
+			Description: resp.Description,
+		}
+		suggested := schema.PascalCase(opID) + "Response"
+		if !strings.HasPrefix(code, "2") {
+			suggested = schema.PascalCase(opID) + schema.PascalCase(code) + "Response"
+		}
+		irResp.Body = parseContent(resp.Content, suggested, schemas)
+
+		headerNames := make([]string, 0, len(resp.Headers))
+		for name := range resp.Headers {
+			headerNames = append(headerNames, name)
+		}
+		sort.Strings(headerNames)
+		for _, name := range headerNames {
+			h := resp.Headers[name]
+			irResp.Headers = append(irResp.Headers, ir.Parameter{
+				Name:        name,
+				In:          "header",
+				Description: h.Description,
+				Required:    h.Required,
+				Type:        schema.TypeOf(h.Schema),
+			})
+		}
+
+		linkNames := make([]string, 0, len(resp.Links))
+		for name := range resp.Links {
+			linkNames = append(linkNames, name)
+		}
+		sort.Strings(linkNames)
+		for _, name := range linkNames {
+			l := resp.Links[name]
+			link := ir.Link{
+				Name:        name,
+				OperationID: l.OperationID,
+				Description: l.Description,
+			}
+			if link.OperationID == "" {
+				link.OperationID = l.OperationRef
+			}
+			if len(l.Parameters) > 0 {
+				link.Parameters = make(map[string]string, len(l.Parameters))
+				for param, expr := range l.Parameters {
+					link.Parameters[param] = schema.FormatValue(expr)
 				}
 			}
+			if l.RequestBody != nil {
+				link.RequestBody = schema.FormatValue(l.RequestBody)
+			}
+			irResp.Links = append(irResp.Links, link)
 		}
-		for _, val := range v {
-			resolveRefs(val, root)
+		irOp.Responses = append(irOp.Responses, irResp)
+	}
+
+	// Auth references (sorted for deterministic output). Each security
+	// requirement is an alternative; a scheme listed in several of them is
+	// recorded once, with the union of its scopes.
+	seenAuth := make(map[string]bool)
+	for _, sec := range op.Security {
+		secNames := make([]string, 0, len(sec))
+		for name := range sec {
+			secNames = append(secNames, name)
 		}
-	case []interface{}:
-		for _, item := range v {
-			resolveRefs(item, root)
+		sort.Strings(secNames)
+		for _, name := range secNames {
+			if !seenAuth[name] {
+				seenAuth[name] = true
+				irOp.Auth = append(irOp.Auth, name)
+			}
+			for _, scope := range sec[name] {
+				if irOp.Scopes == nil {
+					irOp.Scopes = make(map[string][]string)
+				}
+				if !slices.Contains(irOp.Scopes[name], scope) {
+					irOp.Scopes[name] = append(irOp.Scopes[name], scope)
+				}
+			}
 		}
 	}
+
+	return irOp
 }
 
-// lookupRef resolves a JSON pointer like #/components/schemas/Foo.
-func lookupRef(ref string, root map[string]interface{}) interface{} {
-	if !strings.HasPrefix(ref, "#/") {
-		return nil // external refs not supported in v1
+// parseContent converts a content map into a TypeRef listing every media type
+// (sorted for deterministic output) with its examples. The preferred media
+// type, JSON if available, is also recorded on the TypeRef itself.
+func parseContent(content map[string]openAPIMediaType, suggested string, schemas *schema.Set) *ir.TypeRef {
+	if len(content) == 0 {
+		return nil
+	}
+	types := make([]string, 0, len(content))
+	for ct := range content {
+		types = append(types, ct)
+	}
+	sort.Strings(types)
+
+	ref := &ir.TypeRef{}
+	for _, ct := range types {
+		mt := content[ct]
+		irMT := ir.MediaType{
+			ContentType: ct,
+			TypeName:    schemas.TypeName(mt.Schema, suggested),
+			Examples:    mediaExamples(ct, mt),
+		}
+		ref.Content = append(ref.Content, irMT)
 	}
-	parts := strings.Split(ref[2:], "/")
-	var current interface{} = root
-	for _, part := range parts {
-		m, ok := current.(map[string]interface{})
-		if !ok {
-			return nil
+
+	preferred := ref.Content[0]
+	for _, mt := range ref.Content {
+		if schema.IsJSONMediaType(mt.ContentType) {
+			preferred = mt
+			break
 		}
-		current = m[part]
 	}
-	return current
+	ref.TypeName = preferred.TypeName
+	ref.ContentType = preferred.ContentType
+	return ref
 }
 
-func schemaType(s *openAPISchema) string {
-	if s == nil {
-		return ""
+// mediaExamples collects the media type's example and named examples, falling
+// back to examples declared on its schema.
+func mediaExamples(ct string, mt openAPIMediaType) []ir.Example {
+	format := schema.FormatValue
+	if schema.IsJSONMediaType(ct) {
+		format = schema.JSONValue
+	}
+	var examples []ir.Example
+	if mt.Example != nil {
+		examples = append(examples, ir.Example{Value: format(mt.Example)})
+	}
+	names := make([]string, 0, len(mt.Examples))
+	for name := range mt.Examples {
+		names = append(names, name)
 	}
-	if s.Type == "array" && s.Items != nil {
-		return "[]" + schemaType(s.Items)
+	sort.Strings(names)
+	for _, name := range names {
+		ex := mt.Examples[name]
+		irEx := ir.Example{
+			Name:          name,
+			Summary:       ex.Summary,
+			ExternalValue: ex.ExternalValue,
+		}
+		if irEx.Summary == "" {
+			irEx.Summary = ex.Description
+		}
+		if ex.Value != nil {
+			irEx.Value = format(ex.Value)
+		}
+		examples = append(examples, irEx)
+	}
+	if len(examples) == 0 && mt.Schema != nil {
+		if mt.Schema.Example != nil {
+			examples = append(examples, ir.Example{Value: format(mt.Schema.Example)})
+		}
+		for _, v := range mt.Schema.Examples {
+			examples = append(examples, ir.Example{Value: format(v)})
+		}
 	}
-	if s.Format != "" {
-		return s.Type + "(" + s.Format + ")"
+	return examples
+}
+
+// serverURL expands a server URL template using each variable's default.
+func serverURL(server openAPIServer) string {
+	url := server.URL
+	for name, v := range server.Variables {
+		url = strings.ReplaceAll(url, "{"+name+"}", v.Default)
 	}
-	return s.Type
+	return url
 }
 
-func refName(ref string) string {
-	parts := strings.Split(ref, "/")
-	return parts[len(parts)-1]
+// describeServers renders one line per server: the URL template, its
+// description, and the allowed values of each variable.
+func describeServers(servers []openAPIServer) string {
+	lines := make([]string, 0, len(servers))
+	for _, server := range servers {
+		line := server.URL
+		if server.Description != "" {
+			line += " - " + server.Description
+		}
+		names := make([]string, 0, len(server.Variables))
+		for name := range server.Variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var vars []string
+		for _, name := range names {
+			v := server.Variables[name]
+			desc := fmt.Sprintf("%s (default %q", name, v.Default)
+			if len(v.Enum) > 0 {
+				desc += ", one of " + strings.Join(v.Enum, ", ")
+			}
+			if v.Description != "" {
+				desc += ": " + v.Description
+			}
+			vars = append(vars, desc+")")
+		}
+		if len(vars) > 0 {
+			line += "; variables: " + strings.Join(vars, ", ")
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
 }
@@ -1,12 +1,15 @@
 package openapi
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/roberthamel/skill-compiler/internal/instructions"
+	"github.com/roberthamel/skill-compiler/internal/ir"
 )
 
 func readTestdata(t *testing.T, name string) []byte {
@@ -30,8 +33,10 @@ func TestDetect(t *testing.T) {
 		{"yml file", instructions.SpecSource{Path: "api.yml"}, true},
 		{"json file", instructions.SpecSource{Path: "api.json"}, true},
 		{"explicit type", instructions.SpecSource{Type: "openapi", URL: "http://example.com"}, true},
+		{"swagger type", instructions.SpecSource{Type: "swagger", URL: "http://example.com"}, true},
 		{"cli type", instructions.SpecSource{Type: "cli", Binary: "kubectl"}, false},
 		{"go file", instructions.SpecSource{Path: "main.go"}, false},
+		{"json file typed for another plugin", instructions.SpecSource{Type: "graphql", Path: "schema.json"}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -156,3 +161,514 @@ paths:
 		t.Errorf("expected warning about parameter 'limit' missing description, got %v", warnings)
 	}
 }
+
+func TestParse_ExternalRefs(t *testing.T) {
+	p := New()
+	path := filepath.Join("testdata", "split", "openapi.yaml")
+	data := readTestdata(t, filepath.Join("split", "openapi.yaml"))
+
+	result, err := p.Parse(data, instructions.SpecSource{Path: path})
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	if len(result.Operations) != 1 {
+		t.Fatalf("got %d operations, want 1", len(result.Operations))
+	}
+	params := result.Operations[0].Parameters
+	if len(params) != 1 || params[0].Name != "page" || params[0].Type != "integer" {
+		t.Errorf("parameters = %+v, want [page integer] from common.yaml", params)
+	}
+
+	types := map[string]int{}
+	for _, td := range result.Types {
+		types[td.Name] = len(td.Fields)
+	}
+	if types["Order"] != 2 {
+		t.Errorf("Order has %d fields, want 2 (resolved from schemas/order.yaml)", types["Order"])
+	}
+	if types["Category"] != 2 {
+		t.Errorf("Category has %d fields, want 2", types["Category"])
+	}
+}
+
+func TestParse_CircularRef(t *testing.T) {
+	p := New()
+	path := filepath.Join("testdata", "split", "openapi.yaml")
+	data := readTestdata(t, filepath.Join("split", "openapi.yaml"))
+
+	result, err := p.Parse(data, instructions.SpecSource{Path: path})
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	found := 0
+	for _, w := range result.Warnings {
+		if strings.Contains(w.Message, "circular $ref") {
+			found++
+		}
+	}
+	if found != 1 {
+		t.Errorf("got %d circular $ref warnings, want 1", found)
+	}
+}
+
+func TestParse_URLRefs(t *testing.T) {
+	srv := httptest.NewServer(http.FileServer(http.Dir(filepath.Join("testdata", "split"))))
+	defer srv.Close()
+
+	p := New()
+	source := instructions.SpecSource{Type: "openapi", URL: srv.URL + "/openapi.yaml"}
+	raw, err := p.Fetch(source)
+	if err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+	result, err := p.Parse(raw, source)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if len(result.Operations) != 1 || len(result.Operations[0].Parameters) != 1 {
+		t.Fatalf("operations = %+v, want listOrders with page parameter", result.Operations)
+	}
+	for _, w := range p.Validate(result) {
+		if strings.Contains(w.Message, "unresolved $ref") {
+			t.Errorf("unexpected warning: %s", w)
+		}
+	}
+}
+
+func TestParse_OpenAPI31(t *testing.T) {
+	p := New()
+	data := readTestdata(t, "openapi31.yaml")
+
+	result, err := p.Parse(data, instructions.SpecSource{Path: "testdata/openapi31.yaml"})
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	if len(result.Operations) != 2 {
+		t.Fatalf("got %d operations, want 2 (getEvent + webhook)", len(result.Operations))
+	}
+	if got := result.Operations[0].Parameters[0].Type; got != "string" {
+		t.Errorf("id param type = %q, want %q", got, "string")
+	}
+	webhook := result.Operations[1]
+	if !webhook.Webhook || webhook.ID != "webhook_post_eventCreated" {
+		t.Errorf("webhook op = %+v, want webhook_post_eventCreated marked as webhook", webhook)
+	}
+
+	fields := map[string]ir.TypeField{}
+	for _, td := range result.Types {
+		for _, f := range td.Fields {
+			fields[td.Name+"."+f.Name] = f
+		}
+	}
+	if got := fields["Attribute.value"].Type; got != "string|integer" {
+		t.Errorf("$defs Attribute.value type = %q, want %q", got, "string|integer")
+	}
+	if got := fields["Event.location"].Type; got != "[number, number]" {
+		t.Errorf("Event.location type = %q, want %q", got, "[number, number]")
+	}
+	if note := fields["Event.note"]; !note.Nullable || note.Type != "string" || len(note.Examples) != 2 {
+		t.Errorf("Event.note = %+v, want nullable string with 2 examples", note)
+	}
+	if got := fields["Event.kind"].Const; got != `"audit"` {
+		t.Errorf("Event.kind const = %q, want %q", got, `"audit"`)
+	}
+}
+
+func TestParse_Swagger2(t *testing.T) {
+	p := New()
+	data := readTestdata(t, "swagger.yaml")
+
+	result, err := p.Parse(data, instructions.SpecSource{Path: "testdata/swagger.yaml"})
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	if result.Metadata["basePath"] != "/v1" || result.Metadata["host"] != "api.example.com" {
+		t.Errorf("metadata = %v, want host and basePath", result.Metadata)
+	}
+
+	ops := map[string]ir.Operation{}
+	for _, op := range result.Operations {
+		ops[op.ID] = op
+	}
+	if len(ops) != 3 {
+		t.Fatalf("got %d operations, want 3", len(ops))
+	}
+
+	get := ops["getItem"]
+	if len(get.Parameters) != 1 || get.Parameters[0].Name != "itemId" || get.Parameters[0].Type != "string" {
+		t.Errorf("getItem parameters = %+v, want path-level itemId", get.Parameters)
+	}
+	if len(get.Responses) != 2 || get.Responses[1].Description != "Item not found" {
+		t.Errorf("getItem responses = %+v, want 200 and shared 404", get.Responses)
+	}
+
+	update := ops["updateItem"]
+	if update.RequestBody == nil || update.RequestBody.ContentType != "application/json" {
+		t.Errorf("updateItem request body = %+v, want application/json body", update.RequestBody)
+	}
+	for _, param := range update.Parameters {
+		if param.In == "body" {
+			t.Error("body parameter should become the request body")
+		}
+	}
+
+	upload := ops["uploadImage"]
+	if upload.RequestBody == nil || upload.RequestBody.ContentType != "multipart/form-data" {
+		t.Errorf("uploadImage request body = %+v, want multipart/form-data", upload.RequestBody)
+	}
+
+	// Item and Error from definitions, plus the synthesized form body type
+	if len(result.Types) != 3 {
+		t.Errorf("got %d types, want 3", len(result.Types))
+	}
+	auth := map[string]ir.AuthScheme{}
+	for _, a := range result.Auth {
+		auth[a.ID] = a
+	}
+	if auth["basicAuth"].Type != "http" || auth["basicAuth"].Scheme != "basic" {
+		t.Errorf("basicAuth = %+v, want http/basic", auth["basicAuth"])
+	}
+	if auth["oauth"].Type != "oauth2" {
+		t.Errorf("oauth = %+v, want oauth2", auth["oauth"])
+	}
+}
+
+func TestParse_Composition(t *testing.T) {
+	p := New()
+	data := readTestdata(t, "composition.yaml")
+
+	result, err := p.Parse(data, instructions.SpecSource{Path: "testdata/composition.yaml"})
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	types := map[string]ir.TypeDef{}
+	for _, td := range result.Types {
+		types[td.Name] = td
+	}
+
+	dog := types["Dog"]
+	if len(dog.Extends) != 1 || dog.Extends[0] != "Pet" {
+		t.Errorf("Dog.Extends = %v, want [Pet]", dog.Extends)
+	}
+	dogFields := map[string]ir.TypeField{}
+	for _, f := range dog.Fields {
+		dogFields[f.Name] = f
+	}
+	if len(dogFields) != 3 {
+		t.Errorf("Dog has %d fields, want 3 (2 inherited + packSize)", len(dogFields))
+	}
+	if f := dogFields["name"]; f.InheritedFrom != "Pet" || !f.Required {
+		t.Errorf("Dog.name = %+v, want required field inherited from Pet", f)
+	}
+	if f := dogFields["packSize"]; f.InheritedFrom != "" || !f.Required {
+		t.Errorf("Dog.packSize = %+v, want required own field", f)
+	}
+
+	anyPet := types["AnyPet"]
+	if strings.Join(anyPet.OneOf, ",") != "Cat,Dog" {
+		t.Errorf("AnyPet.OneOf = %v, want [Cat Dog]", anyPet.OneOf)
+	}
+	if anyPet.Discriminator == nil || anyPet.Discriminator.Property != "petType" || anyPet.Discriminator.Mapping["dog"] != "Dog" {
+		t.Errorf("AnyPet.Discriminator = %+v, want petType with dog -> Dog", anyPet.Discriminator)
+	}
+
+	owner := map[string]string{}
+	for _, f := range types["Owner"].Fields {
+		owner[f.Name] = f.Type
+	}
+	if owner["pets"] != "[]AnyPet" {
+		t.Errorf("Owner.pets type = %q, want %q", owner["pets"], "[]AnyPet")
+	}
+	if owner["contact"] != "string|integer" {
+		t.Errorf("Owner.contact type = %q, want %q", owner["contact"], "string|integer")
+	}
+
+	// inline variants get their own types instead of rendering as object|object
+	if owner["payment"] != "OwnerPaymentVariant1|OwnerPaymentVariant2" {
+		t.Errorf("Owner.payment type = %q", owner["payment"])
+	}
+	if f := types["OwnerPaymentVariant2"].Fields; len(f) != 1 || f[0].Name != "cardNumber" {
+		t.Errorf("OwnerPaymentVariant2 fields = %+v", f)
+	}
+	if got := strings.Join(types["Refund"].AnyOf, ","); got != "BankTransfer,RefundVariant2" {
+		t.Errorf("Refund.AnyOf = %q, want the variant title, then a positional name", got)
+	}
+	if f := types["RefundVariant2"].Fields; len(f) != 1 || f[0].Name != "voucherCode" {
+		t.Errorf("RefundVariant2 fields = %+v", f)
+	}
+}
+
+func TestParse_BodyTypeNames(t *testing.T) {
+	p := New()
+	data := readTestdata(t, "inline-bodies.yaml")
+
+	result, err := p.Parse(data, instructions.SpecSource{Path: "testdata/inline-bodies.yaml"})
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	ops := map[string]ir.Operation{}
+	for _, op := range result.Operations {
+		ops[op.ID] = op
+	}
+	create := ops["createOrder"]
+	if create.RequestBody == nil || create.RequestBody.TypeName != "CreateOrderRequest" {
+		t.Errorf("createOrder request body = %+v, want CreateOrderRequest", create.RequestBody)
+	}
+	bodies := map[string]string{}
+	for _, r := range create.Responses {
+		if r.Body != nil {
+			bodies[r.StatusCode] = r.Body.TypeName
+		}
+	}
+	if bodies["201"] != "Order" {
+		t.Errorf("201 body = %q, want referenced type Order", bodies["201"])
+	}
+	if bodies["422"] != "CreateOrder422Response" {
+		t.Errorf("422 body = %q, want CreateOrder422Response", bodies["422"])
+	}
+	list := ops["list_orders"]
+	if got := list.Responses[0].Body.TypeName; got != "[]ListOrdersResponseItem" {
+		t.Errorf("list_orders 200 body = %q, want []ListOrdersResponseItem", got)
+	}
+
+	types := map[string]ir.TypeDef{}
+	for _, td := range result.Types {
+		types[td.Name] = td
+	}
+	for _, name := range []string{"Order", "CreateOrderRequest", "CreateOrder422Response", "ListOrdersResponseItem"} {
+		if _, ok := types[name]; !ok {
+			t.Errorf("missing type %q", name)
+		}
+	}
+	if len(types["CreateOrderRequest"].Fields) != 2 {
+		t.Errorf("CreateOrderRequest fields = %+v, want sku and quantity", types["CreateOrderRequest"].Fields)
+	}
+}
+
+func TestParse_ContentTypesAndExamples(t *testing.T) {
+	p := New()
+	data := readTestdata(t, "examples.yaml")
+
+	result, err := p.Parse(data, instructions.SpecSource{Path: "testdata/examples.yaml"})
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	body := result.Operations[0].RequestBody
+	if body == nil || len(body.Content) != 3 {
+		t.Fatalf("request body = %+v, want 3 media types", body)
+	}
+	if body.ContentType != "application/json" || body.TypeName != "CreateNoteRequest" {
+		t.Errorf("preferred media type = %s %s, want application/json CreateNoteRequest", body.ContentType, body.TypeName)
+	}
+
+	content := map[string]ir.MediaType{}
+	for _, mt := range body.Content {
+		content[mt.ContentType] = mt
+	}
+	if got := content["application/x-www-form-urlencoded"].TypeName; got != "CreateNoteRequest" {
+		t.Errorf("form body type = %q, want shared CreateNoteRequest", got)
+	}
+	if ex := content["text/plain"].Examples; len(ex) != 1 || ex[0].Value != "Buy milk" {
+		t.Errorf("text/plain examples = %+v, want raw text example", ex)
+	}
+	jsonEx := content["application/json"].Examples
+	if len(jsonEx) != 2 || jsonEx[0].Name != "empty" || jsonEx[0].Value != `{"text":""}` || jsonEx[1].Summary != "A shopping reminder" {
+		t.Errorf("application/json examples = %+v, want named examples with resolved $ref", jsonEx)
+	}
+
+	resp := result.Operations[0].Responses[0].Body
+	if resp == nil || len(resp.Content) != 1 || len(resp.Content[0].Examples) != 1 {
+		t.Fatalf("response body = %+v, want schema example as fallback", resp)
+	}
+	if got := resp.Content[0].Examples[0].Value; got != `{"id":"n_1","text":"Buy milk"}` {
+		t.Errorf("response example = %s, want schema example", got)
+	}
+}
+
+func TestParse_PathItems(t *testing.T) {
+	p := New()
+	data := readTestdata(t, "path-items.yaml")
+
+	result, err := p.Parse(data, instructions.SpecSource{Path: "testdata/path-items.yaml"})
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	if len(result.Operations) != 2 {
+		t.Fatalf("got %d operations, want 2 (path-level keys are not methods)", len(result.Operations))
+	}
+	if got := result.Metadata["baseUrl"]; got != "https://us.api.example.com/v1" {
+		t.Errorf("baseUrl = %q, want default-expanded first server", got)
+	}
+	if !strings.Contains(result.Metadata["servers"], `region (default "us", one of us, eu)`) {
+		t.Errorf("servers = %q, want region variable described", result.Metadata["servers"])
+	}
+
+	ops := map[string]ir.Operation{}
+	for _, op := range result.Operations {
+		ops[op.ID] = op
+	}
+	del := ops["deleteAccount"]
+	if len(del.Parameters) != 2 {
+		t.Errorf("deleteAccount parameters = %+v, want both path-level parameters", del.Parameters)
+	}
+	if del.Name != "A single account" {
+		t.Errorf("deleteAccount name = %q, want path-level summary", del.Name)
+	}
+	if del.BaseURL != "https://eu.admin.example.com" {
+		t.Errorf("deleteAccount baseUrl = %q, want operation-level server", del.BaseURL)
+	}
+	get := ops["getAccount"]
+	if get.Name != "Get an account" {
+		t.Errorf("getAccount name = %q, want its own summary", get.Name)
+	}
+	if get.BaseURL != "https://accounts.example.com" {
+		t.Errorf("getAccount baseUrl = %q, want path-level server", get.BaseURL)
+	}
+	if len(get.Parameters) != 2 {
+		t.Fatalf("getAccount parameters = %+v, want accountId and overridden verbose", get.Parameters)
+	}
+	for _, param := range get.Parameters {
+		if param.Name == "verbose" && param.Description != "Include audit detail" {
+			t.Errorf("verbose description = %q, want operation-level override", param.Description)
+		}
+	}
+
+	resp := get.Responses[0]
+	if len(resp.Headers) != 1 || resp.Headers[0].Name != "X-RateLimit-Remaining" || resp.Headers[0].Type != "integer" {
+		t.Errorf("headers = %+v, want X-RateLimit-Remaining integer", resp.Headers)
+	}
+	if len(resp.Links) != 1 || resp.Links[0].OperationID != "getUser" || resp.Links[0].Parameters["userId"] != "$response.body#/ownerId" {
+		t.Errorf("links = %+v, want GetOwner -> getUser", resp.Links)
+	}
+}
+
+func TestParse_Security(t *testing.T) {
+	p := New()
+	data := readTestdata(t, "security.yaml")
+
+	result, err := p.Parse(data, instructions.SpecSource{Path: "testdata/security.yaml"})
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	schemes := map[string]ir.AuthScheme{}
+	for _, a := range result.Auth {
+		schemes[a.ID] = a
+	}
+	oauth := schemes["oauth"]
+	if len(oauth.Flows) != 2 || oauth.Flows[0].Type != "authorizationCode" || oauth.Flows[1].Type != "clientCredentials" {
+		t.Fatalf("oauth flows = %+v, want authorizationCode and clientCredentials", oauth.Flows)
+	}
+	if oauth.Flows[0].RefreshURL != "https://auth.example.com/refresh" || oauth.Flows[1].Scopes["write"] != "Modify items" {
+		t.Errorf("oauth flows = %+v, want URLs and scopes carried over", oauth.Flows)
+	}
+	if schemes["oidc"].OpenIDConnectURL == "" {
+		t.Error("oidc scheme missing openIdConnectUrl")
+	}
+	if schemes["jwt"].BearerFormat != "JWT" {
+		t.Errorf("jwt bearerFormat = %q, want JWT", schemes["jwt"].BearerFormat)
+	}
+
+	ops := map[string]ir.Operation{}
+	for _, op := range result.Operations {
+		ops[op.ID] = op
+	}
+	if list := ops["listItems"]; len(list.Auth) != 1 || list.Auth[0] != "oauth" || len(list.Scopes["oauth"]) != 1 {
+		t.Errorf("listItems auth = %v scopes = %v, want top-level default oauth [read]", list.Auth, list.Scopes)
+	}
+	create := ops["createItem"]
+	if len(create.Auth) != 2 || len(create.Scopes["oauth"]) != 2 || create.Scopes["oidc"][0] != "items" {
+		t.Errorf("createItem auth = %v scopes = %v, want operation override", create.Auth, create.Scopes)
+	}
+	if health := ops["health"]; len(health.Auth) != 0 {
+		t.Errorf("health auth = %v, want none (explicit empty security)", health.Auth)
+	}
+}
+
+func TestParse_Swagger2PathExtensions(t *testing.T) {
+	p := New()
+	spec := `swagger: "2.0"
+info:
+  title: Test
+  version: "1.0"
+paths:
+  /items:
+    get:
+      operationId: listItems
+      responses:
+        200:
+          description: OK
+  /admin/items:
+    x-sc-exclude: true
+    get:
+      operationId: purgeItems
+      responses:
+        200:
+          description: OK`
+
+	result, err := p.Parse([]byte(spec), instructions.SpecSource{Path: "test.yaml"})
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if len(result.Operations) != 1 || result.Operations[0].ID != "listItems" {
+		t.Errorf("operations = %+v, want only listItems (path-level x-sc-exclude)", result.Operations)
+	}
+}
+
+func TestParse_Extensions(t *testing.T) {
+	p := New()
+	data := readTestdata(t, "extensions.yaml")
+
+	result, err := p.Parse(data, instructions.SpecSource{Path: "testdata/extensions.yaml"})
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	if len(result.Operations) != 1 || result.Operations[0].ID != "listItems" {
+		t.Fatalf("operations = %+v, want only listItems (x-sc-exclude)", result.Operations)
+	}
+	for _, g := range result.Groups {
+		for _, id := range g.Operations {
+			if id != "listItems" {
+				t.Errorf("group %s lists excluded operation %s", g.Name, id)
+			}
+		}
+	}
+
+	op := result.Operations[0]
+	if op.Hint != "Prefer filtering by status over fetching everything." {
+		t.Errorf("hint = %q", op.Hint)
+	}
+	if op.Extensions["x-internal"] != "false" {
+		t.Errorf("operation extensions = %v, want x-internal", op.Extensions)
+	}
+	if op.Parameters[0].Extensions["x-agent-hint"] == "" {
+		t.Errorf("parameter extensions = %v, want x-agent-hint", op.Parameters[0].Extensions)
+	}
+
+	if got := result.Metadata["x-sc-hint"]; got != "Always page through results with the cursor parameter." {
+		t.Errorf("metadata x-sc-hint = %q", got)
+	}
+	if got := result.Metadata["x-rate-limit"]; got != `{"per":"minute","requests":100}` {
+		t.Errorf("metadata x-rate-limit = %q", got)
+	}
+
+	item := result.Types[0]
+	if item.Extensions["x-entity"] != "item" {
+		t.Errorf("type extensions = %v, want x-entity", item.Extensions)
+	}
+	if _, ok := item.Extensions["$resolvedRef"]; ok {
+		t.Error("type extensions include the internal $resolvedRef key")
+	}
+	if item.Fields[0].Extensions["x-immutable"] != "true" {
+		t.Errorf("field extensions = %v, want x-immutable", item.Fields[0].Extensions)
+	}
+}
@@ -0,0 +1,403 @@
+package openapi
+
+import (
+	"strings"
+)
+
+// Swagger 2.0 support: documents are upgraded to the OpenAPI 3.0 layout before
+// $ref resolution so the rest of Parse only deals with one shape.
+
+var swaggerMethods = []string{"get", "put", "post", "delete", "options", "head", "patch"}
+
+// isSwagger2 reports whether the raw document declares swagger: "2.0".
+func isSwagger2(doc map[string]interface{}) bool {
+	v, _ := doc["swagger"].(string)
+	return strings.HasPrefix(v, "2.")
+}
+
+// convertSwagger2 returns an OpenAPI 3.0 equivalent of a Swagger 2.0 document.
+// Local references to definitions, parameters and responses are rewritten to
+// their components locations.
+func convertSwagger2(doc map[string]interface{}) map[string]interface{} {
+	out := map[string]interface{}{
+		"openapi": "3.0.0",
+	}
+	for _, key := range []string{"info", "tags", "security", "externalDocs"} {
+		if v, ok := doc[key]; ok {
+			out[key] = v
+		}
+	}
+	copyExtensions(out, doc)
+
+	if servers := swaggerServers(doc); len(servers) > 0 {
+		out["servers"] = servers
+	}
+
+	consumes := stringList(doc["consumes"])
+	produces := stringList(doc["produces"])
+	globalParams := asMap(doc["parameters"])
+	globalResps := asMap(doc["responses"])
+
+	paths := make(map[string]interface{})
+	for path, item := range asMap(doc["paths"]) {
+		itemMap := asMap(item)
+		pathParams := asList(itemMap["parameters"])
+		ops := make(map[string]interface{})
+		for _, method := range swaggerMethods {
+			op := asMap(itemMap[method])
+			if op == nil {
+				continue
+			}
+			ops[method] = convertSwaggerOp(op, pathParams, consumes, produces, globalParams, globalResps)
+		}
+		copyExtensions(ops, itemMap)
+		paths[path] = ops
+	}
+	out["paths"] = paths
+
+	components := make(map[string]interface{})
+	if defs, ok := doc["definitions"]; ok {
+		components["schemas"] = defs
+	}
+	if secDefs := asMap(doc["securityDefinitions"]); len(secDefs) > 0 {
+		schemes := make(map[string]interface{}, len(secDefs))
+		for name, def := range secDefs {
+			schemes[name] = convertSwaggerSecurity(asMap(def))
+		}
+		components["securitySchemes"] = schemes
+	}
+	if len(globalParams) > 0 {
+		params := make(map[string]interface{})
+		for name, param := range globalParams {
+			pm := asMap(param)
+			if in, _ := pm["in"].(string); in != "body" && in != "formData" {
+				params[name] = convertSwaggerParam(pm)
+			}
+		}
+		components["parameters"] = params
+	}
+	if len(components) > 0 {
+		out["components"] = components
+	}
+
+	rewriteSwaggerRefs(out)
+	return out
+}
+
+// swaggerServers builds server URLs from host, basePath and schemes.
+func swaggerServers(doc map[string]interface{}) []interface{} {
+	host, _ := doc["host"].(string)
+	basePath, _ := doc["basePath"].(string)
+	if host == "" && basePath == "" {
+		return nil
+	}
+	schemes := stringList(doc["schemes"])
+	if host == "" {
+		return []interface{}{map[string]interface{}{"url": basePath}}
+	}
+	if len(schemes) == 0 {
+		schemes = []string{"https"}
+	}
+	var servers []interface{}
+	for _, scheme := range schemes {
+		servers = append(servers, map[string]interface{}{"url": scheme + "://" + host + basePath})
+	}
+	return servers
+}
+
+func convertSwaggerOp(op map[string]interface{}, pathParams []interface{}, consumes, produces []string, globalParams, globalResps map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{})
+	for _, key := range []string{"operationId", "summary", "description", "tags", "deprecated", "security", "externalDocs"} {
+		if v, ok := op[key]; ok {
+			out[key] = v
+		}
+	}
+	copyExtensions(out, op)
+	if c := stringList(op["consumes"]); len(c) > 0 {
+		consumes = c
+	}
+	if p := stringList(op["produces"]); len(p) > 0 {
+		produces = p
+	}
+	if len(consumes) == 0 {
+		consumes = []string{"application/json"}
+	}
+	if len(produces) == 0 {
+		produces = []string{"application/json"}
+	}
+
+	// Operation parameters override path-level ones with the same name and location
+	var params []map[string]interface{}
+	seen := make(map[string]bool)
+	for _, p := range asList(op["parameters"]) {
+		pm := inlineSwaggerRef(asMap(p), "#/parameters/", globalParams)
+		seen[paramKey(pm)] = true
+		params = append(params, pm)
+	}
+	for _, p := range pathParams {
+		pm := inlineSwaggerRef(asMap(p), "#/parameters/", globalParams)
+		if !seen[paramKey(pm)] {
+			params = append(params, pm)
+		}
+	}
+
+	var converted []interface{}
+	formProps := make(map[string]interface{})
+	var formRequired []interface{}
+	hasFile := false
+	for _, pm := range params {
+		switch pm["in"] {
+		case "body":
+			body := map[string]interface{}{
+				"content": mediaContent(consumes, pm["schema"], nil),
+			}
+			if desc, ok := pm["description"]; ok {
+				body["description"] = desc
+			}
+			if req, ok := pm["required"]; ok {
+				body["required"] = req
+			}
+			out["requestBody"] = body
+		case "formData":
+			name, _ := pm["name"].(string)
+			schema := swaggerSchema(pm)
+			if pm["type"] == "file" {
+				hasFile = true
+			}
+			if desc, ok := pm["description"]; ok {
+				schema["description"] = desc
+			}
+			formProps[name] = schema
+			if req, _ := pm["required"].(bool); req {
+				formRequired = append(formRequired, name)
+			}
+		default:
+			converted = append(converted, convertSwaggerParam(pm))
+		}
+	}
+	if len(converted) > 0 {
+		out["parameters"] = converted
+	}
+	if len(formProps) > 0 {
+		schema := map[string]interface{}{"type": "object", "properties": formProps}
+		if len(formRequired) > 0 {
+			schema["required"] = formRequired
+		}
+		formTypes := []string{"application/x-www-form-urlencoded"}
+		if hasFile {
+			formTypes = []string{"multipart/form-data"}
+		}
+		var formConsumes []string
+		for _, ct := range consumes {
+			if ct == "multipart/form-data" || ct == "application/x-www-form-urlencoded" {
+				formConsumes = append(formConsumes, ct)
+			}
+		}
+		if len(formConsumes) > 0 {
+			formTypes = formConsumes
+		}
+		out["requestBody"] = map[string]interface{}{
+			"required": len(formRequired) > 0,
+			"content":  mediaContent(formTypes, schema, nil),
+		}
+	}
+
+	responses := make(map[string]interface{})
+	for code, resp := range asMap(op["responses"]) {
+		rm := inlineSwaggerRef(asMap(resp), "#/responses/", globalResps)
+		cr := map[string]interface{}{}
+		if desc, ok := rm["description"]; ok {
+			cr["description"] = desc
+		}
+		if schema, ok := rm["schema"]; ok {
+			cr["content"] = mediaContent(produces, schema, asMap(rm["examples"]))
+		}
+		if headers := asMap(rm["headers"]); len(headers) > 0 {
+			hs := make(map[string]interface{}, len(headers))
+			for name, h := range headers {
+				hm := asMap(h)
+				header := map[string]interface{}{"schema": swaggerSchema(hm)}
+				if desc, ok := hm["description"]; ok {
+					header["description"] = desc
+				}
+				hs[name] = header
+			}
+			cr["headers"] = hs
+		}
+		responses[code] = cr
+	}
+	out["responses"] = responses
+	return out
+}
+
+// convertSwaggerParam converts a non-body parameter, moving its type
+// information into a schema.
+func convertSwaggerParam(pm map[string]interface{}) map[string]interface{} {
+	if _, ok := pm["$ref"]; ok {
+		return pm
+	}
+	out := make(map[string]interface{})
+	for _, key := range []string{"name", "in", "description", "required", "deprecated"} {
+		if v, ok := pm[key]; ok {
+			out[key] = v
+		}
+	}
+	copyExtensions(out, pm)
+	out["schema"] = swaggerSchema(pm)
+	return out
+}
+
+// swaggerSchema extracts the inline type fields of a parameter, header or
+// items object into a schema.
+func swaggerSchema(m map[string]interface{}) map[string]interface{} {
+	schema := make(map[string]interface{})
+	for _, key := range []string{"type", "format", "enum", "default", "pattern", "minimum", "maximum", "minLength", "maxLength"} {
+		if v, ok := m[key]; ok {
+			schema[key] = v
+		}
+	}
+	if schema["type"] == "file" {
+		schema["type"] = "string"
+		schema["format"] = "binary"
+	}
+	if items := asMap(m["items"]); items != nil {
+		schema["items"] = swaggerSchema(items)
+	}
+	return schema
+}
+
+func convertSwaggerSecurity(def map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{})
+	if desc, ok := def["description"]; ok {
+		out["description"] = desc
+	}
+	switch def["type"] {
+	case "basic":
+		out["type"] = "http"
+		out["scheme"] = "basic"
+	case "apiKey":
+		out["type"] = "apiKey"
+		out["name"] = def["name"]
+		out["in"] = def["in"]
+	case "oauth2":
+		out["type"] = "oauth2"
+		flowName := map[string]string{
+			"implicit":    "implicit",
+			"password":    "password",
+			"application": "clientCredentials",
+			"accessCode":  "authorizationCode",
+		}[stringValue(def["flow"])]
+		flow := map[string]interface{}{"scopes": def["scopes"]}
+		if flow["scopes"] == nil {
+			flow["scopes"] = map[string]interface{}{}
+		}
+		for _, key := range []string{"authorizationUrl", "tokenUrl"} {
+			if v, ok := def[key]; ok {
+				flow[key] = v
+			}
+		}
+		if flowName != "" {
+			out["flows"] = map[string]interface{}{flowName: flow}
+		}
+	default:
+		out["type"] = def["type"]
+	}
+	return out
+}
+
+// mediaContent builds an OpenAPI 3 content map with the schema under each
+// media type, attaching any per-media-type examples.
+func mediaContent(types []string, schema interface{}, examples map[string]interface{}) map[string]interface{} {
+	content := make(map[string]interface{}, len(types))
+	for _, ct := range types {
+		mt := map[string]interface{}{}
+		if schema != nil {
+			mt["schema"] = schema
+		}
+		if ex, ok := examples[ct]; ok {
+			mt["example"] = ex
+		}
+		content[ct] = mt
+	}
+	return content
+}
+
+// inlineSwaggerRef replaces a local reference to a global parameter or
+// response with its definition, since body and formData parameters cannot be
+// expressed as OpenAPI 3 parameter references.
+func inlineSwaggerRef(m map[string]interface{}, prefix string, defs map[string]interface{}) map[string]interface{} {
+	ref, _ := m["$ref"].(string)
+	if !strings.HasPrefix(ref, prefix) {
+		return m
+	}
+	if def := asMap(defs[strings.TrimPrefix(ref, prefix)]); def != nil {
+		return def
+	}
+	return m
+}
+
+// rewriteSwaggerRefs points local Swagger refs at their OpenAPI 3 locations.
+func rewriteSwaggerRefs(node interface{}) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok {
+			for _, prefix := range [][2]string{
+				{"#/definitions/", "#/components/schemas/"},
+				{"#/parameters/", "#/components/parameters/"},
+				{"#/responses/", "#/components/responses/"},
+			} {
+				if strings.HasPrefix(ref, prefix[0]) {
+					v["$ref"] = prefix[1] + strings.TrimPrefix(ref, prefix[0])
+				}
+			}
+		}
+		for _, val := range v {
+			rewriteSwaggerRefs(val)
+		}
+	case []interface{}:
+		for _, item := range v {
+			rewriteSwaggerRefs(item)
+		}
+	}
+}
+
+// copyExtensions copies the x-* keys of src into dst.
+func copyExtensions(dst, src map[string]interface{}) {
+	for k, v := range src {
+		if strings.HasPrefix(k, "x-") {
+			dst[k] = v
+		}
+	}
+}
+
+func paramKey(pm map[string]interface{}) string {
+	if ref, ok := pm["$ref"].(string); ok {
+		return ref
+	}
+	return stringValue(pm["in"]) + ":" + stringValue(pm["name"])
+}
+
+func asMap(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func asList(v interface{}) []interface{} {
+	l, _ := v.([]interface{})
+	return l
+}
+
+func stringValue(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+func stringList(v interface{}) []string {
+	var out []string
+	for _, item := range asList(v) {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,669 @@
+package postman
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/roberthamel/skill-compiler/internal/ir"
+)
+
+// collection is a Postman v2.0 or v2.1 collection. Items with an "item"
+// list are folders; the rest are requests.
+type collection struct {
+	Info struct {
+		Name        string      `json:"name"`
+		Description description `json:"description"`
+		Schema      string      `json:"schema"`
+	} `json:"info"`
+	Item     []item     `json:"item"`
+	Auth     *auth      `json:"auth"`
+	Variable []keyValue `json:"variable"`
+}
+
+type item struct {
+	Name        string          `json:"name"`
+	Description description     `json:"description"`
+	Item        []item          `json:"item"`
+	Request     *request        `json:"request"`
+	Response    []savedResponse `json:"response"`
+	Auth        *auth           `json:"auth"` // folders only; requests carry theirs in Request
+}
+
+type request struct {
+	Method      string      `json:"method"`
+	Header      headerList  `json:"header"`
+	URL         requestURL  `json:"url"`
+	Body        *body       `json:"body"`
+	Description description `json:"description"`
+	Auth        *auth       `json:"auth"`
+}
+
+// UnmarshalJSON accepts the shorthand of a bare URL string for a GET.
+func (r *request) UnmarshalJSON(data []byte) error {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		*r = request{Method: "GET", URL: requestURL{Raw: s}}
+		return nil
+	}
+	type plain request
+	return json.Unmarshal(data, (*plain)(r))
+}
+
+type requestURL struct {
+	Raw      string     `json:"raw"`
+	Protocol string     `json:"protocol"`
+	Host     stringList `json:"host"`
+	Path     stringList `json:"path"`
+	Query    []keyValue `json:"query"`
+	Variable []keyValue `json:"variable"`
+}
+
+// UnmarshalJSON accepts a URL given as a plain string.
+func (u *requestURL) UnmarshalJSON(data []byte) error {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		*u = requestURL{Raw: s}
+		return nil
+	}
+	type plain requestURL
+	return json.Unmarshal(data, (*plain)(u))
+}
+
+type body struct {
+	Mode       string     `json:"mode"` // raw, urlencoded, formdata, graphql, file
+	Raw        string     `json:"raw"`
+	URLEncoded []keyValue `json:"urlencoded"`
+	FormData   []keyValue `json:"formdata"`
+	GraphQL    *struct {
+		Query     string `json:"query"`
+		Variables string `json:"variables"`
+	} `json:"graphql"`
+	Options struct {
+		Raw struct {
+			Language string `json:"language"` // json, xml, text, html, javascript
+		} `json:"raw"`
+	} `json:"options"`
+	Disabled bool `json:"disabled"`
+}
+
+type savedResponse struct {
+	Name   string     `json:"name"`
+	Status string     `json:"status"`
+	Code   int        `json:"code"`
+	Header headerList `json:"header"`
+	Body   string     `json:"body"`
+}
+
+type keyValue struct {
+	Key         string      `json:"key"`
+	Value       string      `json:"value"`
+	Description description `json:"description"`
+	Disabled    bool        `json:"disabled"`
+	Type        string      `json:"type"` // formdata: text or file
+}
+
+// UnmarshalJSON tolerates non-string values, which some exporters write
+// for numbers and booleans.
+func (kv *keyValue) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Key         string          `json:"key"`
+		Value       json.RawMessage `json:"value"`
+		Description description     `json:"description"`
+		Disabled    bool            `json:"disabled"`
+		Type        string          `json:"type"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*kv = keyValue{Key: raw.Key, Value: rawString(raw.Value), Description: raw.Description, Disabled: raw.Disabled, Type: raw.Type}
+	return nil
+}
+
+// headerList is a list of headers; the string form some exporters use for
+// saved responses is ignored.
+type headerList []keyValue
+
+func (h *headerList) UnmarshalJSON(data []byte) error {
+	var list []keyValue
+	if json.Unmarshal(data, &list) == nil {
+		*h = list
+	}
+	return nil
+}
+
+// description is a plain string or a {content, type} object.
+type description string
+
+func (d *description) UnmarshalJSON(data []byte) error {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		*d = description(s)
+		return nil
+	}
+	var obj struct {
+		Content string `json:"content"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	*d = description(obj.Content)
+	return nil
+}
+
+// stringList is a URL host or path, given as a string or as a list whose
+// elements are strings or {value} objects.
+type stringList []string
+
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		*l = stringList{s}
+		return nil
+	}
+	var elems []json.RawMessage
+	if err := json.Unmarshal(data, &elems); err != nil {
+		return err
+	}
+	for _, elem := range elems {
+		var obj struct {
+			Value string `json:"value"`
+		}
+		if json.Unmarshal(elem, &obj) == nil && obj.Value != "" {
+			*l = append(*l, obj.Value)
+			continue
+		}
+		*l = append(*l, rawString(elem))
+	}
+	return nil
+}
+
+// auth is a collection, folder or request auth block. Its parameters are
+// a list of {key, value} in v2.1 and an object in v2.0.
+type auth struct {
+	Type   string
+	Params map[string]string
+}
+
+func (a *auth) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	_ = json.Unmarshal(raw["type"], &a.Type)
+	a.Params = make(map[string]string)
+	var list []keyValue
+	if json.Unmarshal(raw[a.Type], &list) == nil {
+		for _, kv := range list {
+			a.Params[kv.Key] = kv.Value
+		}
+		return nil
+	}
+	var obj map[string]json.RawMessage
+	if json.Unmarshal(raw[a.Type], &obj) == nil {
+		for k, v := range obj {
+			a.Params[k] = rawString(v)
+		}
+	}
+	return nil
+}
+
+// rawString renders a JSON value as text: strings unquoted, anything else
+// as written.
+func rawString(data json.RawMessage) string {
+	if len(data) == 0 || string(data) == "null" {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		return s
+	}
+	return string(data)
+}
+
+// variableRe matches Postman {{variable}} references.
+var variableRe = regexp.MustCompile(`\{\{\s*([^{}]+?)\s*\}\}`)
+
+// collectionParser accumulates the IR while walking the item tree.
+type collectionParser struct {
+	vars    map[string]string
+	result  *ir.IntermediateRepr
+	usedIDs map[string]bool
+	authIDs map[string]string // scheme signature -> AuthScheme ID
+	hosts   map[string]int    // base URL -> number of requests
+}
+
+func parseCollection(raw []byte) (*ir.IntermediateRepr, error) {
+	var c collection
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, fmt.Errorf("parsing Postman collection: %w", err)
+	}
+
+	cp := &collectionParser{
+		vars: make(map[string]string),
+		result: &ir.IntermediateRepr{
+			Metadata: map[string]string{
+				"title":       c.Info.Name,
+				"description": string(c.Info.Description),
+				"format":      "postman",
+			},
+		},
+		usedIDs: make(map[string]bool),
+		authIDs: make(map[string]string),
+		hosts:   make(map[string]int),
+	}
+	var names []string
+	for _, v := range c.Variable {
+		cp.vars[v.Key] = v.Value
+		names = append(names, v.Key)
+	}
+	if len(names) > 0 {
+		// values may be credentials, so only the names are kept
+		cp.result.Metadata["variables"] = strings.Join(names, ", ")
+	}
+
+	cp.walk(c.Item, nil, "", c.Auth)
+
+	if base := mostCommon(cp.hosts); base != "" {
+		cp.result.Metadata["baseUrl"] = base
+	}
+	return cp.result, nil
+}
+
+// walk visits items depth-first. folders is the folder path to items,
+// desc the innermost folder's description and inherited the auth the items
+// inherit.
+func (cp *collectionParser) walk(items []item, folders []string, desc string, inherited *auth) {
+	groupIndex := -1
+	if len(folders) > 0 {
+		cp.result.Groups = append(cp.result.Groups, ir.Group{Name: strings.Join(folders, "/"), Description: desc})
+		groupIndex = len(cp.result.Groups) - 1
+	}
+
+	var ids []string
+	for _, it := range items {
+		if it.Request == nil {
+			folderAuth := inherited
+			if it.Auth != nil && it.Auth.Type != "inherit" {
+				folderAuth = it.Auth
+			}
+			sub := append(append([]string(nil), folders...), it.Name)
+			cp.walk(it.Item, sub, string(it.Description), folderAuth)
+			continue
+		}
+		op := cp.operation(it, folders, inherited)
+		cp.result.Operations = append(cp.result.Operations, op)
+		ids = append(ids, op.ID)
+	}
+
+	switch {
+	case groupIndex < 0:
+	case len(ids) == 0:
+		// folders holding only folders are represented by their children
+		cp.result.Groups = append(cp.result.Groups[:groupIndex], cp.result.Groups[groupIndex+1:]...)
+	default:
+		cp.result.Groups[groupIndex].Operations = ids
+	}
+}
+
+func (cp *collectionParser) operation(it item, folders []string, inherited *auth) ir.Operation {
+	req := it.Request
+	method := strings.ToUpper(req.Method)
+	if method == "" {
+		method = "GET"
+	}
+	base, path, pathParams := splitURL(req.URL)
+	if base != "" {
+		cp.hosts[cp.resolve(base)]++
+	}
+
+	id := slug(it.Name)
+	if id == "" {
+		id = ir.OperationID(method, path)
+	}
+	op := ir.Operation{
+		ID:          ir.UniqueID(id, cp.usedIDs),
+		Name:        it.Name,
+		Description: string(req.Description),
+		Method:      method,
+		Path:        path,
+		Tags:        append([]string(nil), folders...),
+	}
+
+	described := make(map[string]string)
+	for _, v := range req.URL.Variable {
+		described[v.Key] = joinDescription(string(v.Description), exampleNote(v.Key, v.Value))
+	}
+	for _, name := range pathParams {
+		op.Parameters = append(op.Parameters, ir.Parameter{
+			Name:        name,
+			In:          "path",
+			Description: described[name],
+			Required:    true,
+			Type:        "string",
+		})
+	}
+	for _, q := range req.URL.Query {
+		if q.Key == "" {
+			continue
+		}
+		op.Parameters = append(op.Parameters, ir.Parameter{
+			Name:        q.Key,
+			In:          "query",
+			Description: joinDescription(string(q.Description), exampleNote(q.Key, q.Value)),
+			Required:    !q.Disabled,
+			Type:        "string",
+		})
+	}
+
+	opAuth := inherited
+	if req.Auth != nil && req.Auth.Type != "inherit" {
+		opAuth = req.Auth
+	}
+	if id := cp.authScheme(opAuth); id != "" {
+		op.Auth = []string{id}
+	}
+
+	contentType := ""
+	for _, h := range req.Header {
+		name := strings.ToLower(h.Key)
+		switch {
+		case h.Key == "" || h.Disabled:
+			continue
+		case name == "content-type":
+			contentType = mediaType(h.Value)
+			continue
+		case name == "authorization" && len(op.Auth) > 0:
+			continue
+		}
+		op.Parameters = append(op.Parameters, ir.Parameter{
+			Name:        h.Key,
+			In:          "header",
+			Description: joinDescription(string(h.Description), exampleNote(h.Key, h.Value)),
+			Required:    true,
+			Type:        "string",
+		})
+	}
+
+	if req.Body != nil && !req.Body.Disabled {
+		op.RequestBody = requestBody(req.Body, contentType)
+	}
+	op.Responses = responses(it.Response)
+	return op
+}
+
+// resolve substitutes known collection variables in s.
+func (cp *collectionParser) resolve(s string) string {
+	return variableRe.ReplaceAllStringFunc(s, func(ref string) string {
+		name := variableRe.FindStringSubmatch(ref)[1]
+		if v, ok := cp.vars[name]; ok && v != "" {
+			return v
+		}
+		return ref
+	})
+}
+
+// authScheme registers a as an AuthScheme, reusing an identical one, and
+// returns its ID; "" means no auth.
+func (cp *collectionParser) authScheme(a *auth) string {
+	if a == nil || a.Type == "" || a.Type == "noauth" || a.Type == "inherit" {
+		return ""
+	}
+	scheme := ir.AuthScheme{ID: a.Type, Type: a.Type}
+	switch a.Type {
+	case "bearer":
+		scheme.Type, scheme.Scheme = "http", "bearer"
+		scheme.Description = variableNote(a.Params["token"])
+	case "basic", "digest":
+		scheme.Type, scheme.Scheme = "http", a.Type
+		scheme.Description = variableNote(a.Params["username"])
+	case "apikey":
+		scheme.ID, scheme.Type = "apiKey", "apiKey"
+		scheme.Name = a.Params["key"]
+		scheme.In = a.Params["in"]
+		if scheme.In == "" {
+			scheme.In = "header"
+		}
+		scheme.Description = variableNote(a.Params["value"])
+	case "oauth2":
+		flow := ir.OAuthFlow{
+			Type:             grantTypes[a.Params["grant_type"]],
+			AuthorizationURL: cp.resolve(a.Params["authUrl"]),
+			TokenURL:         cp.resolve(a.Params["accessTokenUrl"]),
+		}
+		if flow.Type == "" {
+			flow.Type = "authorizationCode"
+		}
+		for _, s := range strings.Fields(a.Params["scope"]) {
+			if flow.Scopes == nil {
+				flow.Scopes = make(map[string]string)
+			}
+			flow.Scopes[s] = ""
+		}
+		scheme.Flows = []ir.OAuthFlow{flow}
+	default:
+		scheme.Description = "Postman " + a.Type + " auth"
+	}
+
+	sig, _ := json.Marshal(scheme)
+	if id, ok := cp.authIDs[string(sig)]; ok {
+		return id
+	}
+	used := make(map[string]bool)
+	for _, existing := range cp.result.Auth {
+		used[existing.ID] = true
+	}
+	scheme.ID = ir.UniqueID(scheme.ID, used)
+	cp.authIDs[string(sig)] = scheme.ID
+	cp.result.Auth = append(cp.result.Auth, scheme)
+	return scheme.ID
+}
+
+// grantTypes maps Postman OAuth2 grant types to ir.OAuthFlow types.
+var grantTypes = map[string]string{
+	"authorization_code":           "authorizationCode",
+	"authorization_code_with_pkce": "authorizationCode",
+	"implicit":                     "implicit",
+	"password_credentials":         "password",
+	"client_credentials":           "clientCredentials",
+}
+
+// variableNote says which variable holds a credential; literal
+// credentials are not copied.
+func variableNote(value string) string {
+	if m := variableRe.FindStringSubmatch(value); m != nil {
+		return "Credential from the {{" + m[1] + "}} variable"
+	}
+	return ""
+}
+
+// splitURL returns a request URL's base (protocol and host, possibly a
+// {{variable}}), its path with :params and {{variables}} written as
+// {name}, and the names of those path parameters.
+func splitURL(u requestURL) (base, path string, params []string) {
+	host := strings.Join(u.Host, ".")
+	segments := []string(u.Path)
+	if host == "" && len(segments) == 0 {
+		host, segments = splitRaw(u.Raw)
+	}
+	base = host
+	if u.Protocol != "" && host != "" {
+		base = u.Protocol + "://" + host
+	}
+
+	var parts []string
+	for _, seg := range segments {
+		switch {
+		case seg == "":
+			continue
+		case strings.HasPrefix(seg, ":"):
+			params = append(params, seg[1:])
+			seg = "{" + seg[1:] + "}"
+		case variableRe.MatchString(seg):
+			seg = variableRe.ReplaceAllStringFunc(seg, func(ref string) string {
+				name := variableRe.FindStringSubmatch(ref)[1]
+				params = append(params, name)
+				return "{" + name + "}"
+			})
+		}
+		parts = append(parts, seg)
+	}
+	return base, "/" + strings.Join(parts, "/"), params
+}
+
+// splitRaw splits a raw URL such as "{{baseUrl}}/orders/:id?x=1" or
+// "https://api.example.com/orders" into its base and path segments.
+func splitRaw(raw string) (string, []string) {
+	raw, _, _ = strings.Cut(raw, "#")
+	raw, _, _ = strings.Cut(raw, "?")
+	if strings.Contains(raw, "://") && !strings.HasPrefix(raw, "{{") {
+		if parsed, err := url.Parse(raw); err == nil {
+			return parsed.Scheme + "://" + parsed.Host, strings.Split(strings.Trim(parsed.Path, "/"), "/")
+		}
+	}
+	if strings.HasPrefix(raw, "/") {
+		return "", strings.Split(strings.Trim(raw, "/"), "/")
+	}
+	base, rest, _ := strings.Cut(raw, "/")
+	return base, strings.Split(strings.Trim(rest, "/"), "/")
+}
+
+// requestBody describes a request body and its example.
+func requestBody(b *body, contentType string) *ir.TypeRef {
+	var value string
+	switch b.Mode {
+	case "raw":
+		if contentType == "" {
+			contentType = rawLanguages[b.Options.Raw.Language]
+		}
+		if contentType == "" {
+			contentType = sniffContentType(b.Raw)
+		}
+		value = b.Raw
+	case "urlencoded":
+		contentType = "application/x-www-form-urlencoded"
+		var pairs []string
+		for _, kv := range b.URLEncoded {
+			if !kv.Disabled {
+				pairs = append(pairs, url.QueryEscape(kv.Key)+"="+url.QueryEscape(kv.Value))
+			}
+		}
+		value = strings.Join(pairs, "&")
+	case "formdata":
+		contentType = "multipart/form-data"
+		var fields []string
+		for _, kv := range b.FormData {
+			if kv.Disabled {
+				continue
+			}
+			if kv.Type == "file" {
+				fields = append(fields, kv.Key+"=@file")
+				continue
+			}
+			fields = append(fields, kv.Key+"="+kv.Value)
+		}
+		value = strings.Join(fields, "\n")
+	case "graphql":
+		contentType = "application/json"
+		if b.GraphQL != nil {
+			payload := map[string]interface{}{"query": b.GraphQL.Query}
+			var vars interface{}
+			if json.Unmarshal([]byte(b.GraphQL.Variables), &vars) == nil {
+				payload["variables"] = vars
+			}
+			data, _ := json.Marshal(payload)
+			value = string(data)
+		}
+	case "file":
+		contentType = "application/octet-stream"
+	default:
+		return nil
+	}
+
+	mt := ir.MediaType{ContentType: contentType}
+	if ex := example("", contentType, value); ex != nil {
+		mt.Examples = []ir.Example{*ex}
+	}
+	return &ir.TypeRef{ContentType: contentType, Content: []ir.MediaType{mt}}
+}
+
+// rawLanguages maps a raw body's language option to a media type.
+var rawLanguages = map[string]string{
+	"json":       "application/json",
+	"xml":        "application/xml",
+	"html":       "text/html",
+	"text":       "text/plain",
+	"javascript": "application/javascript",
+}
+
+// responses turns saved responses into one ir.Response per status code,
+// each saved response an example.
+func responses(saved []savedResponse) []ir.Response {
+	var out []ir.Response
+	index := make(map[string]int)
+	for _, s := range saved {
+		code := "default"
+		if s.Code != 0 {
+			code = strconv.Itoa(s.Code)
+		}
+		contentType := ""
+		for _, h := range s.Header {
+			if strings.EqualFold(h.Key, "content-type") {
+				contentType = mediaType(h.Value)
+			}
+		}
+		if contentType == "" && strings.TrimSpace(s.Body) != "" {
+			contentType = sniffContentType(s.Body)
+		}
+
+		i, ok := index[code]
+		if !ok {
+			desc := s.Status
+			if desc == "" {
+				desc = s.Name
+			}
+			out = append(out, ir.Response{StatusCode: code, Description: desc})
+			i = len(out) - 1
+			index[code] = i
+		}
+		ex := example(s.Name, contentType, s.Body)
+		if ex == nil {
+			continue
+		}
+		resp := &out[i]
+		if resp.Body == nil {
+			resp.Body = &ir.TypeRef{ContentType: contentType, Content: []ir.MediaType{{ContentType: contentType}}}
+		}
+		resp.Body.Content[0].Examples = append(resp.Body.Content[0].Examples, *ex)
+	}
+	return out
+}
+
+// slugRe matches runs of characters not allowed in a request slug.
+var slugRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slug turns a request name like "List orders" into "list_orders".
+func slug(name string) string {
+	return strings.Trim(slugRe.ReplaceAllString(strings.ToLower(name), "_"), "_")
+}
+
+// mostCommon returns the key with the highest count, breaking ties by
+// name.
+func mostCommon(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	best := ""
+	for _, k := range keys {
+		if best == "" || counts[k] > counts[best] {
+			best = k
+		}
+	}
+	return best
+}
@@ -0,0 +1,541 @@
+package postman
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/roberthamel/skill-compiler/internal/ir"
+)
+
+// harFile is an HTTP Archive (HAR 1.2) recording.
+type harFile struct {
+	Log struct {
+		Creator struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"creator"`
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harEntry struct {
+	Request  harRequest  `json:"request"`
+	Response harResponse `json:"response"`
+}
+
+type harRequest struct {
+	Method      string       `json:"method"`
+	URL         string       `json:"url"`
+	Headers     []harNV      `json:"headers"`
+	QueryString []harNV      `json:"queryString"`
+	PostData    *harPostData `json:"postData"`
+}
+
+type harNV struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string  `json:"mimeType"`
+	Text     string  `json:"text"`
+	Params   []harNV `json:"params"`
+}
+
+type harResponse struct {
+	Status     int     `json:"status"`
+	StatusText string  `json:"statusText"`
+	Headers    []harNV `json:"headers"`
+	Content    struct {
+		MimeType string `json:"mimeType"`
+		Text     string `json:"text"`
+		Encoding string `json:"encoding"`
+	} `json:"content"`
+}
+
+// harRoute is a recorded API request with its path split into segments.
+type harRoute struct {
+	origin   string
+	method   string
+	segments []string
+	entry    harEntry
+}
+
+// harCluster is the set of recorded requests inferred to be one operation.
+type harCluster struct {
+	origin  string
+	method  string
+	path    string
+	params  []string // path parameter names, in order
+	entries []harEntry
+}
+
+func parseHAR(raw []byte) (*ir.IntermediateRepr, error) {
+	var har harFile
+	if err := json.Unmarshal(raw, &har); err != nil {
+		return nil, fmt.Errorf("parsing HAR file: %w", err)
+	}
+
+	var routes []harRoute
+	origins := make(map[string]int)
+	skipped := 0
+	for _, e := range har.Log.Entries {
+		u, err := url.Parse(e.Request.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || isAsset(e, u) {
+			skipped++
+			continue
+		}
+		method := strings.ToUpper(e.Request.Method)
+		if method == "OPTIONS" {
+			// CORS preflights
+			skipped++
+			continue
+		}
+		origin := u.Scheme + "://" + u.Host
+		routes = append(routes, harRoute{origin: origin, method: method, segments: pathSegments(u.Path), entry: e})
+		origins[origin]++
+	}
+
+	clusters := make(map[string]*harCluster)
+	for i, vary := range varyingSegments(routes) {
+		route, params := templatePath(routes[i].segments, vary)
+		key := routes[i].origin + " " + routes[i].method + " " + route
+		c, ok := clusters[key]
+		if !ok {
+			c = &harCluster{origin: routes[i].origin, method: routes[i].method, path: route, params: params}
+			clusters[key] = c
+		}
+		c.entries = append(c.entries, routes[i].entry)
+	}
+	result := &ir.IntermediateRepr{
+		Metadata: map[string]string{"format": "har"},
+	}
+	if skipped > 0 {
+		result.Warnings = append(result.Warnings, ir.Warning{
+			Message: fmt.Sprintf("skipped %d HAR entries (static assets, preflights or non-HTTP URLs)", skipped),
+		})
+	}
+	if name := har.Log.Creator.Name; name != "" {
+		result.Metadata["recordedWith"] = strings.TrimSpace(name + " " + har.Log.Creator.Version)
+	}
+	primary := mostCommon(origins)
+	if primary != "" {
+		result.Metadata["baseUrl"] = primary
+	}
+
+	keys := make([]string, 0, len(clusters))
+	for k := range clusters {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := clusters[keys[i]], clusters[keys[j]]
+		if a.origin != b.origin {
+			return a.origin < b.origin
+		}
+		if a.path != b.path {
+			return a.path < b.path
+		}
+		return methodOrder(a.method) < methodOrder(b.method)
+	})
+
+	usedIDs := make(map[string]bool)
+	authIDs := make(map[string]bool)
+	groupIndex := make(map[string]int)
+	for _, k := range keys {
+		c := clusters[k]
+		op := harOperation(c, usedIDs)
+		for _, scheme := range harAuth(c.entries) {
+			if !authIDs[scheme.ID] {
+				authIDs[scheme.ID] = true
+				result.Auth = append(result.Auth, scheme)
+			}
+			op.Auth = append(op.Auth, scheme.ID)
+		}
+		result.Operations = append(result.Operations, op)
+
+		i, ok := groupIndex[c.origin]
+		if !ok {
+			result.Groups = append(result.Groups, ir.Group{Name: c.origin})
+			i = len(result.Groups) - 1
+			groupIndex[c.origin] = i
+		}
+		result.Groups[i].Operations = append(result.Groups[i].Operations, op.ID)
+	}
+	return result, nil
+}
+
+func harOperation(c *harCluster, usedIDs map[string]bool) ir.Operation {
+	n := len(c.entries)
+	op := ir.Operation{
+		ID:          ir.UniqueID(ir.OperationID(c.method, c.path), usedIDs),
+		Description: fmt.Sprintf("Inferred from %d recorded request%s.", n, plural(n)),
+		Method:      c.method,
+		Path:        c.path,
+	}
+
+	for _, name := range c.params {
+		op.Parameters = append(op.Parameters, ir.Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Type:     "string",
+		})
+	}
+
+	// Query parameters present in every recording are required
+	seen := make(map[string]int)
+	first := make(map[string]string)
+	var order []string
+	for _, e := range c.entries {
+		inEntry := make(map[string]bool)
+		for _, q := range e.Request.QueryString {
+			if inEntry[q.Name] || sensitiveRe.MatchString(q.Name) {
+				// credentials are described by harAuth
+				continue
+			}
+			inEntry[q.Name] = true
+			if seen[q.Name] == 0 {
+				order = append(order, q.Name)
+				first[q.Name] = q.Value
+			}
+			seen[q.Name]++
+		}
+	}
+	for _, name := range order {
+		op.Parameters = append(op.Parameters, ir.Parameter{
+			Name:        name,
+			In:          "query",
+			Description: exampleNote(name, first[name]),
+			Required:    seen[name] == n,
+			Type:        "string",
+		})
+	}
+
+	headers := make(map[string]harNV)
+	var headerOrder []string
+	for _, e := range c.entries {
+		for _, h := range e.Request.Headers {
+			lower := strings.ToLower(h.Name)
+			if browserHeader(lower) || sensitiveRe.MatchString(lower) {
+				continue
+			}
+			if _, ok := headers[lower]; !ok {
+				headers[lower] = h
+				headerOrder = append(headerOrder, lower)
+			}
+		}
+	}
+	for _, lower := range headerOrder {
+		h := headers[lower]
+		op.Parameters = append(op.Parameters, ir.Parameter{
+			Name:        h.Name,
+			In:          "header",
+			Description: exampleNote(h.Name, h.Value),
+			Type:        "string",
+		})
+	}
+
+	for _, e := range c.entries {
+		pd := e.Request.PostData
+		if pd == nil {
+			continue
+		}
+		contentType := mediaType(pd.MimeType)
+		text := pd.Text
+		if text == "" && len(pd.Params) > 0 {
+			var pairs []string
+			for _, param := range pd.Params {
+				pairs = append(pairs, url.QueryEscape(param.Name)+"="+url.QueryEscape(param.Value))
+			}
+			text = strings.Join(pairs, "&")
+		}
+		if contentType == "" {
+			contentType = sniffContentType(text)
+		}
+		if op.RequestBody == nil {
+			op.RequestBody = &ir.TypeRef{ContentType: contentType, Content: []ir.MediaType{{ContentType: contentType}}}
+		}
+		if len(op.RequestBody.Content[0].Examples) == 0 {
+			if ex := example("", contentType, text); ex != nil {
+				op.RequestBody.Content[0].Examples = []ir.Example{*ex}
+			}
+		}
+	}
+
+	// One response per status code, with the first usable body as example
+	index := make(map[int]int)
+	for _, e := range c.entries {
+		r := e.Response
+		if r.Status == 0 {
+			// aborted or blocked requests
+			continue
+		}
+		i, ok := index[r.Status]
+		if !ok {
+			op.Responses = append(op.Responses, ir.Response{StatusCode: strconv.Itoa(r.Status), Description: r.StatusText})
+			i = len(op.Responses) - 1
+			index[r.Status] = i
+		}
+		resp := &op.Responses[i]
+		if resp.Body != nil {
+			continue
+		}
+		text := r.Content.Text
+		if r.Content.Encoding == "base64" {
+			decoded, err := base64.StdEncoding.DecodeString(text)
+			if err != nil {
+				continue
+			}
+			text = string(decoded)
+		}
+		contentType := mediaType(r.Content.MimeType)
+		ex := example("", contentType, text)
+		if ex == nil {
+			continue
+		}
+		resp.Body = &ir.TypeRef{ContentType: contentType, Content: []ir.MediaType{{ContentType: contentType, Examples: []ir.Example{*ex}}}}
+	}
+	sort.SliceStable(op.Responses, func(i, j int) bool { return op.Responses[i].StatusCode < op.Responses[j].StatusCode })
+	return op
+}
+
+// harAuth infers auth schemes from the credentials requests carried.
+func harAuth(entries []harEntry) []ir.AuthScheme {
+	var schemes []ir.AuthScheme
+	seen := make(map[string]bool)
+	add := func(s ir.AuthScheme) {
+		if !seen[s.ID] {
+			seen[s.ID] = true
+			schemes = append(schemes, s)
+		}
+	}
+	for _, e := range entries {
+		for _, h := range e.Request.Headers {
+			lower := strings.ToLower(h.Name)
+			switch {
+			case lower == "authorization":
+				scheme, _, _ := strings.Cut(h.Value, " ")
+				scheme = strings.ToLower(scheme)
+				if scheme == "bearer" || scheme == "basic" || scheme == "digest" {
+					add(ir.AuthScheme{ID: scheme, Type: "http", Scheme: scheme})
+				}
+			case lower == "cookie" || lower == "proxy-authorization":
+			case sensitiveRe.MatchString(lower) && !browserHeader(lower):
+				add(ir.AuthScheme{ID: lower, Type: "apiKey", Name: h.Name, In: "header"})
+			}
+		}
+		for _, q := range e.Request.QueryString {
+			if sensitiveRe.MatchString(q.Name) {
+				add(ir.AuthScheme{ID: q.Name, Type: "apiKey", Name: q.Name, In: "query"})
+			}
+		}
+	}
+	return schemes
+}
+
+// browserHeaders are headers browsers and HTTP clients set on their own;
+// they say nothing about the API.
+var browserHeaders = map[string]bool{
+	"accept": true, "accept-encoding": true, "accept-language": true,
+	"authorization": true, "cache-control": true, "connection": true,
+	"content-length": true, "content-type": true, "cookie": true,
+	"dnt": true, "host": true, "if-modified-since": true,
+	"if-none-match": true, "origin": true, "pragma": true,
+	"priority": true, "referer": true, "te": true,
+	"upgrade-insecure-requests": true, "user-agent": true,
+}
+
+func browserHeader(lower string) bool {
+	return browserHeaders[lower] || strings.HasPrefix(lower, "sec-") || strings.HasPrefix(lower, ":")
+}
+
+// assetExtensions are file extensions of static resources.
+var assetExtensions = map[string]bool{
+	".css": true, ".js": true, ".mjs": true, ".map": true, ".html": true,
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true,
+	".ico": true, ".webp": true, ".woff": true, ".woff2": true, ".ttf": true,
+}
+
+// isAsset reports whether an entry fetched a page or static resource
+// rather than calling an API.
+func isAsset(e harEntry, u *url.URL) bool {
+	if assetExtensions[strings.ToLower(path.Ext(u.Path))] {
+		return true
+	}
+	mt := mediaType(e.Response.Content.MimeType)
+	switch {
+	case mt == "text/html", mt == "text/css", strings.Contains(mt, "javascript"):
+		return true
+	case strings.HasPrefix(mt, "image/"), strings.HasPrefix(mt, "font/"):
+		return true
+	}
+	return false
+}
+
+var (
+	uuidRe  = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hexRe   = regexp.MustCompile(`^[0-9a-fA-F]{16,}$`)
+	digitRe = regexp.MustCompile(`[0-9]`)
+	tokenRe = regexp.MustCompile(`^[A-Za-z0-9_-]{20,}$`)
+)
+
+// isIDSegment reports whether a path segment looks like an identifier
+// rather than a fixed part of the route: numbers, UUIDs, long hex strings
+// and long opaque tokens containing digits.
+func isIDSegment(seg string) bool {
+	if _, err := strconv.ParseUint(seg, 10, 64); err == nil {
+		return true
+	}
+	if uuidRe.MatchString(seg) || (hexRe.MatchString(seg) && digitRe.MatchString(seg)) {
+		return true
+	}
+	return tokenRe.MatchString(seg) && digitRe.MatchString(seg)
+}
+
+// pathSegments splits a URL path into its segments.
+func pathSegments(p string) []string {
+	return strings.Split(strings.Trim(p, "/"), "/")
+}
+
+// varyingSegments compares requests with the same origin, method and number
+// of path segments position by position, and returns each request's
+// parameter positions. Identifier segments are parameters wherever they
+// appear. Requests that share the route up to a position, and hold
+// different values there after a collection segment, take a parameter
+// there too, so /posts/hello-world and /posts/second-post become one
+// operation.
+func varyingSegments(routes []harRoute) []map[int]bool {
+	vary := make([]map[int]bool, len(routes))
+	shapes := make(map[string][]int)
+	for i, r := range routes {
+		vary[i] = make(map[int]bool)
+		for pos, seg := range r.segments {
+			if seg != "" && isIDSegment(seg) {
+				vary[i][pos] = true
+			}
+		}
+		key := r.origin + " " + r.method + " " + strconv.Itoa(len(r.segments))
+		shapes[key] = append(shapes[key], i)
+	}
+	for _, members := range shapes {
+		for pos := 1; pos < len(routes[members[0]].segments); pos++ {
+			prefixes := make(map[string][]int)
+			for _, m := range members {
+				key := routePrefix(routes[m].segments[:pos], vary[m])
+				prefixes[key] = append(prefixes[key], m)
+			}
+			for _, group := range prefixes {
+				first := group[0]
+				if vary[first][pos-1] || !isCollection(routes[first].segments[pos-1]) {
+					continue
+				}
+				values := make(map[string]bool)
+				for _, m := range group {
+					values[routes[m].segments[pos]] = true
+				}
+				if len(values) < 2 {
+					continue
+				}
+				for _, m := range group {
+					vary[m][pos] = true
+				}
+			}
+		}
+	}
+	return vary
+}
+
+// routePrefix joins segments with the parameters among them blanked out.
+func routePrefix(segments []string, params map[int]bool) string {
+	parts := make([]string, len(segments))
+	for i, seg := range segments {
+		parts[i] = seg
+		if params[i] {
+			parts[i] = "{}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// isCollection reports whether a segment names a collection, e.g. posts,
+// so that the segment after it may be one of its members.
+func isCollection(seg string) bool {
+	seg = strings.ToLower(seg)
+	return strings.HasSuffix(seg, "s") && !strings.HasSuffix(seg, "ss")
+}
+
+// templatePath replaces identifier segments, and the positions in params,
+// with parameters named after the preceding segment, so /orders/42/items/7
+// becomes /orders/{orderId}/items/{itemId}.
+func templatePath(segments []string, params map[int]bool) (string, []string) {
+	segments = append([]string(nil), segments...)
+	var names []string
+	used := make(map[string]bool)
+	for i, seg := range segments {
+		if seg == "" || !(params[i] || isIDSegment(seg)) {
+			continue
+		}
+		name := "id"
+		if i > 0 && !strings.HasPrefix(segments[i-1], "{") {
+			name = paramName(segments[i-1])
+		}
+		unique := name
+		for n := 2; used[unique]; n++ {
+			unique = fmt.Sprintf("%s%d", name, n)
+		}
+		used[unique] = true
+		names = append(names, unique)
+		segments[i] = "{" + unique + "}"
+	}
+	return "/" + strings.Join(segments, "/"), names
+}
+
+// paramName derives a parameter name from the collection segment before
+// it: "orders" gives "orderId", "line-items" gives "lineItemId".
+func paramName(collection string) string {
+	words := strings.FieldsFunc(strings.ToLower(collection), func(r rune) bool {
+		return r == '-' || r == '_' || r == '.'
+	})
+	if len(words) == 0 {
+		return "id"
+	}
+	last := words[len(words)-1]
+	switch {
+	case strings.HasSuffix(last, "ies"):
+		last = strings.TrimSuffix(last, "ies") + "y"
+	case strings.HasSuffix(last, "sses"):
+		last = strings.TrimSuffix(last, "es")
+	case strings.HasSuffix(last, "s") && !strings.HasSuffix(last, "ss"):
+		last = strings.TrimSuffix(last, "s")
+	}
+	words[len(words)-1] = last
+	name := words[0]
+	for _, w := range words[1:] {
+		name += strings.ToUpper(w[:1]) + w[1:]
+	}
+	return name + "Id"
+}
+
+// methodOrder sorts operations on the same path in the usual CRUD order.
+func methodOrder(method string) int {
+	for i, m := range []string{"GET", "POST", "PUT", "PATCH", "DELETE"} {
+		if m == method {
+			return i
+		}
+	}
+	return 5
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
@@ -0,0 +1,153 @@
+package postman
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/roberthamel/skill-compiler/internal/fetch"
+	"github.com/roberthamel/skill-compiler/internal/instructions"
+	"github.com/roberthamel/skill-compiler/internal/ir"
+)
+
+// Plugin handles Postman v2.0/v2.1 collections and HAR recordings.
+type Plugin struct{}
+
+func New() *Plugin { return &Plugin{} }
+
+func (p *Plugin) Name() string { return "postman" }
+
+// collectionSchemaRe finds the schema URL every exported collection carries.
+var collectionSchemaRe = regexp.MustCompile(`"schema"\s*:\s*"https://schema\.getpostman\.com/`)
+
+// Detect accepts explicitly typed sources, .har files, exported
+// *.postman_collection.json files and untyped JSON files declaring the
+// Postman collection schema. It must be registered before the openapi
+// plugin, which claims every untyped JSON file.
+func (p *Plugin) Detect(source instructions.SpecSource) bool {
+	if source.Type == "postman" || source.Type == "har" {
+		return true
+	}
+	if source.Type != "" || source.Path == "" {
+		return false
+	}
+	lower := strings.ToLower(source.Path)
+	switch {
+	case strings.HasSuffix(lower, ".har"), strings.HasSuffix(lower, ".postman_collection.json"):
+		return true
+	case filepath.Ext(lower) != ".json":
+		return false
+	}
+	return fetch.HeadMatches(source.Path, collectionSchemaRe)
+}
+
+func (p *Plugin) Fetch(source instructions.SpecSource) ([]byte, error) {
+	return fetch.Source("postman", source)
+}
+
+// Parse reads a collection or, when the document has a top-level "log", a
+// HAR file.
+func (p *Plugin) Parse(raw []byte, source instructions.SpecSource) (*ir.IntermediateRepr, error) {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return nil, fmt.Errorf("parsing %s input: %w", p.Name(), err)
+	}
+	if _, ok := probe["log"]; ok {
+		return parseHAR(raw)
+	}
+	if _, ok := probe["item"]; ok {
+		return parseCollection(raw)
+	}
+	return nil, fmt.Errorf("input is neither a Postman collection nor a HAR file")
+}
+
+func (p *Plugin) Validate(parsed *ir.IntermediateRepr) []ir.Warning {
+	var warnings []ir.Warning
+	if len(parsed.Operations) == 0 {
+		warnings = append(warnings, ir.Warning{Message: "no requests found"})
+	}
+	if parsed.Metadata["format"] != "postman" {
+		// HAR operations are described by their recordings
+		return warnings
+	}
+	for _, op := range parsed.Operations {
+		if op.Description == "" {
+			warnings = append(warnings, ir.Warning{
+				Message: fmt.Sprintf("request %q (%s %s) has no description", op.Name, op.Method, op.Path),
+			})
+		}
+	}
+	return warnings
+}
+
+// maxExampleSize caps recorded bodies kept as examples; larger ones would
+// crowd the prompt without telling the model more.
+const maxExampleSize = 4096
+
+// example builds an ir.Example from a body, compacting JSON. Empty and
+// oversized bodies yield nil.
+func example(name, contentType, body string) *ir.Example {
+	body = strings.TrimSpace(body)
+	if body == "" || len(body) > maxExampleSize {
+		return nil
+	}
+	if isJSON(contentType) {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, []byte(body)); err == nil {
+			body = buf.String()
+		}
+	}
+	return &ir.Example{Name: name, Value: body}
+}
+
+// mediaType strips parameters from a Content-Type value.
+func mediaType(contentType string) string {
+	if mt, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mt
+	}
+	return strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+}
+
+func isJSON(contentType string) bool {
+	return contentType == "application/json" || strings.HasSuffix(contentType, "+json")
+}
+
+// sniffContentType guesses a body's media type when none was recorded.
+func sniffContentType(body string) string {
+	trimmed := strings.TrimSpace(body)
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		return "application/json"
+	}
+	if strings.HasPrefix(trimmed, "<") {
+		return "application/xml"
+	}
+	return "text/plain"
+}
+
+// sensitiveRe matches header and parameter names whose values are
+// credentials and must not be copied into the IR.
+var sensitiveRe = regexp.MustCompile(`(?i)auth|token|secret|password|passwd|api[-_]?key|cookie|session`)
+
+// exampleNote describes a sample value for a parameter description,
+// withholding values of sensitive parameters.
+func exampleNote(name, value string) string {
+	if value == "" || sensitiveRe.MatchString(name) {
+		return ""
+	}
+	return "e.g. " + value
+}
+
+// joinDescription appends note to desc as a separate sentence.
+func joinDescription(desc, note string) string {
+	switch {
+	case note == "":
+		return desc
+	case desc == "":
+		return note
+	}
+	return strings.TrimRight(desc, ". ") + ". " + note
+}
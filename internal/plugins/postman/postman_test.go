@@ -0,0 +1,271 @@
+package postman
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/roberthamel/skill-compiler/internal/instructions"
+	"github.com/roberthamel/skill-compiler/internal/ir"
+)
+
+func TestDetect(t *testing.T) {
+	p := New()
+
+	tests := []struct {
+		name   string
+		source instructions.SpecSource
+		want   bool
+	}{
+		{"postman type", instructions.SpecSource{Type: "postman", URL: "https://example.com/collection"}, true},
+		{"har type", instructions.SpecSource{Type: "har", Command: "cat session.har"}, true},
+		{"exported collection", instructions.SpecSource{Path: "testdata/orders.postman_collection.json"}, true},
+		{"har file", instructions.SpecSource{Path: "recordings/session.HAR"}, true},
+		{"plain json file", instructions.SpecSource{Path: "testdata/missing.json"}, false},
+		{"typed for another plugin", instructions.SpecSource{Type: "openapi", Path: "testdata/orders.postman_collection.json"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Detect(tt.source); got != tt.want {
+				t.Errorf("Detect(%+v) = %v, want %v", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func parseFile(t *testing.T, path string) *ir.IntermediateRepr {
+	t.Helper()
+	p := New()
+	source := instructions.SpecSource{Path: path}
+	raw, err := p.Fetch(source)
+	if err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+	result, err := p.Parse(raw, source)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	return result
+}
+
+func findOp(t *testing.T, result *ir.IntermediateRepr, id string) ir.Operation {
+	t.Helper()
+	for _, op := range result.Operations {
+		if op.ID == id {
+			return op
+		}
+	}
+	t.Fatalf("operation %s not found", id)
+	return ir.Operation{}
+}
+
+func TestParse_Collection(t *testing.T) {
+	result := parseFile(t, "testdata/orders.postman_collection.json")
+
+	if result.Metadata["title"] != "Acme Orders" || result.Metadata["baseUrl"] != "https://api.acme.example/v2" {
+		t.Errorf("metadata = %v", result.Metadata)
+	}
+	if got := result.Metadata["variables"]; got != "baseUrl, accessToken" {
+		t.Errorf("variables = %q, want names only", got)
+	}
+
+	wantGroups := map[string][]string{
+		"Orders":        {"list_orders", "get_order", "create_order"},
+		"Admin/Reports": {"export_report"},
+	}
+	if len(result.Groups) != len(wantGroups) {
+		t.Fatalf("groups = %+v", result.Groups)
+	}
+	for _, g := range result.Groups {
+		if strings.Join(g.Operations, ",") != strings.Join(wantGroups[g.Name], ",") {
+			t.Errorf("group %s operations = %v, want %v", g.Name, g.Operations, wantGroups[g.Name])
+		}
+	}
+
+	list := findOp(t, result, "list_orders")
+	if list.Method != "GET" || list.Path != "/orders" || list.Description != "Lists orders, newest first." {
+		t.Errorf("list_orders = %s %s %q", list.Method, list.Path, list.Description)
+	}
+	params := map[string]ir.Parameter{}
+	for _, param := range list.Parameters {
+		params[param.Name] = param
+	}
+	if q := params["status"]; q.In != "query" || !q.Required || q.Description != "Filter by status. e.g. open" {
+		t.Errorf("status = %+v", q)
+	}
+	if q := params["limit"]; q.Required {
+		t.Errorf("disabled query parameter limit should be optional")
+	}
+	if h := params["X-Api-Key"]; h.In != "header" || strings.Contains(h.Description, "literal-key") {
+		t.Errorf("X-Api-Key = %+v, want value withheld", h)
+	}
+	resp := list.Responses[0]
+	if resp.StatusCode != "200" || resp.Body.ContentType != "application/json" || resp.Body.Content[0].Examples[0].Value != `[{"id":"ord_1","status":"open"}]` {
+		t.Errorf("response = %+v", resp)
+	}
+
+	get := findOp(t, result, "get_order")
+	if get.Path != "/orders/{orderId}" || get.Parameters[0].In != "path" || get.Parameters[0].Description != "Order identifier. e.g. ord_1" {
+		t.Errorf("get_order = %s %+v", get.Path, get.Parameters)
+	}
+	if len(get.Responses) != 2 || get.Responses[1].StatusCode != "404" {
+		t.Errorf("get_order responses = %+v", get.Responses)
+	}
+
+	create := findOp(t, result, "create_order")
+	if create.RequestBody == nil || create.RequestBody.ContentType != "application/json" || create.RequestBody.Content[0].Examples[0].Value != `{"sku":"ABC-1","quantity":2}` {
+		t.Errorf("create_order body = %+v", create.RequestBody)
+	}
+
+	export := findOp(t, result, "export_report")
+	if export.Path != "/admin/reports/{reportId}/export" || strings.Join(export.Tags, ",") != "Admin,Reports" {
+		t.Errorf("export_report = %s %v", export.Path, export.Tags)
+	}
+	if export.RequestBody.Content[0].Examples[0].Value != "format=csv" {
+		t.Errorf("export_report body = %+v", export.RequestBody)
+	}
+
+	// Auth is inherited from the collection, overridden per folder and
+	// disabled per request
+	if strings.Join(list.Auth, ",") != "bearer" || strings.Join(export.Auth, ",") != "apiKey" {
+		t.Errorf("auth = %v / %v", list.Auth, export.Auth)
+	}
+	if health := findOp(t, result, "health"); len(health.Auth) != 0 {
+		t.Errorf("health auth = %v, want none", health.Auth)
+	}
+	if len(result.Auth) != 2 || result.Auth[0].Scheme != "bearer" || result.Auth[1].Name != "X-Admin-Key" {
+		t.Errorf("auth schemes = %+v", result.Auth)
+	}
+	for _, scheme := range result.Auth {
+		if strings.Contains(scheme.Description, "s3cr3t") {
+			t.Errorf("auth scheme %s leaks a credential", scheme.ID)
+		}
+	}
+
+	if warnings := New().Validate(result); len(warnings) != 2 {
+		t.Errorf("got %d warnings, want 2 (undescribed requests): %v", len(warnings), warnings)
+	}
+}
+
+func TestParse_HAR(t *testing.T) {
+	result := parseFile(t, "testdata/session.har")
+
+	if result.Metadata["baseUrl"] != "https://api.shop.example.com" || result.Metadata["format"] != "har" {
+		t.Errorf("metadata = %v", result.Metadata)
+	}
+	want := []struct{ method, path string }{
+		{"POST", "/v1/orders"},
+		{"GET", "/v1/orders/{orderId}"},
+		{"GET", "/v1/orders/{orderId}/line-items/{lineItemId}"},
+	}
+	if len(result.Operations) != len(want) {
+		t.Fatalf("got %d operations, want %d (asset and preflight skipped)", len(result.Operations), len(want))
+	}
+	for i, w := range want {
+		if op := result.Operations[i]; op.Method != w.method || op.Path != w.path {
+			t.Errorf("operation %d = %s %s, want %s %s", i, op.Method, op.Path, w.method, w.path)
+		}
+	}
+
+	get := result.Operations[1]
+	if get.Description != "Inferred from 2 recorded requests." {
+		t.Errorf("description = %q", get.Description)
+	}
+	params := map[string]ir.Parameter{}
+	for _, param := range get.Parameters {
+		params[param.Name] = param
+	}
+	if q := params["expand"]; q.In != "query" || q.Required {
+		t.Errorf("expand = %+v, want optional (absent from one recording)", q)
+	}
+	if _, ok := params["X-Client-Version"]; !ok {
+		t.Error("custom header X-Client-Version missing")
+	}
+	if _, ok := params["Accept"]; ok {
+		t.Error("browser header Accept should be dropped")
+	}
+	if len(get.Responses) != 2 || get.Responses[1].Body.Content[0].Examples[0].Value != `{"error":"not_found"}` {
+		t.Errorf("responses = %+v, want base64 body decoded", get.Responses)
+	}
+	if strings.Join(get.Auth, ",") != "bearer" {
+		t.Errorf("auth = %v", get.Auth)
+	}
+
+	items := result.Operations[2]
+	if strings.Join(items.Auth, ",") != "api_key" {
+		t.Errorf("line item auth = %v", items.Auth)
+	}
+	for _, param := range items.Parameters {
+		if param.Name == "api_key" {
+			t.Error("api_key should be described as auth, not a query parameter")
+		}
+	}
+
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0].Message, "skipped 2") {
+		t.Errorf("warnings = %v", result.Warnings)
+	}
+}
+
+func TestTemplatePath(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"/users/42", "/users/{userId}"},
+		{"/users/me", "/users/me"},
+		{"/categories/7/entries/8", "/categories/{categoryId}/entries/{entryId}"},
+		{"/files/9f86d081884c7d659a2feaa0c55ad015", "/files/{fileId}"},
+		{"/42/43", "/{id}/{id2}"},
+	}
+	for _, tt := range tests {
+		if got, _ := templatePath(pathSegments(tt.in), nil); got != tt.want {
+			t.Errorf("templatePath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParse_HARSlugPaths(t *testing.T) {
+	var entries []string
+	for _, req := range []string{
+		"GET /v1/posts/hello-world",
+		"GET /v1/posts/second-post",
+		"GET /v1/orders",
+		"GET /v1/users",
+		"GET /v1/users/me",
+		"GET /v1/users/7/posts/welcome",
+		"GET /v1/users/8/posts/pricing",
+		"DELETE /v1/posts/hello-world",
+	} {
+		method, path, _ := strings.Cut(req, " ")
+		entries = append(entries, fmt.Sprintf(`{"request": {"method": %q, "url": "https://api.example.com%s"}, "response": {"status": 200}}`, method, path))
+	}
+	raw := `{"log": {"entries": [` + strings.Join(entries, ",") + `]}}`
+
+	result, err := New().Parse([]byte(raw), instructions.SpecSource{Type: "har"})
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	var got []string
+	for _, op := range result.Operations {
+		got = append(got, op.Method+" "+op.Path)
+	}
+	want := []string{
+		"GET /v1/orders",
+		"DELETE /v1/posts/hello-world",
+		"GET /v1/posts/{postId}",
+		"GET /v1/users",
+		"GET /v1/users/me",
+		"GET /v1/users/{userId}/posts/{postId}",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("operations = %q, want %q", got, want)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	p := New()
+	for _, src := range []string{`{"openapi": "3.0.0"}`, `[1, 2]`, `{"item": "x"}`} {
+		if _, err := p.Parse([]byte(src), instructions.SpecSource{Type: "postman"}); err == nil {
+			t.Errorf("Parse(%q): expected error", src)
+		}
+	}
+}
@@ -0,0 +1,540 @@
+package protobuf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// protoFile is a parsed .proto file. Nested messages and enums are flattened
+// into the file's lists with dotted names relative to the package
+// (e.g. Order.Item).
+type protoFile struct {
+	path     string
+	syntax   string
+	pkg      string
+	imports  []string
+	messages []*protoMessage
+	enums    []*protoEnum
+	services []*protoService
+}
+
+type protoMessage struct {
+	name        string // relative to the package, e.g. Order.Item
+	description string
+	fields      []protoField
+}
+
+type protoField struct {
+	name        string
+	typ         string // as written; map fields are map<K, V>
+	label       string // repeated, optional, required or empty
+	oneof       string // name of the enclosing oneof, if any
+	description string
+	deprecated  bool
+}
+
+type protoEnum struct {
+	name        string
+	description string
+	values      []string
+}
+
+type protoService struct {
+	name        string
+	description string
+	methods     []protoMethod
+}
+
+type protoMethod struct {
+	name         string
+	description  string
+	input        string
+	output       string
+	clientStream bool
+	serverStream bool
+	deprecated   bool
+}
+
+type token struct {
+	value    string
+	str      bool // quoted string literal
+	line     int
+	comment  string // leading comment block
+	trailing string // comment following the token on the same line
+}
+
+// lex splits proto source into tokens, attaching comments: a comment block
+// directly above a token is its leading comment, and a comment after a token
+// on the same line is that token's trailing comment.
+func lex(src string) ([]token, error) {
+	var tokens []token
+	var block []string
+	blockEnd := 0 // line the comment block ends on
+	line := 1
+	lastLine := 0 // line of the previous token
+	emit := func(t token) {
+		// A blank line detaches a comment block from what follows
+		if len(block) > 0 && t.line <= blockEnd+1 {
+			t.comment = strings.Join(block, "\n")
+		}
+		tokens = append(tokens, t)
+		block = nil
+		lastLine = t.line
+	}
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case strings.HasPrefix(src[i:], "//"), strings.HasPrefix(src[i:], "/*"):
+			var text string
+			start := line
+			if src[i+1] == '/' {
+				end := strings.IndexByte(src[i:], '\n')
+				if end < 0 {
+					end = len(src) - i
+				}
+				text = strings.TrimSpace(strings.TrimPrefix(src[i:i+end], "//"))
+				i += end
+			} else {
+				end := strings.Index(src[i+2:], "*/")
+				if end < 0 {
+					return nil, fmt.Errorf("line %d: unterminated comment", line)
+				}
+				raw := src[i+2 : i+2+end]
+				line += strings.Count(raw, "\n")
+				var lines []string
+				for _, l := range strings.Split(raw, "\n") {
+					lines = append(lines, strings.TrimPrefix(strings.TrimSpace(l), "* "))
+				}
+				text = strings.TrimSpace(strings.Join(lines, "\n"))
+				text = strings.TrimPrefix(text, "*")
+				i += end + 4
+			}
+			if len(tokens) > 0 && start == lastLine {
+				prev := &tokens[len(tokens)-1]
+				prev.trailing = strings.TrimSpace(prev.trailing + "\n" + text)
+			} else {
+				if len(block) > 0 && start > blockEnd+1 {
+					block = nil
+				}
+				block = append(block, text)
+				blockEnd = line
+			}
+		case c == '"' || c == '\'':
+			j := i + 1
+			var b strings.Builder
+			for ; j < len(src) && src[j] != c; j++ {
+				if src[j] == '\n' {
+					return nil, fmt.Errorf("line %d: unterminated string", line)
+				}
+				if src[j] == '\\' && j+1 < len(src) {
+					j++
+				}
+				b.WriteByte(src[j])
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			emit(token{value: b.String(), str: true, line: line})
+			i = j + 1
+		case isIdentChar(c) || c == '.' || c == '-' || c == '+':
+			j := i + 1
+			for j < len(src) && (isIdentChar(src[j]) || src[j] == '.') {
+				j++
+			}
+			emit(token{value: src[i:j], line: line})
+			i = j
+		default:
+			emit(token{value: string(c), line: line})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	file   *protoFile
+}
+
+// parseProto parses the source of a single .proto file.
+func parseProto(path, src string) (*protoFile, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	p := &parser{tokens: tokens, file: &protoFile{path: path}}
+	if err := p.parseFile(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p.file, nil
+}
+
+func (p *parser) eof() bool { return p.pos >= len(p.tokens) }
+
+func (p *parser) peek() token {
+	if p.eof() {
+		return token{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() (token, error) {
+	if p.eof() {
+		line := 0
+		if len(p.tokens) > 0 {
+			line = p.tokens[len(p.tokens)-1].line
+		}
+		return token{}, fmt.Errorf("line %d: unexpected end of file", line)
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	return t, nil
+}
+
+func (p *parser) expect(value string) (token, error) {
+	t, err := p.next()
+	if err != nil {
+		return t, err
+	}
+	if t.value != value || t.str {
+		return t, fmt.Errorf("line %d: expected %q, got %q", t.line, value, t.value)
+	}
+	return t, nil
+}
+
+// skip consumes value if it is the next token.
+func (p *parser) skip(value string) bool {
+	if t := p.peek(); !p.eof() && !t.str && t.value == value {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// skipStatement consumes tokens up to and including the next ';', or a
+// balanced {...} block, whichever ends the statement.
+func (p *parser) skipStatement() (token, error) {
+	depth := 0
+	for {
+		t, err := p.next()
+		if err != nil {
+			return t, err
+		}
+		switch t.value {
+		case "{":
+			depth++
+		case "}":
+			depth--
+			if depth == 0 {
+				return t, nil
+			}
+		case ";":
+			if depth == 0 {
+				return t, nil
+			}
+		}
+	}
+}
+
+func (p *parser) parseFile() error {
+	for !p.eof() {
+		t, _ := p.next()
+		switch t.value {
+		case "syntax", "edition":
+			if _, err := p.expect("="); err != nil {
+				return err
+			}
+			v, err := p.next()
+			if err != nil {
+				return err
+			}
+			p.file.syntax = v.value
+			if _, err := p.expect(";"); err != nil {
+				return err
+			}
+		case "package":
+			name, err := p.next()
+			if err != nil {
+				return err
+			}
+			p.file.pkg = name.value
+			if _, err := p.expect(";"); err != nil {
+				return err
+			}
+		case "import":
+			p.skip("public")
+			p.skip("weak")
+			path, err := p.next()
+			if err != nil {
+				return err
+			}
+			p.file.imports = append(p.file.imports, path.value)
+			if _, err := p.expect(";"); err != nil {
+				return err
+			}
+		case "message":
+			if err := p.parseMessage(t.comment, ""); err != nil {
+				return err
+			}
+		case "enum":
+			if err := p.parseEnum(t.comment, ""); err != nil {
+				return err
+			}
+		case "service":
+			if err := p.parseService(t.comment); err != nil {
+				return err
+			}
+		case ";":
+		default:
+			// option, extend and anything else the IR has no use for
+			if _, err := p.skipStatement(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *parser) parseMessage(comment, prefix string) error {
+	name, err := p.next()
+	if err != nil {
+		return err
+	}
+	msg := &protoMessage{name: prefix + name.value, description: comment}
+	p.file.messages = append(p.file.messages, msg)
+	if _, err := p.expect("{"); err != nil {
+		return err
+	}
+	return p.parseMessageBody(msg, "")
+}
+
+// parseMessageBody parses fields and nested declarations up to the closing
+// brace. oneof is set while parsing the body of a oneof.
+func (p *parser) parseMessageBody(msg *protoMessage, oneof string) error {
+	for {
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		switch t.value {
+		case "}":
+			return nil
+		case ";":
+		case "message":
+			if err := p.parseMessage(t.comment, msg.name+"."); err != nil {
+				return err
+			}
+		case "enum":
+			if err := p.parseEnum(t.comment, msg.name+"."); err != nil {
+				return err
+			}
+		case "oneof":
+			name, err := p.next()
+			if err != nil {
+				return err
+			}
+			if _, err := p.expect("{"); err != nil {
+				return err
+			}
+			if err := p.parseMessageBody(msg, name.value); err != nil {
+				return err
+			}
+		case "option", "reserved", "extensions", "extend":
+			if _, err := p.skipStatement(); err != nil {
+				return err
+			}
+		default:
+			field := protoField{oneof: oneof, description: t.comment}
+			switch t.value {
+			case "repeated", "optional", "required":
+				field.label = t.value
+				if t, err = p.next(); err != nil {
+					return err
+				}
+			}
+			if t.value == "map" && p.skip("<") {
+				key, err := p.next()
+				if err != nil {
+					return err
+				}
+				if _, err := p.expect(","); err != nil {
+					return err
+				}
+				value, err := p.next()
+				if err != nil {
+					return err
+				}
+				if _, err := p.expect(">"); err != nil {
+					return err
+				}
+				field.typ = "map<" + key.value + ", " + value.value + ">"
+			} else {
+				field.typ = t.value
+			}
+			name, err := p.next()
+			if err != nil {
+				return err
+			}
+			field.name = name.value
+			if _, err := p.expect("="); err != nil {
+				return err
+			}
+			end, err := p.skipOptions(&field.deprecated)
+			if err != nil {
+				return err
+			}
+			if field.description == "" {
+				field.description = end.trailing
+			}
+			msg.fields = append(msg.fields, field)
+		}
+	}
+}
+
+// skipOptions consumes the rest of a field or enum value declaration
+// (number and [options]) through the terminating ';', which it returns.
+// deprecated is set if the options include deprecated = true.
+func (p *parser) skipOptions(deprecated *bool) (token, error) {
+	for {
+		t, err := p.next()
+		if err != nil {
+			return t, err
+		}
+		switch t.value {
+		case ";":
+			return t, nil
+		case "deprecated":
+			if p.skip("=") && p.skip("true") && deprecated != nil {
+				*deprecated = true
+			}
+		}
+	}
+}
+
+func (p *parser) parseEnum(comment, prefix string) error {
+	name, err := p.next()
+	if err != nil {
+		return err
+	}
+	enum := &protoEnum{name: prefix + name.value, description: comment}
+	p.file.enums = append(p.file.enums, enum)
+	if _, err := p.expect("{"); err != nil {
+		return err
+	}
+	for {
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		switch t.value {
+		case "}":
+			return nil
+		case ";":
+		case "option", "reserved":
+			if _, err := p.skipStatement(); err != nil {
+				return err
+			}
+		default:
+			enum.values = append(enum.values, t.value)
+			if _, err := p.skipOptions(nil); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (p *parser) parseService(comment string) error {
+	name, err := p.next()
+	if err != nil {
+		return err
+	}
+	svc := &protoService{name: name.value, description: comment}
+	p.file.services = append(p.file.services, svc)
+	if _, err := p.expect("{"); err != nil {
+		return err
+	}
+	for {
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		switch t.value {
+		case "}":
+			return nil
+		case ";":
+		case "rpc":
+			m, err := p.parseMethod(t.comment)
+			if err != nil {
+				return err
+			}
+			svc.methods = append(svc.methods, m)
+		default:
+			if _, err := p.skipStatement(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// parseMethod parses "Name (stream? Req) returns (stream? Resp)" followed by
+// either ';' or an options block.
+func (p *parser) parseMethod(comment string) (protoMethod, error) {
+	m := protoMethod{description: comment}
+	name, err := p.next()
+	if err != nil {
+		return m, err
+	}
+	m.name = name.value
+	if m.clientStream, m.input, err = p.parseMessageType(); err != nil {
+		return m, err
+	}
+	if _, err := p.expect("returns"); err != nil {
+		return m, err
+	}
+	if m.serverStream, m.output, err = p.parseMessageType(); err != nil {
+		return m, err
+	}
+	if p.skip(";") {
+		return m, nil
+	}
+	if _, err := p.expect("{"); err != nil {
+		return m, err
+	}
+	for !p.skip("}") {
+		t, err := p.next()
+		if err != nil {
+			return m, err
+		}
+		if t.value == "option" {
+			if _, err := p.skipOptions(&m.deprecated); err != nil {
+				return m, err
+			}
+		}
+	}
+	return m, nil
+}
+
+func (p *parser) parseMessageType() (bool, string, error) {
+	if _, err := p.expect("("); err != nil {
+		return false, "", err
+	}
+	stream := p.skip("stream")
+	t, err := p.next()
+	if err != nil {
+		return false, "", err
+	}
+	if _, err := p.expect(")"); err != nil {
+		return false, "", err
+	}
+	return stream, t.value, nil
+}
@@ -0,0 +1,326 @@
+package protobuf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/roberthamel/skill-compiler/internal/fetch"
+	"github.com/roberthamel/skill-compiler/internal/instructions"
+	"github.com/roberthamel/skill-compiler/internal/ir"
+)
+
+// Plugin handles proto3 service definitions.
+type Plugin struct{}
+
+func New() *Plugin { return &Plugin{} }
+
+func (p *Plugin) Name() string { return "protobuf" }
+
+func (p *Plugin) Detect(source instructions.SpecSource) bool {
+	if source.Type == "protobuf" || source.Type == "grpc" {
+		return true
+	}
+	return source.Type == "" && strings.ToLower(filepath.Ext(source.Path)) == ".proto"
+}
+
+// bundle is what Fetch produces: the source files, keyed by their path
+// relative to the import root, in load order.
+type bundle struct {
+	Files   []bundleFile `json:"files"`
+	Missing []string     `json:"missing,omitempty"` // imports not found on disk
+}
+
+type bundleFile struct {
+	Path     string `json:"path"`
+	Content  string `json:"content"`
+	Imported bool   `json:"imported,omitempty"` // loaded only to resolve an import
+}
+
+func (p *Plugin) Fetch(source instructions.SpecSource) ([]byte, error) {
+	if source.Path != "" {
+		return fetchPath(source)
+	}
+	return fetch.Source("protobuf", source)
+}
+
+// fetchPath loads a .proto file, or every .proto file under a directory,
+// along with the files they import. Imports are resolved relative to the
+// directory (or the file's directory), then the working directory.
+func fetchPath(source instructions.SpecSource) ([]byte, error) {
+	info, err := os.Stat(source.Path)
+	if err != nil {
+		return nil, err
+	}
+	root := source.Path
+	var queue []string
+	if info.IsDir() {
+		err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, _ := filepath.Rel(root, path)
+			rel = filepath.ToSlash(rel)
+			if fi.IsDir() {
+				if rel != "." && source.Excludes(rel) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if strings.HasSuffix(path, ".proto") && !source.Excludes(rel) {
+				queue = append(queue, rel)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("walking %s: %w", root, err)
+		}
+	} else {
+		root = filepath.Dir(source.Path)
+		queue = []string{filepath.Base(source.Path)}
+	}
+
+	var b bundle
+	loaded := make(map[string]bool)
+	explicit := len(queue)
+	for i := 0; i < len(queue); i++ {
+		rel := queue[i]
+		if loaded[rel] {
+			continue
+		}
+		loaded[rel] = true
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+		if err != nil && i >= explicit {
+			// Imports may also be relative to the working directory
+			data, err = os.ReadFile(filepath.FromSlash(rel))
+		}
+		if err != nil {
+			if i < explicit {
+				return nil, err
+			}
+			// Well-known types (google/protobuf/*) are usually not vendored
+			if !strings.HasPrefix(rel, "google/protobuf/") {
+				b.Missing = append(b.Missing, rel)
+			}
+			continue
+		}
+		b.Files = append(b.Files, bundleFile{Path: rel, Content: string(data), Imported: i >= explicit})
+		if f, err := parseProto(rel, string(data)); err == nil {
+			queue = append(queue, f.imports...)
+		}
+	}
+	return json.Marshal(b)
+}
+
+func (p *Plugin) Parse(raw []byte, source instructions.SpecSource) (*ir.IntermediateRepr, error) {
+	var b bundle
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(trimmed, &b); err != nil {
+			return nil, fmt.Errorf("parsing protobuf bundle: %w", err)
+		}
+	} else {
+		// A single file from a URL or command
+		name := filepath.Base(source.URL)
+		if name == "." || name == "/" {
+			name = "input.proto"
+		}
+		b.Files = []bundleFile{{Path: name, Content: string(raw)}}
+	}
+
+	var files []*protoFile
+	imported := make(map[*protoFile]bool)
+	for _, bf := range b.Files {
+		f, err := parseProto(bf.Path, bf.Content)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+		imported[f] = bf.Imported
+	}
+
+	// Index every message and enum by fully-qualified name for resolution
+	known := make(map[string]bool)
+	for _, f := range files {
+		for _, m := range f.messages {
+			known[qualify(f.pkg, m.name)] = true
+		}
+		for _, e := range f.enums {
+			known[qualify(f.pkg, e.name)] = true
+		}
+	}
+
+	result := &ir.IntermediateRepr{
+		Metadata: make(map[string]string),
+	}
+	for _, missing := range b.Missing {
+		result.Warnings = append(result.Warnings, ir.Warning{Message: fmt.Sprintf("import %q not found", missing)})
+	}
+	var packages []string
+	seenPkg := make(map[string]bool)
+	for _, f := range files {
+		if !imported[f] && f.pkg != "" && !seenPkg[f.pkg] {
+			seenPkg[f.pkg] = true
+			packages = append(packages, f.pkg)
+		}
+	}
+	sort.Strings(packages)
+	if len(packages) > 0 {
+		result.Metadata["packages"] = strings.Join(packages, ", ")
+	}
+
+	// IDs are Service.Method, falling back to the fully-qualified service
+	// name when services in two packages (e.g. v1 and v2) share a name
+	usedIDs := make(map[string]bool)
+	for _, f := range files {
+		if imported[f] {
+			continue
+		}
+		for _, svc := range f.services {
+			fullService := qualify(f.pkg, svc.name)
+			group := ir.Group{Name: fullService, Description: svc.description}
+			for _, m := range svc.methods {
+				id := svc.name + "." + m.name
+				if usedIDs[id] {
+					id = fullService + "." + m.name
+				}
+				usedIDs[id] = true
+				op := ir.Operation{
+					ID:          id,
+					Name:        m.name,
+					Description: m.description,
+					Method:      streamingMode(m),
+					Path:        "/" + fullService + "/" + m.name,
+					Tags:        []string{fullService},
+					Deprecated:  m.deprecated,
+					RequestBody: &ir.TypeRef{
+						TypeName:    resolve(m.input, f.pkg, known),
+						ContentType: "application/grpc",
+					},
+					Responses: []ir.Response{{
+						StatusCode: "OK",
+						Body: &ir.TypeRef{
+							TypeName:    resolve(m.output, f.pkg, known),
+							ContentType: "application/grpc",
+						},
+					}},
+				}
+				result.Operations = append(result.Operations, op)
+				group.Operations = append(group.Operations, op.ID)
+			}
+			result.Groups = append(result.Groups, group)
+		}
+	}
+
+	// Types from every file, imported ones included, since services refer
+	// to them
+	for _, f := range files {
+		for _, m := range f.messages {
+			scope := qualify(f.pkg, m.name)
+			td := ir.TypeDef{
+				Name:        scope,
+				Kind:        "message",
+				Description: m.description,
+			}
+			for _, field := range m.fields {
+				tf := ir.TypeField{
+					Name:        field.name,
+					Type:        fieldType(field, scope, known),
+					Description: field.description,
+					Required:    field.label == "required",
+					Nullable:    field.label == "optional",
+				}
+				if field.oneof != "" {
+					tf.Description = strings.TrimSpace(tf.Description + " (oneof " + field.oneof + ": at most one field of the group is set)")
+				}
+				if field.deprecated {
+					tf.Description = strings.TrimSpace(tf.Description + " (deprecated)")
+				}
+				td.Fields = append(td.Fields, tf)
+			}
+			result.Types = append(result.Types, td)
+		}
+		for _, e := range f.enums {
+			result.Types = append(result.Types, ir.TypeDef{
+				Name:        qualify(f.pkg, e.name),
+				Kind:        "enum",
+				Description: e.description,
+				Enum:        e.values,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// streamingMode describes an RPC's streaming mode for Operation.Method.
+func streamingMode(m protoMethod) string {
+	switch {
+	case m.clientStream && m.serverStream:
+		return "BIDI_STREAMING"
+	case m.clientStream:
+		return "CLIENT_STREAMING"
+	case m.serverStream:
+		return "SERVER_STREAMING"
+	}
+	return "UNARY"
+}
+
+func qualify(pkg, name string) string {
+	if pkg == "" {
+		return name
+	}
+	return pkg + "." + name
+}
+
+// resolve finds the fully-qualified name a type reference written in scope
+// refers to, following protobuf scoping: the innermost enclosing scope is
+// searched first. Scalars and unknown types are returned as written.
+func resolve(ref, scope string, known map[string]bool) string {
+	if strings.HasPrefix(ref, ".") {
+		return strings.TrimPrefix(ref, ".")
+	}
+	for scope != "" {
+		if candidate := scope + "." + ref; known[candidate] {
+			return candidate
+		}
+		i := strings.LastIndex(scope, ".")
+		if i < 0 {
+			break
+		}
+		scope = scope[:i]
+	}
+	return ref
+}
+
+// fieldType renders a field's type with message and enum names resolved:
+// repeated fields as []T and map fields as map<K, V>.
+func fieldType(f protoField, scope string, known map[string]bool) string {
+	if strings.HasPrefix(f.typ, "map<") {
+		key, value, _ := strings.Cut(strings.TrimSuffix(strings.TrimPrefix(f.typ, "map<"), ">"), ", ")
+		return "map<" + key + ", " + resolve(value, scope, known) + ">"
+	}
+	typ := resolve(f.typ, scope, known)
+	if f.label == "repeated" {
+		return "[]" + typ
+	}
+	return typ
+}
+
+func (p *Plugin) Validate(parsed *ir.IntermediateRepr) []ir.Warning {
+	var warnings []ir.Warning
+	if len(parsed.Operations) == 0 {
+		warnings = append(warnings, ir.Warning{Message: "no services defined"})
+	}
+	for _, op := range parsed.Operations {
+		if op.Description == "" {
+			warnings = append(warnings, ir.Warning{
+				Message: fmt.Sprintf("rpc %s has no comment", op.Path),
+			})
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,213 @@
+package protobuf
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/roberthamel/skill-compiler/internal/instructions"
+	"github.com/roberthamel/skill-compiler/internal/ir"
+)
+
+func TestDetect(t *testing.T) {
+	p := New()
+
+	tests := []struct {
+		name   string
+		source instructions.SpecSource
+		want   bool
+	}{
+		{"proto file", instructions.SpecSource{Path: "api/orders.proto"}, true},
+		{"directory with type", instructions.SpecSource{Type: "protobuf", Path: "api/"}, true},
+		{"grpc type", instructions.SpecSource{Type: "grpc", Path: "api/"}, true},
+		{"directory without type", instructions.SpecSource{Path: "api/"}, false},
+		{"yaml file", instructions.SpecSource{Path: "api.yaml"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Detect(tt.source); got != tt.want {
+				t.Errorf("Detect(%+v) = %v, want %v", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func parseDir(t *testing.T) (*Plugin, *ir.IntermediateRepr) {
+	t.Helper()
+	p := New()
+	source := instructions.SpecSource{Type: "protobuf", Path: "testdata/protos"}
+	raw, err := p.Fetch(source)
+	if err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+	result, err := p.Parse(raw, source)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	return p, result
+}
+
+func TestParse_Services(t *testing.T) {
+	_, result := parseDir(t)
+
+	if got := result.Metadata["packages"]; got != "acme.common.v1, acme.orders.v1" {
+		t.Errorf("packages = %q", got)
+	}
+	if len(result.Groups) != 1 || result.Groups[0].Name != "acme.orders.v1.OrderService" || result.Groups[0].Description != "OrderService manages customer orders." {
+		t.Errorf("groups = %+v", result.Groups)
+	}
+
+	want := []struct {
+		id, method, input, output string
+	}{
+		{"OrderService.GetOrder", "UNARY", "acme.orders.v1.GetOrderRequest", "acme.orders.v1.Order"},
+		{"OrderService.WatchOrders", "SERVER_STREAMING", "acme.orders.v1.WatchOrdersRequest", "acme.orders.v1.Order"},
+		{"OrderService.ImportItems", "CLIENT_STREAMING", "acme.orders.v1.Order.Item", "acme.orders.v1.ImportSummary"},
+		{"OrderService.EditOrder", "BIDI_STREAMING", "acme.orders.v1.EditCommand", "acme.orders.v1.Order"},
+	}
+	if len(result.Operations) != len(want) {
+		t.Fatalf("got %d operations, want %d", len(result.Operations), len(want))
+	}
+	for i, w := range want {
+		op := result.Operations[i]
+		if op.ID != w.id || op.Method != w.method || op.RequestBody.TypeName != w.input || op.Responses[0].Body.TypeName != w.output {
+			t.Errorf("operation %d = %s %s (%s) -> %s, want %+v", i, op.ID, op.Method, op.RequestBody.TypeName, op.Responses[0].Body.TypeName, w)
+		}
+	}
+	get := result.Operations[0]
+	if get.Path != "/acme.orders.v1.OrderService/GetOrder" || get.Description != "Fetch a single order by ID." {
+		t.Errorf("GetOrder path/description = %q / %q", get.Path, get.Description)
+	}
+	if !result.Operations[1].Deprecated {
+		t.Error("WatchOrders should be deprecated")
+	}
+	if got := result.Operations[3].Description; got != "Interactive order editing session." {
+		t.Errorf("EditOrder description = %q", got)
+	}
+}
+
+func TestParse_Messages(t *testing.T) {
+	_, result := parseDir(t)
+
+	types := map[string]ir.TypeDef{}
+	for _, td := range result.Types {
+		types[td.Name] = td
+	}
+	money, ok := types["acme.common.v1.Money"]
+	if !ok || money.Description != "An amount of money in a currency." || money.Fields[0].Description != "ISO 4217" {
+		t.Errorf("Money = %+v, want imported message with comments", money)
+	}
+
+	order := types["acme.orders.v1.Order"]
+	if order.Kind != "message" || order.Description != "An order placed by a customer." {
+		t.Errorf("Order = %+v", order)
+	}
+	fields := map[string]ir.TypeField{}
+	for _, f := range order.Fields {
+		fields[f.Name] = f
+	}
+	checks := map[string]string{
+		"id":        "string",
+		"status":    "acme.orders.v1.Status",
+		"items":     "[]acme.orders.v1.Order.Item",
+		"labels":    "map<string, string>",
+		"placed_at": "google.protobuf.Timestamp",
+	}
+	for name, typ := range checks {
+		if fields[name].Type != typ {
+			t.Errorf("field %s type = %q, want %q", name, fields[name].Type, typ)
+		}
+	}
+	if fields["id"].Description != "Server-assigned identifier" {
+		t.Errorf("id description = %q, want trailing comment", fields["id"].Description)
+	}
+	if !strings.Contains(fields["address"].Description, "oneof delivery") {
+		t.Errorf("address description = %q, want oneof noted", fields["address"].Description)
+	}
+	if !strings.Contains(fields["legacy_ref"].Description, "deprecated") || !fields["note"].Nullable {
+		t.Errorf("legacy_ref/note = %+v / %+v", fields["legacy_ref"], fields["note"])
+	}
+
+	if item := types["acme.orders.v1.Order.Item"]; item.Fields[2].Type != "acme.common.v1.Money" {
+		t.Errorf("Item.price type = %q, want resolved import", item.Fields[2].Type)
+	}
+	if status := types["acme.orders.v1.Status"]; status.Kind != "enum" || len(status.Enum) != 3 {
+		t.Errorf("Status = %+v", status)
+	}
+}
+
+func TestParse_SingleFile(t *testing.T) {
+	p := New()
+	src := `syntax = "proto3";
+service Echo {
+  rpc Say(Msg) returns (Msg);
+}
+message Msg { string text = 1; }
+`
+	result, err := p.Parse([]byte(src), instructions.SpecSource{Type: "protobuf", Command: "cat echo.proto"})
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if len(result.Operations) != 1 || result.Operations[0].Path != "/Echo/Say" || result.Operations[0].RequestBody.TypeName != "Msg" {
+		t.Errorf("operations = %+v", result.Operations)
+	}
+	if warnings := p.Validate(result); len(warnings) != 1 {
+		t.Errorf("got %d warnings, want 1 (uncommented rpc)", len(warnings))
+	}
+}
+
+func TestParse_VersionedServices(t *testing.T) {
+	dir := t.TempDir()
+	for _, version := range []string{"v1", "v2"} {
+		src := `syntax = "proto3";
+package acme.orders.` + version + `;
+// Orders ` + version + `.
+service OrderService {
+  // Fetch an order.
+  rpc GetOrder(GetOrderRequest) returns (GetOrderRequest);
+}
+message GetOrderRequest { string id = 1; }
+`
+		if err := os.MkdirAll(filepath.Join(dir, version), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, version, "orders.proto"), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := New()
+	source := instructions.SpecSource{Type: "protobuf", Path: dir}
+	raw, err := p.Fetch(source)
+	if err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+	result, err := p.Parse(raw, source)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if len(result.Operations) != 2 {
+		t.Fatalf("got %d operations, want 2", len(result.Operations))
+	}
+	v1, v2 := result.Operations[0], result.Operations[1]
+	if v1.ID != "OrderService.GetOrder" || v2.ID != "acme.orders.v2.OrderService.GetOrder" {
+		t.Errorf("ids = %q, %q, want the v2 service fully qualified", v1.ID, v2.ID)
+	}
+	if v2.Path != "/acme.orders.v2.OrderService/GetOrder" || result.Groups[1].Operations[0] != v2.ID {
+		t.Errorf("v2 = %+v, groups = %+v", v2, result.Groups)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	p := New()
+	for _, src := range []string{
+		"service S { rpc M(A) returns B; }",
+		"message M { string id = 1;",
+		"/* unterminated",
+	} {
+		if _, err := p.Parse([]byte(src), instructions.SpecSource{Type: "protobuf"}); err == nil {
+			t.Errorf("Parse(%q): expected error", src)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/roberthamel/skill-compiler/internal/fetch"
+	"github.com/roberthamel/skill-compiler/internal/instructions"
+	"github.com/roberthamel/skill-compiler/internal/ir"
+	"gopkg.in/yaml.v3"
+)
+
+// resolvedRefKey is added to every inlined node and records the $ref it
+// replaced, so named types survive resolution.
+const resolvedRefKey = "$resolvedRef"
+
+// Load decodes a YAML or JSON document and inlines its $refs, as Resolve
+// does.
+func Load(raw []byte, source instructions.SpecSource) (map[string]interface{}, []ir.Warning, error) {
+	doc, err := Unmarshal(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc, warnings := Resolve(doc, source)
+	return doc, warnings, nil
+}
+
+// Resolve returns a copy of doc with its $refs inlined, resolving external
+// references relative to the source. Inlined nodes record the $ref they
+// replaced under $resolvedRef. References that cannot be resolved, and
+// circular ones, are left in place and reported as warnings.
+func Resolve(doc map[string]interface{}, source instructions.SpecSource) (map[string]interface{}, []ir.Warning) {
+	r := newRefResolver()
+	doc = r.resolveDocument(doc, sourceLocation(source))
+	return doc, r.warnings
+}
+
+// refResolver inlines $ref pointers, loading external documents relative to
+// the document that references them. Loaded documents are cached by
+// location, and expanded targets by their key so that a schema referenced
+// in many places is only expanded once.
+type refResolver struct {
+	docs     map[string]map[string]interface{}
+	resolved map[string]interface{}
+	cycles   int // circular refs found so far; expansions that hit one are not cached
+	warned   map[string]bool
+	warnings []ir.Warning
+}
+
+func newRefResolver() *refResolver {
+	return &refResolver{
+		docs:     make(map[string]map[string]interface{}),
+		resolved: make(map[string]interface{}),
+		warned:   make(map[string]bool),
+	}
+}
+
+// sourceLocation returns the location external refs in the root document are
+// resolved against: an absolute file path, a URL, or "" for command sources.
+func sourceLocation(source instructions.SpecSource) string {
+	if source.Path != "" {
+		if abs, err := filepath.Abs(source.Path); err == nil {
+			return abs
+		}
+		return source.Path
+	}
+	return source.URL
+}
+
+// resolveDocument returns a copy of root with all resolvable $refs inlined.
+// loc is the root document's location and may be empty.
+func (r *refResolver) resolveDocument(root map[string]interface{}, loc string) map[string]interface{} {
+	if loc != "" {
+		r.docs[loc] = root
+	}
+	resolved, _ := r.resolve(root, loc, root, nil).(map[string]interface{})
+	return resolved
+}
+
+// resolve walks node (which belongs to the document at loc) and returns a
+// copy with $refs replaced by their targets. stack holds the refs currently
+// being expanded so that cycles are left as $ref and reported once.
+func (r *refResolver) resolve(node interface{}, loc string, doc map[string]interface{}, stack []string) interface{} {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok {
+			return r.resolveRef(v, ref, loc, doc, stack)
+		}
+		out := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			out[k] = r.resolve(val, loc, doc, stack)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = r.resolve(item, loc, doc, stack)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func (r *refResolver) resolveRef(node map[string]interface{}, ref, loc string, doc map[string]interface{}, stack []string) interface{} {
+	target, targetLoc, targetDoc, key, err := r.lookup(ref, loc, doc)
+	if err != nil {
+		r.warn(key, loc, fmt.Sprintf("unresolved $ref %q: %s", ref, err))
+		return copyMap(node)
+	}
+	for _, s := range stack {
+		if s == key {
+			r.cycles++
+			r.warn("cycle:"+key, loc, fmt.Sprintf("circular $ref %q left unresolved", ref))
+			return copyMap(node)
+		}
+	}
+
+	// A target that contains a cycle expands differently depending on where
+	// it is reached from, so only complete expansions are reused
+	resolved, ok := r.resolved[key]
+	if !ok {
+		cycles := r.cycles
+		resolved = r.resolve(target, targetLoc, targetDoc, append(stack, key))
+		if r.cycles == cycles {
+			r.resolved[key] = resolved
+		}
+	}
+	rm, ok := resolved.(map[string]interface{})
+	if !ok {
+		return resolved
+	}
+	// Sibling keys next to $ref are kept unless the target defines them.
+	out := make(map[string]interface{}, len(node)+len(rm))
+	for k, val := range node {
+		if k != "$ref" {
+			out[k] = r.resolve(val, loc, doc, stack)
+		}
+	}
+	for k, val := range rm {
+		out[k] = val
+	}
+	out[resolvedRefKey] = ref
+	return out
+}
+
+// lookup locates the target of ref. It returns the target node, the location
+// and root of the document containing it, and a unique key for the target.
+func (r *refResolver) lookup(ref, loc string, doc map[string]interface{}) (interface{}, string, map[string]interface{}, string, error) {
+	docPart, pointer, _ := strings.Cut(ref, "#")
+
+	targetLoc := loc
+	targetDoc := doc
+	if docPart != "" {
+		var err error
+		targetLoc, err = resolveLocation(loc, docPart)
+		if err != nil {
+			return nil, "", nil, ref, err
+		}
+		targetDoc, err = r.load(targetLoc)
+		if err != nil {
+			return nil, "", nil, targetLoc + "#" + pointer, err
+		}
+	}
+
+	key := targetLoc + "#" + pointer
+	target := lookupPointer(pointer, targetDoc)
+	if target == nil {
+		return nil, "", nil, key, fmt.Errorf("pointer %q not found", "#"+pointer)
+	}
+	return target, targetLoc, targetDoc, key, nil
+}
+
+// load reads and caches the document at loc (a file path or URL).
+func (r *refResolver) load(loc string) (map[string]interface{}, error) {
+	if doc, ok := r.docs[loc]; ok {
+		return doc, nil
+	}
+	var data []byte
+	var err error
+	if isURL(loc) {
+		data, err = fetch.URL(loc)
+	} else {
+		data, err = os.ReadFile(loc)
+	}
+	if err != nil {
+		return nil, err
+	}
+	doc, err := Unmarshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", loc, err)
+	}
+	r.docs[loc] = doc
+	return doc, nil
+}
+
+// warn records a warning once per key.
+func (r *refResolver) warn(key, loc, msg string) {
+	if r.warned[key] {
+		return
+	}
+	r.warned[key] = true
+	r.warnings = append(r.warnings, ir.Warning{Message: msg, Path: loc})
+}
+
+// resolveLocation resolves ref (the document part of a $ref) against base.
+func resolveLocation(base, ref string) (string, error) {
+	if isURL(ref) {
+		return ref, nil
+	}
+	if isURL(base) {
+		baseURL, err := url.Parse(base)
+		if err != nil {
+			return "", err
+		}
+		refURL, err := url.Parse(ref)
+		if err != nil {
+			return "", err
+		}
+		return baseURL.ResolveReference(refURL).String(), nil
+	}
+	if filepath.IsAbs(ref) {
+		return filepath.Clean(ref), nil
+	}
+	if base == "" {
+		return "", fmt.Errorf("relative reference with no base location (use path or url in the spec source)")
+	}
+	return filepath.Join(filepath.Dir(base), filepath.FromSlash(ref)), nil
+}
+
+// lookupPointer resolves a JSON pointer fragment like /components/schemas/Foo.
+// Each segment is percent-decoded, as URI fragments are, and then has its
+// ~1 and ~0 escapes replaced, so keys may contain "/" either way.
+func lookupPointer(pointer string, root map[string]interface{}) interface{} {
+	if pointer == "" || pointer == "/" {
+		return root
+	}
+	var current interface{} = root
+	for _, part := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if unescaped, err := url.PathUnescape(part); err == nil {
+			part = unescaped
+		}
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[part]
+	}
+	return current
+}
+
+// Unmarshal decodes a YAML or JSON document, converting non-string keys to
+// strings.
+func Unmarshal(data []byte) (map[string]interface{}, error) {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		// Try JSON
+		if err2 := json.Unmarshal(data, &doc); err2 != nil {
+			return nil, err
+		}
+	}
+	normalized, _ := normalizeKeys(doc).(map[string]interface{})
+	return normalized, nil
+}
+
+// normalizeKeys converts maps with non-string keys, which yaml.v3 produces
+// for unquoted keys such as response codes, into map[string]interface{}.
+func normalizeKeys(node interface{}) interface{} {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			v[k] = normalizeKeys(val)
+		}
+		return v
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			out[fmt.Sprint(k)] = normalizeKeys(val)
+		}
+		return out
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeKeys(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,68 @@
+package schema
+
+import "testing"
+
+func TestLookupPointer(t *testing.T) {
+	root := map[string]interface{}{
+		"paths": map[string]interface{}{
+			"/users/{id}": "users",
+			"a%b":         "percent",
+			"x~y":         "tilde",
+		},
+	}
+	tests := []struct {
+		pointer string
+		want    interface{}
+	}{
+		{"/paths/~1users~1{id}", "users"},
+		{"/paths/%2Fusers%2F%7Bid%7D", "users"},
+		{"/paths/a%25b", "percent"},
+		{"/paths/x~0y", "tilde"},
+		{"/paths/missing", nil},
+	}
+	for _, tt := range tests {
+		if got := lookupPointer(tt.pointer, root); got != tt.want {
+			t.Errorf("lookupPointer(%q) = %v, want %v", tt.pointer, got, tt.want)
+		}
+	}
+}
+
+func TestResolve_SharedRefs(t *testing.T) {
+	doc, err := Unmarshal([]byte(`
+properties:
+  billing: {$ref: "#/$defs/Address"}
+  shipping: {$ref: "#/$defs/Address"}
+  parent: {$ref: "#/$defs/Node"}
+$defs:
+  Address:
+    type: object
+    properties:
+      street: {type: string}
+  Node:
+    type: object
+    properties:
+      child: {$ref: "#/$defs/Node"}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newRefResolver()
+	resolved := r.resolveDocument(doc, "")
+
+	if _, ok := r.resolved["#/$defs/Address"]; !ok {
+		t.Error("Address expansion not cached")
+	}
+	if _, ok := r.resolved["#/$defs/Node"]; ok {
+		t.Error("Node expansion cached even though it contains a cycle")
+	}
+	props := resolved["properties"].(map[string]interface{})
+	for _, name := range []string{"billing", "shipping"} {
+		addr := props[name].(map[string]interface{})
+		if addr["type"] != "object" || addr[resolvedRefKey] != "#/$defs/Address" {
+			t.Errorf("%s = %v, want the inlined Address", name, addr)
+		}
+	}
+	if len(r.warnings) != 1 {
+		t.Errorf("warnings = %v, want the circular Node ref", r.warnings)
+	}
+}
@@ -0,0 +1,306 @@
+// Package schema converts JSON Schemas, as embedded in OpenAPI, AsyncAPI
+// and standalone schema documents, into IR types.
+package schema
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Schema struct {
+	Ref           string             `yaml:"$ref" json:"$ref"`
+	ResolvedRef   string             `yaml:"$resolvedRef" json:"$resolvedRef"` // set by refResolver
+	Type          schemaTypes        `yaml:"type" json:"type"`
+	Format        string             `yaml:"format" json:"format"`
+	Description   string             `yaml:"description" json:"description"`
+	Properties    map[string]*Schema `yaml:"properties" json:"properties"`
+	Items         *Schema            `yaml:"items" json:"items"`
+	PrefixItems   []*Schema          `yaml:"prefixItems" json:"prefixItems"` // 3.1 tuples
+	Required      []string           `yaml:"required" json:"required"`
+	Enum          []interface{}      `yaml:"enum" json:"enum"`
+	Const         interface{}        `yaml:"const" json:"const"`       // 3.1
+	Nullable      bool               `yaml:"nullable" json:"nullable"` // 3.0; 3.1 uses type: [T, "null"]
+	Defs          map[string]*Schema `yaml:"$defs" json:"$defs"`       // 3.1
+	Example       interface{}        `yaml:"example" json:"example"`   // 3.0
+	Examples      []interface{}      `yaml:"examples" json:"examples"` // 3.1
+	AllOf         []*Schema          `yaml:"allOf" json:"allOf"`
+	OneOf         []*Schema          `yaml:"oneOf" json:"oneOf"`
+	AnyOf         []*Schema          `yaml:"anyOf" json:"anyOf"`
+	Discriminator *Discriminator     `yaml:"discriminator" json:"discriminator"`
+	Title         string             `yaml:"title" json:"title"`
+	Default       interface{}        `yaml:"default" json:"default"`
+
+	// Validation keywords, reported as constraints
+	Pattern              string      `yaml:"pattern" json:"pattern"`
+	Minimum              interface{} `yaml:"minimum" json:"minimum"`
+	Maximum              interface{} `yaml:"maximum" json:"maximum"`
+	ExclusiveMinimum     interface{} `yaml:"exclusiveMinimum" json:"exclusiveMinimum"` // bool in 3.0, number in 3.1
+	ExclusiveMaximum     interface{} `yaml:"exclusiveMaximum" json:"exclusiveMaximum"`
+	MultipleOf           interface{} `yaml:"multipleOf" json:"multipleOf"`
+	MinLength            interface{} `yaml:"minLength" json:"minLength"`
+	MaxLength            interface{} `yaml:"maxLength" json:"maxLength"`
+	MinItems             interface{} `yaml:"minItems" json:"minItems"`
+	MaxItems             interface{} `yaml:"maxItems" json:"maxItems"`
+	UniqueItems          bool        `yaml:"uniqueItems" json:"uniqueItems"`
+	MinProperties        interface{} `yaml:"minProperties" json:"minProperties"`
+	MaxProperties        interface{} `yaml:"maxProperties" json:"maxProperties"`
+	AdditionalProperties interface{} `yaml:"additionalProperties" json:"additionalProperties"` // bool or schema
+	ReadOnly             bool        `yaml:"readOnly" json:"readOnly"`
+	WriteOnly            bool        `yaml:"writeOnly" json:"writeOnly"`
+	Deprecated           bool        `yaml:"deprecated" json:"deprecated"`
+
+	Extra map[string]interface{} `yaml:",inline" json:"-"` // unknown keys, including x-* extensions
+}
+
+type Discriminator struct {
+	PropertyName string            `yaml:"propertyName" json:"propertyName"`
+	Mapping      map[string]string `yaml:"mapping" json:"mapping"`
+}
+
+// Decode converts a decoded YAML/JSON value into a schema.
+func Decode(raw interface{}) *Schema {
+	if raw == nil {
+		return nil
+	}
+	data, err := yaml.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var s Schema
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil
+	}
+	return &s
+}
+
+// schemaTypes holds a schema's type, which OpenAPI 3.1 allows to be either a
+// single name or an array of names (e.g. [string, "null"]).
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Kind {
+	case yaml.ScalarNode:
+		*t = schemaTypes{value.Value}
+		return nil
+	case yaml.SequenceNode:
+		var types []string
+		if err := value.Decode(&types); err != nil {
+			return err
+		}
+		*t = types
+		return nil
+	default:
+		return fmt.Errorf("schema type must be a string or array of strings")
+	}
+}
+
+// nonNull returns the declared types without "null".
+func (t schemaTypes) nonNull() []string {
+	var out []string
+	for _, name := range t {
+		if name != "null" {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+func (t schemaTypes) has(name string) bool {
+	for _, n := range t {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaName returns the type name of a schema that was (or, for circular
+// references, still is) a $ref, or "" for inline schemas.
+func schemaName(s *Schema) string {
+	if s == nil {
+		return ""
+	}
+	if s.ResolvedRef != "" {
+		return RefName(s.ResolvedRef)
+	}
+	if s.Ref != "" {
+		return RefName(s.Ref)
+	}
+	return ""
+}
+
+// TypeOf renders a compact type name such as "string", "[]integer",
+// "string(date-time)", "[string, integer]" for tuples or "string|integer"
+// for multi-typed schemas. Referenced schemas render as their type name and
+// unions as their variants joined by "|". Nullability is reported separately.
+func TypeOf(s *Schema) string {
+	if s == nil {
+		return ""
+	}
+	if name := schemaName(s); name != "" {
+		return name
+	}
+	if len(s.OneOf) > 0 || len(s.AnyOf) > 0 {
+		var names []string
+		for _, v := range append(append([]*Schema(nil), s.OneOf...), s.AnyOf...) {
+			names = append(names, TypeOf(v))
+		}
+		return strings.Join(names, "|")
+	}
+	if len(s.AllOf) == 1 {
+		return TypeOf(s.AllOf[0])
+	}
+	types := s.Type.nonNull()
+	if len(types) == 0 {
+		types = literalTypes(s)
+	}
+	parts := make([]string, 0, len(types))
+	for _, t := range types {
+		switch {
+		case t == "array" && len(s.PrefixItems) > 0:
+			items := make([]string, len(s.PrefixItems))
+			for i, item := range s.PrefixItems {
+				items[i] = TypeOf(item)
+			}
+			parts = append(parts, "["+strings.Join(items, ", ")+"]")
+		case t == "array" && s.Items != nil:
+			parts = append(parts, "[]"+TypeOf(s.Items))
+		case t == "object" && len(s.Properties) == 0 && additionalSchema(s) != nil:
+			parts = append(parts, "map[string]"+TypeOf(additionalSchema(s)))
+		case s.Format != "":
+			parts = append(parts, t+"("+s.Format+")")
+		default:
+			parts = append(parts, t)
+		}
+	}
+	return strings.Join(parts, "|")
+}
+
+// literalTypes infers the types of an untyped schema from its enum or
+// const values.
+func literalTypes(s *Schema) []string {
+	values := s.Enum
+	if s.Const != nil {
+		values = append(values, s.Const)
+	}
+	var types []string
+	for _, v := range values {
+		var t string
+		switch v.(type) {
+		case string:
+			t = "string"
+		case bool:
+			t = "boolean"
+		case int, int64, uint64:
+			t = "integer"
+		case float64:
+			t = "number"
+		default:
+			continue
+		}
+		if !slices.Contains(types, t) {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// additionalSchema returns the schema of an object's additional properties,
+// or nil when they are unconstrained or forbidden.
+func additionalSchema(s *Schema) *Schema {
+	if _, ok := s.AdditionalProperties.(map[string]interface{}); !ok {
+		return nil
+	}
+	return Decode(s.AdditionalProperties)
+}
+
+// schemaConstraints collects a schema's validation keywords, keyed by
+// keyword, with values rendered as in the spec.
+func schemaConstraints(s *Schema) map[string]string {
+	out := make(map[string]string)
+	set := func(keyword string, v interface{}) {
+		if v != nil && v != false {
+			out[keyword] = FormatValue(v)
+		}
+	}
+	if s.Pattern != "" {
+		out["pattern"] = s.Pattern
+	}
+	set("minimum", s.Minimum)
+	set("maximum", s.Maximum)
+	set("exclusiveMinimum", s.ExclusiveMinimum)
+	set("exclusiveMaximum", s.ExclusiveMaximum)
+	set("multipleOf", s.MultipleOf)
+	set("minLength", s.MinLength)
+	set("maxLength", s.MaxLength)
+	set("minItems", s.MinItems)
+	set("maxItems", s.MaxItems)
+	set("uniqueItems", s.UniqueItems)
+	set("minProperties", s.MinProperties)
+	set("maxProperties", s.MaxProperties)
+	set("readOnly", s.ReadOnly)
+	set("writeOnly", s.WriteOnly)
+	set("deprecated", s.Deprecated)
+	if s.AdditionalProperties == false {
+		out["additionalProperties"] = "false"
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// schemaNullable reports whether null is an allowed value.
+func schemaNullable(s *Schema) bool {
+	if s == nil {
+		return false
+	}
+	if s.Nullable || s.Type.has("null") {
+		return true
+	}
+	for _, v := range s.Enum {
+		if v == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaEnum returns the non-null enum values as strings.
+func schemaEnum(s *Schema) []string {
+	var out []string
+	for _, v := range s.Enum {
+		if v != nil {
+			out = append(out, FormatValue(v))
+		}
+	}
+	return out
+}
+
+// schemaExamples returns the schema's example values JSON-encoded.
+func schemaExamples(s *Schema) []string {
+	var out []string
+	if s.Example != nil {
+		out = append(out, JSONValue(s.Example))
+	}
+	for _, ex := range s.Examples {
+		out = append(out, JSONValue(ex))
+	}
+	return out
+}
+
+// RefName derives a type name from a $ref: the last pointer segment, or the
+// file name without extension for whole-document references.
+func RefName(ref string) string {
+	docPart, pointer, _ := strings.Cut(ref, "#")
+	if strings.Trim(pointer, "/") == "" {
+		base := path.Base(docPart)
+		return strings.TrimSuffix(base, path.Ext(base))
+	}
+	parts := strings.Split(pointer, "/")
+	return parts[len(parts)-1]
+}
@@ -0,0 +1,292 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/roberthamel/skill-compiler/internal/ir"
+)
+
+// Set converts a document's schemas into IR types. Named schemas (e.g.
+// components/schemas) become types under their own name. Inline object
+// schemas passed to TypeName get a synthesized name, and referenced schemas
+// that are not named in the set (e.g. from external files) are added as
+// types under their own name.
+type Set struct {
+	named  map[string]*Schema
+	known  map[string]bool
+	inline map[string]string // suggested name + schema JSON -> synthesized name
+	defs   []ir.TypeDef
+}
+
+// NewSet registers the named schemas and their $defs.
+func NewSet(named map[string]*Schema) *Set {
+	s := &Set{named: named, known: make(map[string]bool), inline: make(map[string]string)}
+	for name, schema := range named {
+		s.known[name] = true
+		for _, def := range defNames(schema) {
+			s.known[def] = true
+		}
+	}
+	return s
+}
+
+// TypeName returns the type name for a schema, registering a TypeDef for it
+// when needed. suggested is used for inline object schemas.
+func (s *Set) TypeName(schema *Schema, suggested string) string {
+	if schema == nil {
+		return ""
+	}
+	if name := schemaName(schema); name != "" {
+		if !s.known[name] {
+			s.add(name, schema)
+		}
+		return name
+	}
+	if len(schema.Properties) > 0 || len(schema.AllOf) > 0 || len(schema.OneOf) > 0 || len(schema.AnyOf) > 0 {
+		// the same inline schema under several media types shares one name
+		key := ""
+		if sig, err := json.Marshal(schema); err == nil {
+			key = suggested + string(sig)
+			if name, ok := s.inline[key]; ok {
+				return name
+			}
+		}
+		name := suggested
+		for i := 2; s.known[name]; i++ {
+			name = fmt.Sprintf("%s%d", suggested, i)
+		}
+		if key != "" {
+			s.inline[key] = name
+		}
+		s.add(name, schema)
+		return name
+	}
+	if schema.Type.has("array") && schema.Items != nil && len(schema.PrefixItems) == 0 {
+		return "[]" + s.TypeName(schema.Items, suggested+"Item")
+	}
+	return TypeOf(schema)
+}
+
+// add registers a type for schema. Its slot is taken before the schema is
+// converted, so the types of inline objects inside it follow it.
+func (s *Set) add(name string, schema *Schema) {
+	s.known[name] = true
+	i := len(s.defs)
+	s.defs = append(s.defs, ir.TypeDef{})
+	s.defs[i] = s.typeDef(name, schema)
+}
+
+// fieldType returns the type name of a property. Inline objects, also as
+// array items, map values or union variants, are registered under
+// suggested; references, single-part allOf wrappers and scalars are named
+// as TypeOf names them.
+func (s *Set) fieldType(schema *Schema, suggested string) string {
+	if schema == nil {
+		return ""
+	}
+	types := schema.Type.nonNull()
+	switch {
+	case schemaName(schema) != "" || len(schema.Properties) > 0 || len(schema.AllOf) > 1:
+		return s.TypeName(schema, suggested)
+	case len(schema.AllOf) == 1:
+		return s.fieldType(schema.AllOf[0], suggested)
+	case len(schema.OneOf) > 0 || len(schema.AnyOf) > 0:
+		variants := append(append([]*Schema(nil), schema.OneOf...), schema.AnyOf...)
+		return strings.Join(s.variantNames(variants, suggested), "|")
+	case len(types) == 1 && types[0] == "array" && schema.Items != nil && len(schema.PrefixItems) == 0:
+		return "[]" + s.fieldType(schema.Items, suggested+"Item")
+	case len(types) == 1 && types[0] == "object" && additionalSchema(schema) != nil:
+		return "map[string]" + s.fieldType(additionalSchema(schema), suggested+"Value")
+	}
+	return TypeOf(schema)
+}
+
+// Fields returns the properties of an object schema as fields. Inline
+// objects among them are named after suggested and the property.
+func (s *Set) Fields(schema *Schema, suggested string) []ir.TypeField {
+	if schema == nil {
+		return nil
+	}
+	return s.typeDef(suggested, schema).Fields
+}
+
+// Types returns the named schemas, sorted by name and each followed by its
+// $defs, then the types registered through TypeName.
+func (s *Set) Types() []ir.TypeDef {
+	names := make([]string, 0, len(s.named))
+	for name := range s.named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var types []ir.TypeDef
+	for _, name := range names {
+		types = append(types, s.typeDef(name, s.named[name]))
+		types = append(types, s.defsTypeDefs(s.named[name])...)
+	}
+	return append(types, s.defs...)
+}
+
+// typeDef converts a named schema into a TypeDef. Inline objects among its
+// properties get types named after it and the property.
+func (s *Set) typeDef(name string, schema *Schema) ir.TypeDef {
+	td := ir.TypeDef{
+		Name:        name,
+		Description: schema.Description,
+		Enum:        schemaEnum(schema),
+		Examples:    schemaExamples(schema),
+		Constraints: schemaConstraints(schema),
+		Extensions:  Extensions(schema.Extra),
+	}
+	if td.Description == "" {
+		td.Description = schema.Title
+	}
+	if schema.Const != nil {
+		td.Enum = []string{FormatValue(schema.Const)}
+	}
+
+	// allOf parts are flattened; fields from named parts record their origin
+	for _, part := range schema.AllOf {
+		parent := schemaName(part)
+		if parent != "" {
+			td.Extends = append(td.Extends, parent)
+		}
+		partName := parent
+		if partName == "" {
+			partName = name
+		}
+		inherited := s.typeDef(partName, part)
+		for _, f := range inherited.Fields {
+			if f.InheritedFrom == "" {
+				f.InheritedFrom = parent
+			}
+			td.Fields = mergeField(td.Fields, f)
+		}
+		if td.Description == "" && parent == "" {
+			td.Description = inherited.Description
+		}
+		td.OneOf = append(td.OneOf, inherited.OneOf...)
+		td.AnyOf = append(td.AnyOf, inherited.AnyOf...)
+		if td.Discriminator == nil {
+			td.Discriminator = inherited.Discriminator
+		}
+	}
+
+	td.OneOf = append(td.OneOf, s.variantNames(schema.OneOf, name)...)
+	td.AnyOf = append(td.AnyOf, s.variantNames(schema.AnyOf, name)...)
+	if d := schema.Discriminator; d != nil && d.PropertyName != "" {
+		td.Discriminator = &ir.Discriminator{Property: d.PropertyName}
+		if len(d.Mapping) > 0 {
+			td.Discriminator.Mapping = make(map[string]string, len(d.Mapping))
+			for value, ref := range d.Mapping {
+				td.Discriminator.Mapping[value] = RefName(ref)
+			}
+		}
+	}
+
+	sortedFields := make([]string, 0, len(schema.Properties))
+	for fieldName := range schema.Properties {
+		sortedFields = append(sortedFields, fieldName)
+	}
+	sort.Strings(sortedFields)
+	for _, fieldName := range sortedFields {
+		fieldSchema := schema.Properties[fieldName]
+		required := false
+		for _, req := range schema.Required {
+			if req == fieldName {
+				required = true
+				break
+			}
+		}
+		field := ir.TypeField{
+			Name:        fieldName,
+			Type:        s.fieldType(fieldSchema, name+PascalCase(fieldName)),
+			Description: fieldSchema.Description,
+			Required:    required,
+			Nullable:    schemaNullable(fieldSchema),
+			Enum:        schemaEnum(fieldSchema),
+			Examples:    schemaExamples(fieldSchema),
+			Constraints: schemaConstraints(fieldSchema),
+			Extensions:  Extensions(fieldSchema.Extra),
+		}
+		if field.Description == "" {
+			field.Description = fieldSchema.Title
+		}
+		if fieldSchema.Const != nil {
+			field.Const = JSONValue(fieldSchema.Const)
+		}
+		if fieldSchema.Default != nil {
+			field.Default = JSONValue(fieldSchema.Default)
+		}
+		td.Fields = mergeField(td.Fields, field)
+	}
+
+	// required may list properties that are declared in an allOf part
+	for i, f := range td.Fields {
+		for _, req := range schema.Required {
+			if req == f.Name {
+				td.Fields[i].Required = true
+			}
+		}
+	}
+	return td
+}
+
+// mergeField adds f to fields, replacing an existing field with the same name
+// so that a schema's own properties override inherited ones.
+func mergeField(fields []ir.TypeField, f ir.TypeField) []ir.TypeField {
+	for i, existing := range fields {
+		if existing.Name == f.Name {
+			if !f.Required {
+				f.Required = existing.Required
+			}
+			fields[i] = f
+			return fields
+		}
+	}
+	return append(fields, f)
+}
+
+// variantNames returns the type name of each oneOf/anyOf variant. Inline
+// object variants are registered under their title or, failing that, the
+// parent name and their position, e.g. PaymentVariant2.
+func (s *Set) variantNames(variants []*Schema, parent string) []string {
+	names := make([]string, 0, len(variants))
+	for i, v := range variants {
+		suggested := PascalCase(v.Title)
+		if suggested == "" {
+			suggested = fmt.Sprintf("%sVariant%d", parent, i+1)
+		}
+		names = append(names, s.fieldType(v, suggested))
+	}
+	return names
+}
+
+// defsTypeDefs returns TypeDefs for the schema's $defs (sorted for
+// deterministic output), including nested $defs.
+func (s *Set) defsTypeDefs(schema *Schema) []ir.TypeDef {
+	names := make([]string, 0, len(schema.Defs))
+	for name := range schema.Defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var out []ir.TypeDef
+	for _, name := range names {
+		def := schema.Defs[name]
+		out = append(out, s.typeDef(name, def))
+		out = append(out, s.defsTypeDefs(def)...)
+	}
+	return out
+}
+
+// defNames returns the names of the schema's $defs, including nested $defs.
+func defNames(schema *Schema) []string {
+	var names []string
+	for name, def := range schema.Defs {
+		names = append(names, name)
+		names = append(names, defNames(def)...)
+	}
+	return names
+}
@@ -0,0 +1,55 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/roberthamel/skill-compiler/internal/ir"
+	"gopkg.in/yaml.v3"
+)
+
+func TestTypeDef_Constraints(t *testing.T) {
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal([]byte(`
+type: object
+title: Limits
+additionalProperties: false
+properties:
+  size:
+    type: integer
+    minimum: 1
+    maximum: 10
+    default: 5
+  code:
+    type: string
+    pattern: "^[A-Z]{3}$"
+  labels:
+    type: object
+    additionalProperties:
+      type: string
+  mode:
+    enum: [fast, safe]
+`), &raw); err != nil {
+		t.Fatal(err)
+	}
+	td := NewSet(map[string]*Schema{"Limits": Decode(raw)}).Types()[0]
+
+	if td.Description != "Limits" || td.Constraints["additionalProperties"] != "false" {
+		t.Errorf("type = %+v, want title as description and closed properties", td)
+	}
+	fields := map[string]ir.TypeField{}
+	for _, f := range td.Fields {
+		fields[f.Name] = f
+	}
+	if size := fields["size"]; size.Default != "5" || size.Constraints["minimum"] != "1" || size.Constraints["maximum"] != "10" {
+		t.Errorf("size = %+v", size)
+	}
+	if code := fields["code"]; code.Constraints["pattern"] != "^[A-Z]{3}$" {
+		t.Errorf("code = %+v", code)
+	}
+	if got := fields["labels"].Type; got != "map[string]string" {
+		t.Errorf("labels type = %q, want map[string]string", got)
+	}
+	if got := fields["mode"].Type; got != "string" {
+		t.Errorf("mode type = %q, want string inferred from enum", got)
+	}
+}
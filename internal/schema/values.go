@@ -0,0 +1,77 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// FormatValue renders a literal from the spec: strings as-is, everything
+// else JSON-encoded.
+func FormatValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return JSONValue(v)
+}
+
+// JSONValue JSON-encodes a literal from the spec.
+func JSONValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// PascalCase turns identifiers like "create_order", "list-pets" or
+// "getPet" into "CreateOrder", "ListPets" and "GetPet".
+func PascalCase(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// FormatExample renders an example value for a media type: JSON-encoded for
+// JSON media types, otherwise as FormatValue does.
+func FormatExample(contentType string, v interface{}) string {
+	if IsJSONMediaType(contentType) {
+		return JSONValue(v)
+	}
+	return FormatValue(v)
+}
+
+// IsJSONMediaType matches application/json and structured syntax suffixes
+// such as application/problem+json.
+func IsJSONMediaType(ct string) bool {
+	ct = strings.ToLower(strings.TrimSpace(strings.Split(ct, ";")[0]))
+	return ct == "application/json" || strings.HasSuffix(ct, "+json")
+}
+
+// Extensions returns the x-* entries of a decoded object's unknown keys, or
+// nil when there are none.
+func Extensions(fields map[string]interface{}) map[string]string {
+	var out map[string]string
+	for k, v := range fields {
+		if !strings.HasPrefix(k, "x-") {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]string)
+		}
+		out[k] = FormatValue(v)
+	}
+	return out
+}